@@ -0,0 +1,127 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gorilla/mux"
+	"github.com/windbnb/reservation-service/model"
+)
+
+// SpecPath is where the service serves its OpenAPI document.
+const SpecPath = "/api/openapi.json"
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+// LoadSpec parses and validates the OpenAPI document describing every route of the service.
+func LoadSpec() (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	spec, err := loader.LoadFromData(specYAML)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := spec.Validate(loader.Context); err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// MustLoadSpec is LoadSpec for the router; the document is embedded, so it failing to load is a bug.
+func MustLoadSpec() *openapi3.T {
+	spec, err := LoadSpec()
+	if err != nil {
+		panic("OpenAPI document is not valid: " + err.Error())
+	}
+
+	return spec
+}
+
+// SpecHandler serves the document as JSON.
+func SpecHandler(spec *openapi3.T) http.HandlerFunc {
+	document, err := json.Marshal(spec)
+	if err != nil {
+		panic("OpenAPI document cannot be encoded: " + err.Error())
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(document)
+	}
+}
+
+// ValidateRequests rejects requests whose parameters or body do not match the document with 400 Bad Request
+// before they reach the handlers. Property names of the body are matched without regard to case, and the handlers
+// are given the body with the names of the document. Tokens are checked by the handlers with the user service, not
+// here, and requests the document does not describe are left for the router to answer.
+func ValidateRequests(spec *openapi3.T) mux.MiddlewareFunc {
+	router, err := gorillamux.NewRouter(spec)
+	if err != nil {
+		panic("OpenAPI document cannot be routed: " + err.Error())
+	}
+
+	options := &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route, pathParams, err := router.FindRoute(r)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			err = normalizePropertyNames(r, route)
+			if err == nil {
+				err = openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+					Request:    r,
+					PathParams: pathParams,
+					Route:      route,
+					Options:    options,
+				})
+			}
+			if err != nil {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(model.ErrorResponse{Message: validationMessage(err), StatusCode: http.StatusBadRequest})
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// validationMessage names what is wrong without echoing the whole schema the value was checked against.
+func validationMessage(err error) string {
+	var requestError *openapi3filter.RequestError
+	if !errors.As(err, &requestError) {
+		return err.Error()
+	}
+
+	field := "request body"
+	if requestError.Parameter != nil {
+		field = requestError.Parameter.In + " parameter " + requestError.Parameter.Name
+	}
+
+	var schemaError *openapi3.SchemaError
+	switch {
+	case errors.As(requestError.Err, &schemaError):
+		if pointer := schemaError.JSONPointer(); len(pointer) > 0 {
+			field += "." + strings.Join(pointer, ".")
+		}
+		return field + ": " + schemaError.Reason
+	case requestError.Err != nil:
+		return field + ": " + requestError.Err.Error()
+	}
+
+	return field + ": " + requestError.Reason
+}
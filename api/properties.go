@@ -0,0 +1,142 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+// propertyError is a JSON body naming the same property more than once in different cases.
+type propertyError struct {
+	path []string
+}
+
+func (e *propertyError) Error() string {
+	return "request body." + strings.Join(e.path, ".") + ": property is given more than once"
+}
+
+// normalizePropertyNames renames the properties of the JSON body of the request to the names the document gives
+// them. encoding/json matches properties to struct fields without regard to case, so older clients send the Go field
+// names; renaming them lets the document validate those bodies as the handlers read them. Bodies that need no
+// renaming, and bodies that are not JSON, are left as they are.
+func normalizePropertyNames(r *http.Request, route *routers.Route) error {
+	if r.Body == nil || route.Operation == nil || route.Operation.RequestBody == nil || route.Operation.RequestBody.Value == nil {
+		return nil
+	}
+
+	mediaType := route.Operation.RequestBody.Value.Content.Get("application/json")
+	if mediaType == nil || mediaType.Schema == nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if decoder.Decode(&value) != nil {
+		// the validator reports what is wrong with the body
+		return nil
+	}
+
+	normalized, renamed, err := normalizeValue(value, mediaType.Schema.Value, nil)
+	if err != nil || !renamed {
+		return err
+	}
+
+	body, err = json.Marshal(normalized)
+	if err != nil {
+		return err
+	}
+
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	return nil
+}
+
+func normalizeValue(value any, schema *openapi3.Schema, path []string) (any, bool, error) {
+	if schema == nil {
+		return value, false, nil
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		properties := schemaProperties(schema)
+		normalized := make(map[string]any, len(value))
+		renamed := false
+		for key, property := range value {
+			name, propertySchema := key, properties[key]
+			for candidate, candidateSchema := range properties {
+				if propertySchema == nil && strings.EqualFold(candidate, key) {
+					name, propertySchema = candidate, candidateSchema
+				}
+			}
+
+			if _, found := normalized[name]; found {
+				return nil, false, &propertyError{path: append(path, name)}
+			}
+
+			normalizedProperty, renamedProperty, err := normalizeValue(property, propertySchema, append(path, name))
+			if err != nil {
+				return nil, false, err
+			}
+
+			normalized[name] = normalizedProperty
+			renamed = renamed || renamedProperty || name != key
+		}
+		return normalized, renamed, nil
+	case []any:
+		if schema.Items == nil {
+			return value, false, nil
+		}
+
+		renamed := false
+		for i, item := range value {
+			normalizedItem, renamedItem, err := normalizeValue(item, schema.Items.Value, path)
+			if err != nil {
+				return nil, false, err
+			}
+
+			value[i] = normalizedItem
+			renamed = renamed || renamedItem
+		}
+		return value, renamed, nil
+	}
+
+	return value, false, nil
+}
+
+// schemaProperties collects the properties of the schema, including the ones of the schemas it is composed of.
+func schemaProperties(schema *openapi3.Schema) map[string]*openapi3.Schema {
+	properties := map[string]*openapi3.Schema{}
+	for name, property := range schema.Properties {
+		if property != nil {
+			properties[name] = property.Value
+		}
+	}
+
+	for _, composed := range [][]*openapi3.SchemaRef{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for _, composedSchema := range composed {
+			if composedSchema == nil || composedSchema.Value == nil {
+				continue
+			}
+			for name, property := range schemaProperties(composedSchema.Value) {
+				if _, found := properties[name]; !found {
+					properties[name] = property
+				}
+			}
+		}
+	}
+
+	return properties
+}
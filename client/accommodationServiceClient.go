@@ -2,22 +2,44 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"github.com/windbnb/reservation-service/logging"
 	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
 	"github.com/windbnb/reservation-service/util"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 )
 
-func GetAccommodation(accommodationID uint) (model.AccommodationInfo, error) {
-	accommodationUrl, _ := util.GetAccommodationServicePathRoundRobin()
+// The locations of the other services and the timeout bounding every call to them, on top of the deadline of
+// the caller, are set from the configuration.
+var (
+	UserServiceURL          = "http://localhost:8081"
+	AccommodationServiceURL = "http://localhost:8082"
+	RequestTimeout          = 5 * time.Second
+)
+
+// Logger logs the failed calls to the other services; the default logger is used when it is nil.
+var Logger *slog.Logger
+
+func GetAccommodation(accommodationID uint, ctx context.Context) (model.AccommodationInfo, error) {
+	ctx, cancel := context.WithTimeout(ctx, RequestTimeout)
+	defer cancel()
+
+	accommodationUrl, _ := util.GetServicePathRoundRobin(AccommodationServiceURL)
 	url := accommodationUrl.Next().Host + "/api/accomodation/" + strconv.FormatUint(uint64(accommodationID), 10)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	//req.Header.Add("Authorization", tokenString)
+	tracer.Inject(ctx, req)
+	logging.InjectRequestID(ctx, req)
 	client := &http.Client{}
 	response, err := client.Do(req)
 
 	if err != nil {
+		logging.OrDefault(Logger).WarnContext(ctx, "calling accommodation service failed", "url", url, "error", err)
 		return model.AccommodationInfo{}, err
 	}
 
@@ -26,8 +48,11 @@ func GetAccommodation(accommodationID uint) (model.AccommodationInfo, error) {
 	return accommodationInfo, nil
 }
 
-func CreateReservedTerm(reservationRequest model.ReservationRequest) (uint, error) {
-	accommodationUrl, _ := util.GetAccommodationServicePathRoundRobin()
+func CreateReservedTerm(reservationRequest model.ReservationRequest, ctx context.Context) (uint, error) {
+	ctx, cancel := context.WithTimeout(ctx, RequestTimeout)
+	defer cancel()
+
+	accommodationUrl, _ := util.GetServicePathRoundRobin(AccommodationServiceURL)
 	url := accommodationUrl.Next().Host + "/api/accomodation/reservedTerm"
 
 	reservedTerm := model.ReservedTermRequest{
@@ -40,12 +65,15 @@ func CreateReservedTerm(reservationRequest model.ReservationRequest) (uint, erro
 		return 0, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(marshalled))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(marshalled))
 	//req.Header.Add("Authorization", tokenString)
+	tracer.Inject(ctx, req)
+	logging.InjectRequestID(ctx, req)
 	client := &http.Client{}
 	response, err := client.Do(req)
 
 	if err != nil {
+		logging.OrDefault(Logger).WarnContext(ctx, "calling accommodation service failed", "url", url, "error", err)
 		return 0, err
 	}
 
@@ -54,11 +82,18 @@ func CreateReservedTerm(reservationRequest model.ReservationRequest) (uint, erro
 	return reservedTermResponse.Id, nil
 }
 
-func DeleteReservedTerm(reservedTermId uint) {
-	accommodationUrl, _ := util.GetAccommodationServicePathRoundRobin()
+func DeleteReservedTerm(reservedTermId uint, ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, RequestTimeout)
+	defer cancel()
+
+	accommodationUrl, _ := util.GetServicePathRoundRobin(AccommodationServiceURL)
 	url := accommodationUrl.Next().Host + "/api/accomodation/reservedTerm" + strconv.FormatUint(uint64(reservedTermId), 10)
-	req, _ := http.NewRequest("DELETE", url, nil)
+	req, _ := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 	//req.Header.Add("Authorization", tokenString)
+	tracer.Inject(ctx, req)
+	logging.InjectRequestID(ctx, req)
 	client := &http.Client{}
-	_, _ = client.Do(req)
+	if _, err := client.Do(req); err != nil {
+		logging.OrDefault(Logger).WarnContext(ctx, "calling accommodation service failed", "url", url, "error", err)
+	}
 }
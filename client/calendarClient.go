@@ -0,0 +1,176 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/windbnb/reservation-service/logging"
+)
+
+const (
+	maximumCalendarSize      = 5 << 20
+	maximumCalendarRedirects = 5
+)
+
+var errCalendarAddressNotAllowed = errors.New("Calendar feed address is not allowed")
+
+// calendarClient only connects to public addresses, whatever the host names resolve to and wherever the feed
+// redirects to, so hosts cannot make the service reach its own network through a calendar URL.
+var calendarClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: func(network string, address string, conn syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || !isPublicAddress(ip) {
+					return errCalendarAddressNotAllowed
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ResponseHeaderTimeout: 5 * time.Second,
+	},
+}
+
+// FetchCalendar downloads the iCalendar feed at the URL. Http(s) URLs are accepted when their host is one of
+// allowedHosts, file URLs when they point inside importDir; either is rejected when its setting is empty. The
+// errors returned are shown to hosts, so they never carry what the feed or the network answered.
+func FetchCalendar(rawURL string, importDir string, allowedHosts []string, ctx context.Context) ([]byte, error) {
+	calendarUrl, err := parseCalendarURL(rawURL, importDir, allowedHosts)
+	if err != nil {
+		return nil, err
+	}
+
+	if calendarUrl.Scheme == "file" {
+		path, err := calendarFilePath(calendarUrl, importDir)
+		if err != nil {
+			return nil, err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			logging.OrDefault(Logger).WarnContext(ctx, "reading calendar file failed", "error", err)
+			return nil, errors.New("Calendar file could not be read")
+		}
+		defer file.Close()
+
+		return io.ReadAll(io.LimitReader(file, maximumCalendarSize))
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "GET", calendarUrl.String(), nil)
+	if err != nil {
+		return nil, errors.New("Calendar URL is not valid")
+	}
+
+	client := *calendarClient
+	client.CheckRedirect = func(request *http.Request, via []*http.Request) error {
+		if len(via) >= maximumCalendarRedirects {
+			return errors.New("Calendar feed redirected too many times")
+		}
+		_, err := parseCalendarURL(request.URL.String(), "", allowedHosts)
+		return err
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		logging.OrDefault(Logger).WarnContext(ctx, "fetching calendar feed failed", "host", calendarUrl.Host, "error", err)
+		if errors.Is(err, errCalendarAddressNotAllowed) {
+			return nil, errCalendarAddressNotAllowed
+		}
+		return nil, errors.New("Calendar feed could not be reached")
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.New("Calendar feed responded with status " + strconv.Itoa(response.StatusCode))
+	}
+
+	calendar, err := io.ReadAll(io.LimitReader(response.Body, maximumCalendarSize))
+	if err != nil {
+		logging.OrDefault(Logger).WarnContext(ctx, "reading calendar feed failed", "host", calendarUrl.Host, "error", err)
+		return nil, errors.New("Calendar feed could not be read")
+	}
+
+	return calendar, nil
+}
+
+// ValidateCalendarURL checks that FetchCalendar is able to fetch the URL without fetching it.
+func ValidateCalendarURL(rawURL string, importDir string, allowedHosts []string) error {
+	_, err := parseCalendarURL(rawURL, importDir, allowedHosts)
+	return err
+}
+
+func parseCalendarURL(rawURL string, importDir string, allowedHosts []string) (*url.URL, error) {
+	calendarUrl, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.New("Calendar URL is not valid")
+	}
+
+	switch calendarUrl.Scheme {
+	case "http", "https":
+		if calendarUrl.Hostname() == "" || calendarUrl.User != nil {
+			return nil, errors.New("Calendar URL is not valid")
+		}
+		if len(allowedHosts) == 0 {
+			return nil, errors.New("Importing calendars from URLs is disabled")
+		}
+		for _, allowedHost := range allowedHosts {
+			if strings.EqualFold(calendarUrl.Hostname(), allowedHost) {
+				return calendarUrl, nil
+			}
+		}
+		return nil, errors.New("Calendar URL host is not allowed")
+	case "file":
+		if _, err := calendarFilePath(calendarUrl, importDir); err != nil {
+			return nil, err
+		}
+		return calendarUrl, nil
+	}
+
+	return nil, errors.New("Calendar URL must use http, https or file scheme")
+}
+
+func isPublicAddress(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, private in practice although not in net.IP.IsPrivate.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func calendarFilePath(calendarUrl *url.URL, importDir string) (string, error) {
+	if importDir == "" {
+		return "", errors.New("Importing calendars from files is disabled")
+	}
+
+	root, err := filepath.Abs(importDir)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Clean(calendarUrl.Path)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+
+	if path != root && !strings.HasPrefix(path, root+string(filepath.Separator)) {
+		return "", errors.New("Calendar file must be inside the calendar import directory")
+	}
+
+	return path, nil
+}
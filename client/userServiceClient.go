@@ -1,21 +1,30 @@
 package client
 
 import (
+	"context"
 	"encoding/json"
+	"github.com/windbnb/reservation-service/logging"
 	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
 	"github.com/windbnb/reservation-service/util"
 	"net/http"
 )
 
-func AuthorizeHost(tokenString string) (model.UserResponseDTO, error) {
-	userUrl, _ := util.GetUserServicePathRoundRobin()
+func AuthorizeHost(tokenString string, ctx context.Context) (model.UserResponseDTO, error) {
+	ctx, cancel := context.WithTimeout(ctx, RequestTimeout)
+	defer cancel()
+
+	userUrl, _ := util.GetServicePathRoundRobin(UserServiceURL)
 	url := userUrl.Next().Host + "/api/users/authorize/host"
-	req, err := http.NewRequest("POST", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 	req.Header.Add("Authorization", tokenString)
+	tracer.Inject(ctx, req)
+	logging.InjectRequestID(ctx, req)
 	client := &http.Client{}
 	response, err := client.Do(req)
 
 	if err != nil {
+		logging.OrDefault(Logger).WarnContext(ctx, "calling user service failed", "url", url, "error", err)
 		return model.UserResponseDTO{}, err
 	}
 
@@ -24,15 +33,21 @@ func AuthorizeHost(tokenString string) (model.UserResponseDTO, error) {
 	return userResponse, nil
 }
 
-func AuthorizeGueest(tokenString string) (model.UserResponseDTO, error) {
-	userUrl, _ := util.GetUserServicePathRoundRobin()
+func AuthorizeGueest(tokenString string, ctx context.Context) (model.UserResponseDTO, error) {
+	ctx, cancel := context.WithTimeout(ctx, RequestTimeout)
+	defer cancel()
+
+	userUrl, _ := util.GetServicePathRoundRobin(UserServiceURL)
 	url := userUrl.Next().Host + "/api/users/authorize/guest"
-	req, err := http.NewRequest("POST", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
 	req.Header.Add("Authorization", tokenString)
+	tracer.Inject(ctx, req)
+	logging.InjectRequestID(ctx, req)
 	client := &http.Client{}
 	response, err := client.Do(req)
 
 	if err != nil {
+		logging.OrDefault(Logger).WarnContext(ctx, "calling user service failed", "url", url, "error", err)
 		return model.UserResponseDTO{}, err
 	}
 
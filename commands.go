@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/windbnb/reservation-service/config"
+	"github.com/windbnb/reservation-service/export"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/repository"
+	"github.com/windbnb/reservation-service/service"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// migrate applies the migrations even when the service is configured not to apply them when it starts.
+func migrate(ctx context.Context, cfg config.Config, logger *slog.Logger, args []string) error {
+	if err := flag.NewFlagSet("migrate", flag.ContinueOnError).Parse(args); err != nil {
+		return err
+	}
+
+	return withRepository(ctx, cfg, logger, func(db *mongo.Database, repo *repository.Repository) error {
+		return migrateSchema(true, db, repo, logger, ctx)
+	})
+}
+
+// seed writes to the configured database only when -database names it, so a load test configuration pointed at the
+// wrong database does not fill it with generated stays. The generated stays are tagged with -run and removed with
+// -remove.
+func seed(ctx context.Context, cfg config.Config, logger *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	database := flags.String("database", "", "name of the configured database, to confirm writing to it")
+	run := flags.String("run", "", "tag stored on the generated reservation requests, generated from the time when empty; with -remove, the run to remove, every run when empty")
+	remove := flags.Bool("remove", false, "remove seeded reservation requests instead of generating them")
+	count := flags.Int("count", 1000, "number of reservation requests to generate")
+	accommodations := flags.Int("accommodations", 50, "number of accommodations, numbered from 1, three per host")
+	guests := flags.Int("guests", 500, "number of guests")
+	from := flags.String("from", time.Now().AddDate(0, 0, -90).Format(util.DateLayout), "earliest check-in date")
+	days := flags.Int("days", 365, "number of days from the earliest check-in date stays check in on")
+	timeZone := flags.String("time-zone", cfg.Reservations.DefaultTimeZone, "time zone of the accommodations")
+	price := flags.Float64("price", 80, "nightly base price of the accommodations")
+	randomSeed := flags.Int64("seed", time.Now().UnixNano(), "seed of the random source, the same seed generates the same data")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *database != cfg.Database.Name {
+		return fmt.Errorf("seed writes to the %q database, pass -database %s to confirm", cfg.Database.Name, cfg.Database.Name)
+	}
+
+	if *remove {
+		return withRepository(ctx, cfg, logger, func(db *mongo.Database, repo *repository.Repository) error {
+			removed, err := newService(cfg, repo, logger).RemoveSeededReservationRequests(*run, ctx)
+			logger.Info("removed seeded reservation requests", "count", removed, "run", *run)
+			return err
+		})
+	}
+
+	if *run == "" {
+		*run = "seed-" + time.Now().UTC().Format("20060102T150405Z")
+	}
+
+	firstCheckIn, err := time.Parse(util.DateLayout, *from)
+	if err != nil {
+		return fmt.Errorf("-from must be a date: %w", err)
+	}
+
+	options := service.SeedOptions{
+		Run:            *run,
+		Count:          *count,
+		Accommodations: *accommodations,
+		Guests:         *guests,
+		From:           firstCheckIn,
+		Days:           *days,
+		TimeZone:       *timeZone,
+		NightlyPrice:   *price}
+
+	return withRepository(ctx, cfg, logger, func(db *mongo.Database, repo *repository.Repository) error {
+		saved, err := newService(cfg, repo, logger).SeedReservationRequests(options, rand.New(rand.NewSource(*randomSeed)), ctx)
+		logger.Info("seeded reservation requests", "count", saved, "seed", *randomSeed, "run", *run)
+		return err
+	})
+}
+
+func exportReservationRequests(ctx context.Context, cfg config.Config, logger *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", export.JSONL, "output format, jsonl or csv")
+	output := flags.String("output", "-", "file to write to, - for standard output")
+	statuses := flags.String("status", "", "comma separated statuses to export, all when empty")
+	accommodationID := flags.Uint("accommodation", 0, "export only the reservation requests of this accommodation")
+	guestID := flags.Uint("guest", 0, "export only the reservation requests of this guest")
+	ownerID := flags.Uint("owner", 0, "export only the reservation requests of this host")
+	from := flags.String("from", "", "export only stays checking in on this local date or later")
+	to := flags.String("to", "", "export only stays checking in before this local date")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *format != export.JSONL && *format != export.CSV {
+		return fmt.Errorf("-format must be %s or %s", export.JSONL, export.CSV)
+	}
+
+	filter := model.ReservationRequestFilter{
+		AccommodationID: *accommodationID,
+		GuestID:         *guestID,
+		OwnerID:         *ownerID}
+	for _, status := range strings.Split(*statuses, ",") {
+		if status = strings.ToUpper(strings.TrimSpace(status)); status != "" {
+			filter.Statuses = append(filter.Statuses, model.ReservationRequestStatus(status))
+		}
+	}
+
+	var err error
+	if filter.CheckInFrom, err = parseOptionalDate(*from); err != nil {
+		return fmt.Errorf("-from must be a date: %w", err)
+	}
+	if filter.CheckInTo, err = parseOptionalDate(*to); err != nil {
+		return fmt.Errorf("-to must be a date: %w", err)
+	}
+
+	return withRepository(ctx, cfg, logger, func(db *mongo.Database, repo *repository.Repository) error {
+		reservationRequests := repo.FindReservationRequests(filter, ctx)
+		if reservationRequests == nil {
+			return errors.New("reading reservation requests failed")
+		}
+
+		writer := os.Stdout
+		if *output != "-" {
+			file, err := os.Create(*output)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			writer = file
+		}
+
+		if err := export.Write(writer, *format, *reservationRequests); err != nil {
+			return err
+		}
+
+		logger.Info("exported reservation requests", "count", len(*reservationRequests), "format", *format, "output", *output)
+		return nil
+	})
+}
+
+// reconcile writes the report to standard output and fails when issues remain, so it can run as a periodic check.
+func reconcile(ctx context.Context, cfg config.Config, logger *slog.Logger, args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	repair := flags.Bool("repair", false, "create the reserved terms missing in the accommodation service")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	return withRepository(ctx, cfg, logger, func(db *mongo.Database, repo *repository.Repository) error {
+		report := newService(cfg, repo, logger).ReconcileWithAccommodationService(*repair, ctx)
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+
+		if remaining := len(report.Issues) - report.Repaired; remaining > 0 {
+			return fmt.Errorf("%d issues remain", remaining)
+		}
+		return nil
+	})
+}
+
+// expire runs the expiry job once. The events of the expired reservation requests stay in the outbox until the
+// service dispatches them.
+func expire(ctx context.Context, cfg config.Config, logger *slog.Logger, args []string) error {
+	if err := flag.NewFlagSet("expire", flag.ContinueOnError).Parse(args); err != nil {
+		return err
+	}
+
+	return withRepository(ctx, cfg, logger, func(db *mongo.Database, repo *repository.Repository) error {
+		expired := newService(cfg, repo, logger).ExpireSubmittedReservationRequests(ctx)
+		logger.Info("expired reservation requests", "count", expired)
+		return nil
+	})
+}
+
+// parseOptionalDate checks that the date is a local date, and leaves it as the YYYY-MM-DD the stays are stored with.
+func parseOptionalDate(date string) (string, error) {
+	if date == "" {
+		return "", nil
+	}
+
+	parsed, err := time.Parse(util.DateLayout, date)
+	if err != nil {
+		return "", err
+	}
+
+	return parsed.Format(util.DateLayout), nil
+}
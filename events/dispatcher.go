@@ -0,0 +1,101 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/repository"
+	"github.com/windbnb/reservation-service/tracer"
+)
+
+const (
+	dispatchBatchSize      = 100
+	defaultMaximumAttempts = 10
+)
+
+type EventHandler func(event model.Event, ctx context.Context) error
+
+type subscription struct {
+	name    string
+	handler EventHandler
+}
+
+// Dispatcher publishes events stored in the outbox to the subscribed handlers. An event is marked as
+// published only when all of its handlers succeed, otherwise it is retried on the next dispatch. The handlers
+// that succeeded are recorded on the event and not run again, and an event that failed MaximumAttempts times
+// is parked, so it no longer holds up the outbox.
+type Dispatcher struct {
+	Repo repository.IRepository
+	// MaximumAttempts is how many times an event is dispatched before it is parked; 10 when it is not set.
+	MaximumAttempts int
+	subscriptions   map[model.EventType][]subscription
+}
+
+// Subscribe runs the handler for the events of the given type. The name identifies the handler in the events it
+// handled, so it has to stay the same across releases.
+func (d *Dispatcher) Subscribe(eventType model.EventType, name string, handler EventHandler) {
+	if d.subscriptions == nil {
+		d.subscriptions = map[model.EventType][]subscription{}
+	}
+
+	d.subscriptions[eventType] = append(d.subscriptions[eventType], subscription{name: name, handler: handler})
+}
+
+func (d *Dispatcher) DispatchPending(ctx context.Context) {
+	span := tracer.StartSpanFromContext(ctx, "dispatchPendingEvents")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	events := d.Repo.FindUnpublishedEvents(dispatchBatchSize, ctx)
+	if events == nil {
+		return
+	}
+
+	for _, event := range *events {
+		err := d.dispatch(&event, ctx)
+		if err == nil {
+			d.Repo.MarkEventPublished(&event, ctx)
+			continue
+		}
+
+		event.Attempts++
+		event.LastError = err.Error()
+		if event.Attempts >= d.maximumAttempts() {
+			slog.ErrorContext(ctx, "event parked after failing to be handled", "eventID", event.ID.Hex(), "eventType", event.Type, "attempts", event.Attempts, "error", err)
+			d.Repo.ParkEvent(&event, ctx)
+			continue
+		}
+
+		d.Repo.RecordEventAttempt(&event, ctx)
+	}
+}
+
+// dispatch runs the handlers that did not handle the event yet, adding the ones that succeed to event.HandledBy,
+// and returns the error of the first one that fails.
+func (d *Dispatcher) dispatch(event *model.Event, ctx context.Context) error {
+	for _, subscription := range d.subscriptions[event.Type] {
+		if slices.Contains(event.HandledBy, subscription.name) {
+			continue
+		}
+
+		if err := subscription.handler(*event, ctx); err != nil {
+			slog.WarnContext(ctx, "handling event failed", "eventID", event.ID.Hex(), "eventType", event.Type, "handler", subscription.name, "error", err)
+			return err
+		}
+
+		event.HandledBy = append(event.HandledBy, subscription.name)
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) maximumAttempts() int {
+	if d.MaximumAttempts > 0 {
+		return d.MaximumAttempts
+	}
+
+	return defaultMaximumAttempts
+}
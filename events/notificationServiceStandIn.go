@@ -0,0 +1,65 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"github.com/windbnb/reservation-service/model"
+)
+
+// NotificationServiceStandIn plays the role of the notification service until it exists: it consumes
+// expiry events and notifies the guest and the host of the reservation request.
+func NotificationServiceStandIn(event model.Event, ctx context.Context) error {
+	var reservationExpired model.ReservationExpiredEvent
+	if err := json.Unmarshal([]byte(event.Payload), &reservationExpired); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "notifying guest and host that reservation request expired",
+		"guestID", reservationExpired.GuestID,
+		"ownerID", reservationExpired.OwnerID,
+		"reservationRequestID", reservationExpired.ReservationRequestID,
+		"reason", reservationExpired.Reason)
+
+	return nil
+}
+
+// DeclineNotificationServiceStandIn notifies guests that their reservation request was declined.
+func DeclineNotificationServiceStandIn(event model.Event, ctx context.Context) error {
+	var reservationDeclined model.ReservationDeclinedEvent
+	if err := json.Unmarshal([]byte(event.Payload), &reservationDeclined); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "notifying guest that reservation request was declined",
+		"guestID", reservationDeclined.GuestID,
+		"reservationRequestID", reservationDeclined.ReservationRequestID,
+		"reason", reservationDeclined.Reason)
+
+	return nil
+}
+
+// WaitlistNotificationServiceStandIn notifies waitlisted guests that the dates they waited for are available.
+func WaitlistNotificationServiceStandIn(event model.Event, ctx context.Context) error {
+	var waitlistNotified model.WaitlistNotifiedEvent
+	if err := json.Unmarshal([]byte(event.Payload), &waitlistNotified); err != nil {
+		return err
+	}
+
+	if waitlistNotified.ReservationRequestID != "" {
+		slog.InfoContext(ctx, "notifying guest that waitlisted dates became available and reservation request was submitted",
+			"guestID", waitlistNotified.GuestID,
+			"accommodationID", waitlistNotified.AccommodationID,
+			"reservationRequestID", waitlistNotified.ReservationRequestID)
+		return nil
+	}
+
+	slog.InfoContext(ctx, "notifying guest that waitlisted dates became available",
+		"guestID", waitlistNotified.GuestID,
+		"accommodationID", waitlistNotified.AccommodationID,
+		"startDate", waitlistNotified.StartDate.Format("2006-01-02"),
+		"endDate", waitlistNotified.EndDate.Format("2006-01-02"))
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/payment"
+)
+
+// NewPaymentServiceStandIn returns a handler that plays the role of the payment service until it exists:
+// it consumes cancellation events and refunds the amounts they carry through the payment provider.
+func NewPaymentServiceStandIn(paymentProvider payment.PaymentProvider) EventHandler {
+	return func(event model.Event, ctx context.Context) error {
+		var reservationCancelled model.ReservationCancelledEvent
+		if err := json.Unmarshal([]byte(event.Payload), &reservationCancelled); err != nil {
+			return err
+		}
+
+		slog.InfoContext(ctx, "refunding guest for cancelled reservation request",
+			"refundAmount", reservationCancelled.RefundAmount,
+			"guestID", reservationCancelled.GuestID,
+			"reservationRequestID", reservationCancelled.ReservationRequestID,
+			"penalty", reservationCancelled.Penalty,
+			"cancellationPolicy", reservationCancelled.CancellationPolicy)
+
+		if paymentProvider == nil || reservationCancelled.AuthorizationID == "" || reservationCancelled.RefundAmount <= 0 {
+			return nil
+		}
+
+		err := paymentProvider.Refund(reservationCancelled.AuthorizationID, reservationCancelled.RefundAmount, ctx)
+		if errors.Is(err, payment.ErrAuthorizationNotFound) {
+			slog.WarnContext(ctx, "refund skipped", "reservationRequestID", reservationCancelled.ReservationRequestID, "error", err)
+			return nil
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,136 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+)
+
+const (
+	JSONL = "jsonl"
+	CSV   = "csv"
+)
+
+// Record is a reservation request as exported, flattened so that both formats carry the same fields in the same
+// order. Optional values that are not set are exported empty.
+type Record struct {
+	ID                 string  `json:"id"`
+	Status             string  `json:"status"`
+	AccommodationID    uint    `json:"accommodationID"`
+	AccommodationName  string  `json:"accommodationName"`
+	OwnerID            uint    `json:"ownerID"`
+	GuestID            uint    `json:"guestID"`
+	GuestNumber        uint    `json:"guestNumber"`
+	CheckInDate        string  `json:"checkInDate"`
+	CheckOutDate       string  `json:"checkOutDate"`
+	StartDate          string  `json:"startDate"`
+	EndDate            string  `json:"endDate"`
+	TimeZone           string  `json:"timeZone"`
+	Total              float64 `json:"total"`
+	CancellationPolicy string  `json:"cancellationPolicy"`
+	PaymentStatus      string  `json:"paymentStatus"`
+	RefundAmount       float64 `json:"refundAmount"`
+	GroupID            string  `json:"groupID"`
+	SeriesID           string  `json:"seriesID"`
+	ReservedTermID     uint    `json:"reservedTermID"`
+	CreatedAt          string  `json:"createdAt"`
+}
+
+var csvHeader = []string{
+	"id", "status", "accommodationID", "accommodationName", "ownerID", "guestID", "guestNumber", "checkInDate",
+	"checkOutDate", "startDate", "endDate", "timeZone", "total", "cancellationPolicy", "paymentStatus",
+	"refundAmount", "groupID", "seriesID", "reservedTermID", "createdAt"}
+
+func NewRecord(reservationRequest model.ReservationRequest) Record {
+	record := Record{
+		ID:                 reservationRequest.ID.Hex(),
+		Status:             string(reservationRequest.Status),
+		AccommodationID:    reservationRequest.AccommodationID,
+		AccommodationName:  reservationRequest.AccommodationName,
+		OwnerID:            reservationRequest.OwnerID,
+		GuestID:            reservationRequest.GuestID,
+		GuestNumber:        reservationRequest.GuestNumber,
+		CheckInDate:        reservationRequest.CheckInDate,
+		CheckOutDate:       reservationRequest.CheckOutDate,
+		StartDate:          formatTime(reservationRequest.StartDate),
+		EndDate:            formatTime(reservationRequest.EndDate),
+		TimeZone:           reservationRequest.TimeZone,
+		CancellationPolicy: string(reservationRequest.CancellationPolicy),
+		ReservedTermID:     reservationRequest.ReservedTermId,
+		CreatedAt:          formatTime(reservationRequest.CreatedAt)}
+
+	if reservationRequest.Price != nil {
+		record.Total = reservationRequest.Price.Total
+	}
+	if reservationRequest.Payment != nil {
+		record.PaymentStatus = string(reservationRequest.Payment.Status)
+	}
+	if reservationRequest.Refund != nil {
+		record.RefundAmount = reservationRequest.Refund.Amount
+	}
+	if reservationRequest.GroupID != nil {
+		record.GroupID = reservationRequest.GroupID.Hex()
+	}
+	if reservationRequest.SeriesID != nil {
+		record.SeriesID = reservationRequest.SeriesID.Hex()
+	}
+
+	return record
+}
+
+// Write writes the reservation requests to w in the format, JSONL or CSV with a header row.
+func Write(w io.Writer, format string, reservationRequests []model.ReservationRequest) error {
+	switch format {
+	case JSONL:
+		encoder := json.NewEncoder(w)
+		for _, reservationRequest := range reservationRequests {
+			if err := encoder.Encode(NewRecord(reservationRequest)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(csvHeader); err != nil {
+			return err
+		}
+		for _, reservationRequest := range reservationRequests {
+			if err := writer.Write(NewRecord(reservationRequest).csvRow()); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	}
+
+	return fmt.Errorf("unknown export format %q, expected %s or %s", format, JSONL, CSV)
+}
+
+func (r Record) csvRow() []string {
+	return []string{
+		r.ID, r.Status, formatUint(r.AccommodationID), r.AccommodationName, formatUint(r.OwnerID), formatUint(r.GuestID),
+		formatUint(r.GuestNumber), r.CheckInDate, r.CheckOutDate, r.StartDate, r.EndDate, r.TimeZone,
+		formatAmount(r.Total), r.CancellationPolicy, r.PaymentStatus, formatAmount(r.RefundAmount), r.GroupID,
+		r.SeriesID, formatUint(r.ReservedTermID), r.CreatedAt}
+}
+
+func formatTime(instant time.Time) string {
+	if instant.IsZero() {
+		return ""
+	}
+
+	return instant.UTC().Format(time.RFC3339)
+}
+
+func formatUint(value uint) string {
+	return strconv.FormatUint(uint64(value), 10)
+}
+
+func formatAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
@@ -6,14 +6,20 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
-	"github.com/opentracing/opentracing-go"
 	"github.com/windbnb/reservation-service/client"
+	"github.com/windbnb/reservation-service/health"
+	"github.com/windbnb/reservation-service/logging"
 	"github.com/windbnb/reservation-service/tracer"
+	"github.com/windbnb/reservation-service/util"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/windbnb/reservation-service/model"
 	"github.com/windbnb/reservation-service/service"
@@ -21,37 +27,1046 @@ import (
 
 type Handler struct {
 	Service *service.ReservationRequestService
-	Tracer  opentracing.Tracer
+	Tracer  trace.Tracer
 	Closer  io.Closer
+	Logger  *slog.Logger
+	Health  *health.Monitor
 }
 
-func (handler *Handler) Healthcheck(w http.ResponseWriter, _ *http.Request) {
+// Healthcheck answers the liveness probe. It does not check the dependencies: restarting the service does not bring
+// them back, so their state is left to the readiness probe.
+func (handler *Handler) Healthcheck(w http.ResponseWriter, r *http.Request) {
 	_, _ = fmt.Fprintln(w, "Healthy!")
 }
 
-func (handler *Handler) Ready(w http.ResponseWriter, _ *http.Request) {
-	_, _ = fmt.Fprintln(w, "Ready!")
+// Ready answers the readiness probe; the service is not ready while starting, shutting down or when a
+// critical dependency is down.
+func (handler *Handler) Ready(w http.ResponseWriter, r *http.Request) {
+	if handler.Health == nil {
+		_, _ = fmt.Fprintln(w, "Ready!")
+		return
+	}
+
+	report := handler.Health.Report(r.Context())
+	writeHealthReport(w, report, report.Ready)
+}
+
+func (h *Handler) CreateReservationRequest(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("createReservationRequestHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling create reservation request at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	userResponse := h.authorizeGuest(r, ctx)
+	if userResponse == nil || userResponse.Role != "GUEST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a guest", StatusCode: http.StatusUnauthorized})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	var createReservationRequest *model.CreateReservationRequest
+	err := json.NewDecoder(r.Body).Decode(&createReservationRequest)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	createReservationRequest.GuestID = userResponse.Id
+	if createReservationRequest.Recurrence != nil {
+		h.createReservationSeries(w, span, createReservationRequest, ctx)
+		return
+	}
+
+	reservationRequest, err := h.Service.SaveReservationRequest(createReservationRequest, ctx)
+
+	if err != nil {
+		tracer.LogError(span, err)
+		errorResponse := model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest}
+		var violationsError *service.BookingRuleViolationsError
+		if errors.As(err, &violationsError) {
+			errorResponse.StatusCode = http.StatusUnprocessableEntity
+			errorResponse.Violations = violationsError.Violations
+		}
+		w.WriteHeader(errorResponse.StatusCode)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	reservationRequestDto := toReservationRequestDto(reservationRequest)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reservationRequestDto)
+}
+
+func (h *Handler) QuoteReservationRequest(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("quoteReservationRequestHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling quote reservation request at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var createReservationRequest *model.CreateReservationRequest
+	err := json.NewDecoder(r.Body).Decode(&createReservationRequest)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	price, err := h.Service.QuoteReservationRequest(createReservationRequest, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(price)
+}
+
+func (h *Handler) GetGuestsActive(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getGuestsActiveHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get guests active reservations at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	userResponse := h.authorizeGuest(r, ctx)
+	if userResponse.Role != "GUEST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a guest", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	guestID, _ := strconv.Atoi(params["id"])
+
+	activeReservations := h.Service.GetGuestActiveReservations(uint(guestID), ctx)
+
+	reservationRequestsDto := []model.ReservationRequestDto{}
+
+	for _, reservationRequest := range *activeReservations {
+		reservationRequestsDto = append(reservationRequestsDto, toReservationRequestDto(&reservationRequest))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reservationRequestsDto)
+}
+
+func (h *Handler) GetOwnersActive(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getOwnersActiveHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get owners active reservations at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	userResponse := h.authorizeHost(r, ctx)
+	if userResponse.Role != "HOST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	guestID, _ := strconv.Atoi(params["id"])
+
+	activeReservations := h.Service.GetOwnersActiveReservations(uint(guestID), ctx)
+
+	reservationRequestsDto := []model.ReservationRequestDto{}
+
+	for _, reservationRequest := range *activeReservations {
+		reservationRequestsDto = append(reservationRequestsDto, toReservationRequestDto(&reservationRequest))
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(reservationRequestsDto)
+}
+
+// GetWheatherGuestWasWithHost is kept for the rating service until it moves to GetRatingEligibility.
+func (h *Handler) GetWheatherGuestWasWithHost(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getWheatherGuestWasWithHostHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get whether guest was accomodated by host at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeGuest(r, ctx)
+	if userResponse.Role != "GUEST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not an authorised guest", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	params := mux.Vars(r)
+	guestID, _ := strconv.Atoi(params["guestId"])
+	ownerID, _ := strconv.Atoi(params["hostId"])
+
+	response := h.Service.GetWheatherGuestWasWithHost(uint(guestID), uint(ownerID), ctx)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetWheatherGuestWasInAccomodation is kept for the rating service until it moves to GetRatingEligibility.
+func (h *Handler) GetWheatherGuestWasInAccomodation(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getWheatherGuestWasInAccomodationHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get whether guest was in accomodation at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeGuest(r, ctx)
+	if userResponse.Role != "GUEST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not an authorised guest", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	params := mux.Vars(r)
+	guestID, _ := strconv.Atoi(params["guestId"])
+	accommodationID, _ := strconv.Atoi(params["accomodationId"])
+
+	response := h.Service.GetWheatherGuestWasInAccomodation(uint(guestID), uint(accommodationID), ctx)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (h *Handler) GetRatingEligibility(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getRatingEligibilityHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get rating eligibility at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeGuest(r, ctx)
+	if userResponse == nil || userResponse.Role != "GUEST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not an authorised guest", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	params := mux.Vars(r)
+	guestID, _ := strconv.Atoi(params["guestId"])
+	if uint(guestID) != userResponse.Id {
+		tracer.LogError(span, errors.New("Forbidden"))
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "guests can only read their own eligibility", StatusCode: http.StatusForbidden})
+		return
+	}
+
+	hostID, _ := strconv.Atoi(r.URL.Query().Get("hostId"))
+	accommodationID, _ := strconv.Atoi(r.URL.Query().Get("accommodationId"))
+	if hostID <= 0 && accommodationID <= 0 {
+		tracer.LogError(span, errors.New("hostId or accommodationId must be provided"))
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "hostId or accommodationId must be provided", StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	eligibility := h.Service.GetRatingEligibility(model.RatingEligibilityRequest{
+		GuestID:         uint(guestID),
+		HostID:          uint(hostID),
+		AccommodationID: uint(accommodationID)}, ctx)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(eligibility)
+}
+
+func (h *Handler) GetRatingEligibilities(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getRatingEligibilitiesHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get rating eligibilities at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeUser(r, ctx)
+	if userResponse == nil {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not authorised", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	var eligibilityRequests []model.RatingEligibilityRequest
+	err := json.NewDecoder(r.Body).Decode(&eligibilityRequests)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	// guests may only ask about their own stays and hosts only about stays with them
+	for _, eligibilityRequest := range eligibilityRequests {
+		if (userResponse.Role == model.GUEST && eligibilityRequest.GuestID != userResponse.Id) ||
+			(userResponse.Role == model.HOST && eligibilityRequest.HostID != userResponse.Id) {
+			tracer.LogError(span, errors.New("Forbidden"))
+			w.WriteHeader(http.StatusForbidden)
+			json.NewEncoder(w).Encode(model.ErrorResponse{Message: "users can only read the eligibility of their own stays", StatusCode: http.StatusForbidden})
+			return
+		}
+	}
+
+	eligibilities, err := h.Service.GetRatingEligibilities(eligibilityRequests, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(eligibilities)
+}
+
+func (h *Handler) MarkReviewSubmitted(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("markReviewSubmittedHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling mark review submitted at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeGuest(r, ctx)
+	if userResponse == nil || userResponse.Role != "GUEST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a guest", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	params := mux.Vars(r)
+	objectId, err := primitive.ObjectIDFromHex(params["id"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	target := model.ReviewTarget(r.URL.Query().Get("target"))
+	reservation, err := h.Service.MarkReviewSubmitted(objectId, userResponse.Id, target, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toReservationRequestDto(reservation))
+}
+
+func (h *Handler) GetAccommodationCalendar(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getAccommodationCalendarHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get accommodation calendar at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeHost(r, ctx)
+	if userResponse == nil || userResponse.Role != "HOST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	params := mux.Vars(r)
+	accommodationID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	from, to, err := parsePeriod(r)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	calendar, err := h.Service.GetAccommodationCalendar(uint(accommodationID), userResponse.Id, from, to, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(calendar)
+}
+
+func (h *Handler) GetAvailability(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getAvailabilityHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get availability at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	accommodationID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	from, to, err := parsePeriod(r)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	nights, err := parseUintQuery(r, "nights", 1)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	limit, err := parseUintQuery(r, "limit", 5)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	availability, err := h.Service.GetAvailability(uint(accommodationID), from, to, nights, limit, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(availability)
+}
+
+func (h *Handler) CheckBulkAvailability(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("checkBulkAvailabilityHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling check bulk availability at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	var bulkAvailabilityRequest model.BulkAvailabilityRequest
+	err := json.NewDecoder(r.Body).Decode(&bulkAvailabilityRequest)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	bulkAvailability, err := h.Service.CheckBulkAvailability(&bulkAvailabilityRequest, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(bulkAvailability)
+}
+
+func (h *Handler) GetBookingRules(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getBookingRulesHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get booking rules at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	accommodationID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	bookingRules, err := h.Service.GetBookingRules(uint(accommodationID), ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusInternalServerError})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(bookingRules)
+}
+
+func (h *Handler) UpdateBookingRules(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("updateBookingRulesHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling update booking rules at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeHost(r, ctx)
+	if userResponse == nil || userResponse.Role != "HOST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	params := mux.Vars(r)
+	accommodationID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	var bookingRules model.BookingRules
+	err = json.NewDecoder(r.Body).Decode(&bookingRules)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	bookingRules.AccommodationID = uint(accommodationID)
+	updatedBookingRules, err := h.Service.UpdateBookingRules(&bookingRules, userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updatedBookingRules)
+}
+
+func (h *Handler) GetCalendarFeed(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getCalendarFeedHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get calendar feed at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeHost(r, ctx)
+	if userResponse == nil || userResponse.Role != "HOST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	params := mux.Vars(r)
+	accommodationID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	token, err := h.Service.GetCalendarFeedToken(uint(accommodationID), userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if forwardedProto := r.Header.Get("X-Forwarded-Proto"); forwardedProto != "" {
+		scheme = forwardedProto
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(model.CalendarFeedDto{
+		URL: fmt.Sprintf("%s://%s/api/accommodations/%d/calendar.ics?token=%s", scheme, r.Host, accommodationID, token),
+	})
+}
+
+func (h *Handler) ExportCalendar(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("exportCalendarHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling export calendar at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	params := mux.Vars(r)
+	accommodationID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	calendar, err := h.Service.ExportCalendar(uint(accommodationID), r.URL.Query().Get("token"), ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(calendar)
+}
+
+func (h *Handler) AddCalendarImport(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("addCalendarImportHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling add calendar import at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeHost(r, ctx)
+	if userResponse == nil || userResponse.Role != "HOST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	params := mux.Vars(r)
+	accommodationID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	var calendarImportRequest model.CalendarImportRequest
+	err = json.NewDecoder(r.Body).Decode(&calendarImportRequest)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	calendarImport, err := h.Service.AddCalendarImport(uint(accommodationID), userResponse.Id, calendarImportRequest.URL, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(calendarImport)
+}
+
+func (h *Handler) GetCalendarImports(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getCalendarImportsHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get calendar imports at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeHost(r, ctx)
+	if userResponse == nil || userResponse.Role != "HOST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	params := mux.Vars(r)
+	accommodationID, err := strconv.Atoi(params["id"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	calendarImports, err := h.Service.GetCalendarImports(uint(accommodationID), userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(calendarImports)
+}
+
+func (h *Handler) DeleteCalendarImport(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("deleteCalendarImportHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling delete calendar import at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeHost(r, ctx)
+	if userResponse == nil || userResponse.Role != "HOST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	params := mux.Vars(r)
+	objectId, err := primitive.ObjectIDFromHex(params["importId"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	err = h.Service.DeleteCalendarImport(objectId, userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) JoinWaitlist(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("joinWaitlistHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling join waitlist at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeGuest(r, ctx)
+	if userResponse == nil || userResponse.Role != "GUEST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a guest", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	var joinWaitlistRequest model.JoinWaitlistRequest
+	err := json.NewDecoder(r.Body).Decode(&joinWaitlistRequest)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	joinWaitlistRequest.GuestID = userResponse.Id
+	waitlistEntry, err := h.Service.JoinWaitlist(&joinWaitlistRequest, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(waitlistEntry)
+}
+
+func (h *Handler) LeaveWaitlist(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("leaveWaitlistHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling leave waitlist at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeGuest(r, ctx)
+	if userResponse == nil || userResponse.Role != "GUEST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a guest", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	params := mux.Vars(r)
+	objectId, err := primitive.ObjectIDFromHex(params["id"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	err = h.Service.LeaveWaitlist(objectId, userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) GetGuestsWaitlistEntries(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getGuestsWaitlistEntriesHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get guests waitlist entries at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeGuest(r, ctx)
+	if userResponse == nil || userResponse.Role != "GUEST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a guest", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	waitlistEntries := h.Service.GetGuestsWaitlistEntries(userResponse.Id, ctx)
+	if waitlistEntries == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "waitlist could not be loaded", StatusCode: http.StatusInternalServerError})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(waitlistEntries)
+}
+
+// createReservationSeries answers a create request with a recurrence. Occurrences that cannot be booked are
+// reported with 409 Conflict unless the guest asked to skip them.
+func (h *Handler) createReservationSeries(w http.ResponseWriter, span trace.Span, createReservationRequest *model.CreateReservationRequest, ctx context.Context) {
+	reservationSeries, err := h.Service.SaveReservationSeries(createReservationRequest, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		errorResponse := model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest}
+		var conflictsError *service.SeriesConflictsError
+		if errors.As(err, &conflictsError) {
+			errorResponse.StatusCode = http.StatusConflict
+			errorResponse.Conflicts = conflictsError.Conflicts
+		}
+		w.WriteHeader(errorResponse.StatusCode)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toReservationSeriesDto(reservationSeries))
+}
+
+func (h *Handler) GetReservationSeries(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getReservationSeriesHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get reservation series at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeUser(r, ctx)
+	if userResponse == nil {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not logged in", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	params := mux.Vars(r)
+	seriesID, err := primitive.ObjectIDFromHex(params["id"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	reservationSeries, err := h.Service.GetReservationSeries(seriesID, userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toReservationSeriesDto(reservationSeries))
+}
+
+func (h *Handler) CancelReservationSeries(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("cancelReservationSeriesHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling cancel reservation series at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeGuest(r, ctx)
+	if userResponse == nil || userResponse.Role != "GUEST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a guest", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	params := mux.Vars(r)
+	seriesID, err := primitive.ObjectIDFromHex(params["id"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	reservationSeries, err := h.Service.CancelReservationSeries(seriesID, userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toReservationSeriesDto(reservationSeries))
+}
+
+func (h *Handler) CreateReservationGroup(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("createReservationGroupHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling create reservation group at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeGuest(r, ctx)
+	if userResponse == nil || userResponse.Role != "GUEST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a guest", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	var createReservationGroupRequest model.CreateReservationGroupRequest
+	err := json.NewDecoder(r.Body).Decode(&createReservationGroupRequest)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	createReservationGroupRequest.GuestID = userResponse.Id
+	reservationGroup, err := h.Service.SaveReservationGroup(&createReservationGroupRequest, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		errorResponse := model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest}
+		var violationsError *service.BookingRuleViolationsError
+		if errors.As(err, &violationsError) {
+			errorResponse.StatusCode = http.StatusUnprocessableEntity
+			errorResponse.Violations = violationsError.Violations
+		}
+		w.WriteHeader(errorResponse.StatusCode)
+		json.NewEncoder(w).Encode(errorResponse)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toReservationGroupDto(reservationGroup))
 }
 
-func (h *Handler) CreateReservationRequest(w http.ResponseWriter, r *http.Request) {
-	span := tracer.StartSpanFromRequest("createReservationRequestHandler", h.Tracer, r)
-	defer span.Finish()
-	span.LogFields(
-		tracer.LogString("handler", fmt.Sprintf("handling create reservation request at %s\n", r.URL.Path)),
+func (h *Handler) GetReservationGroup(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getReservationGroupHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get reservation group at %s\n", r.URL.Path)),
 	)
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
-	userResponse := h.authorizeGuest(r)
-	if userResponse == nil || userResponse.Role != "GUEST" {
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeUser(r, ctx)
+	if userResponse == nil {
 		tracer.LogError(span, errors.New("Unauthorized"))
 		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a guest", StatusCode: http.StatusUnauthorized})
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not logged in", StatusCode: http.StatusUnauthorized})
 		return
 	}
-	w.Header().Set("Content-Type", "application/json")
 
-	var createReservationRequest *model.CreateReservationRequest
-	err := json.NewDecoder(r.Body).Decode(&createReservationRequest)
+	params := mux.Vars(r)
+	groupID, err := primitive.ObjectIDFromHex(params["id"])
 	if err != nil {
 		tracer.LogError(span, err)
 		w.WriteHeader(http.StatusBadRequest)
@@ -59,9 +1074,7 @@ func (h *Handler) CreateReservationRequest(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	createReservationRequest.GuestID = userResponse.Id
-	reservationRequest, err := h.Service.SaveReservationRequest(createReservationRequest, ctx)
-
+	reservationGroup, err := h.Service.GetReservationGroup(groupID, userResponse.Id, ctx)
 	if err != nil {
 		tracer.LogError(span, err)
 		w.WriteHeader(http.StatusBadRequest)
@@ -69,165 +1082,155 @@ func (h *Handler) CreateReservationRequest(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	var reservationRequestDto = model.ReservationRequestDto{
-		ID:                reservationRequest.ID.Hex(),
-		Status:            reservationRequest.Status,
-		GuestNumber:       reservationRequest.GuestNumber,
-		GuestID:           reservationRequest.GuestID,
-		AccommodationID:   reservationRequest.AccommodationID,
-		StartDate:         reservationRequest.StartDate,
-		EndDate:           reservationRequest.EndDate,
-		AccommodationName: reservationRequest.AccommodationName}
-
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(reservationRequestDto)
+	json.NewEncoder(w).Encode(toReservationGroupDto(reservationGroup))
 }
 
-func (h *Handler) GetGuestsActive(w http.ResponseWriter, r *http.Request) {
-	span := tracer.StartSpanFromRequest("getGuestsActiveHandler", h.Tracer, r)
-	defer span.Finish()
-	span.LogFields(
-		tracer.LogString("handler", fmt.Sprintf("handling get guests active reservations at %s\n", r.URL.Path)),
+func (h *Handler) GetGuestsReservationGroups(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getGuestsReservationGroupsHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get guests reservation groups at %s\n", r.URL.Path)),
 	)
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
-	userResponse := h.authorizeGuest(r)
-	if userResponse.Role != "GUEST" {
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeGuest(r, ctx)
+	if userResponse == nil || userResponse.Role != "GUEST" {
 		tracer.LogError(span, errors.New("Unauthorized"))
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a guest", StatusCode: http.StatusUnauthorized})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-
-	params := mux.Vars(r)
-	guestID, _ := strconv.Atoi(params["id"])
-
-	activeReservations := h.Service.GetGuestActiveReservations(uint(guestID), ctx)
-
-	reservationRequestsDto := []model.ReservationRequestDto{}
-
-	for _, reservationRequest := range *activeReservations {
-		reservationRequestsDto = append(reservationRequestsDto, model.ReservationRequestDto{
-			ID:                reservationRequest.ID.Hex(),
-			Status:            reservationRequest.Status,
-			GuestNumber:       reservationRequest.GuestNumber,
-			GuestID:           reservationRequest.GuestID,
-			AccommodationID:   reservationRequest.AccommodationID,
-			StartDate:         reservationRequest.StartDate,
-			EndDate:           reservationRequest.EndDate,
-			AccommodationName: reservationRequest.AccommodationName})
-	}
-
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(reservationRequestsDto)
+	reservationGroups := h.Service.GetGuestsReservationGroups(userResponse.Id, ctx)
+	writeReservationGroups(w, reservationGroups)
 }
 
-func (h *Handler) GetOwnersActive(w http.ResponseWriter, r *http.Request) {
-	span := tracer.StartSpanFromRequest("getOwnersActiveHandler", h.Tracer, r)
-	defer span.Finish()
-	span.LogFields(
-		tracer.LogString("handler", fmt.Sprintf("handling get owners active reservations at %s\n", r.URL.Path)),
+func (h *Handler) GetOwnersReservationGroups(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("getOwnersReservationGroupsHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling get owners reservation groups at %s\n", r.URL.Path)),
 	)
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
-	userResponse := h.authorizeHost(r)
-	if userResponse.Role != "HOST" {
+	w.Header().Set("Content-Type", "application/json")
+	userResponse := h.authorizeHost(r, ctx)
+	if userResponse == nil || userResponse.Role != "HOST" {
 		tracer.LogError(span, errors.New("Unauthorized"))
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-
-	params := mux.Vars(r)
-	guestID, _ := strconv.Atoi(params["id"])
+	reservationGroups := h.Service.GetOwnersReservationGroups(userResponse.Id, ctx)
+	writeReservationGroups(w, reservationGroups)
+}
 
-	activeReservations := h.Service.GetOwnersActiveReservations(uint(guestID), ctx)
+func (h *Handler) AcceptReservationGroup(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("acceptReservationGroupHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling accept reservation group at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
-	reservationRequestsDto := []model.ReservationRequestDto{}
+	h.updateReservationGroup(w, r, span, model.HOST, func(groupID primitive.ObjectID, userID uint) (*model.ReservationGroup, error) {
+		return h.Service.AcceptReservationGroup(groupID, userID, ctx)
+	})
+}
 
-	for _, reservationRequest := range *activeReservations {
-		reservationRequestsDto = append(reservationRequestsDto, model.ReservationRequestDto{
-			ID:                reservationRequest.ID.Hex(),
-			Status:            reservationRequest.Status,
-			GuestNumber:       reservationRequest.GuestNumber,
-			GuestID:           reservationRequest.GuestID,
-			AccommodationID:   reservationRequest.AccommodationID,
-			StartDate:         reservationRequest.StartDate,
-			EndDate:           reservationRequest.EndDate,
-			AccommodationName: reservationRequest.AccommodationName})
-	}
+func (h *Handler) DeclineReservationGroup(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("declineReservationGroupHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling decline reservation group at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(reservationRequestsDto)
+	h.updateReservationGroup(w, r, span, model.HOST, func(groupID primitive.ObjectID, userID uint) (*model.ReservationGroup, error) {
+		return h.Service.DeclineReservationGroup(groupID, userID, ctx)
+	})
 }
 
-func (h *Handler) GetWheatherGuestWasWithHost(w http.ResponseWriter, r *http.Request) {
-	span := tracer.StartSpanFromRequest("getWheatherGuestWasWithHostHandler", h.Tracer, r)
-	defer span.Finish()
-	span.LogFields(
-		tracer.LogString("handler", fmt.Sprintf("handling get whether guest was accomodated by host at %s\n", r.URL.Path)),
+func (h *Handler) CancelReservationGroup(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("cancelReservationGroupHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling cancel reservation group at %s\n", r.URL.Path)),
 	)
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
+	h.updateReservationGroup(w, r, span, model.GUEST, func(groupID primitive.ObjectID, userID uint) (*model.ReservationGroup, error) {
+		return h.Service.CancelReservationGroup(groupID, userID, ctx)
+	})
+}
+
+// updateReservationGroup authorizes the user in the given role and applies update to the group named in the path.
+func (h *Handler) updateReservationGroup(w http.ResponseWriter, r *http.Request, span trace.Span, role model.UserRole, update func(groupID primitive.ObjectID, userID uint) (*model.ReservationGroup, error)) {
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 	w.Header().Set("Content-Type", "application/json")
-	userResponse := h.authorizeGuest(r)
-	if userResponse.Role != "GUEST" {
+
+	var userResponse *model.UserResponseDTO
+	if role == model.HOST {
+		userResponse = h.authorizeHost(r, ctx)
+	} else {
+		userResponse = h.authorizeGuest(r, ctx)
+	}
+	if userResponse == nil || userResponse.Role != role {
 		tracer.LogError(span, errors.New("Unauthorized"))
 		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not an authorised guest", StatusCode: http.StatusUnauthorized})
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a " + strings.ToLower(string(role)), StatusCode: http.StatusUnauthorized})
 		return
 	}
 
 	params := mux.Vars(r)
-	guestID, _ := strconv.Atoi(params["guestId"])
-	ownerID, _ := strconv.Atoi(params["hostId"])
+	groupID, err := primitive.ObjectIDFromHex(params["id"])
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
 
-	response := h.Service.GetWheatherGuestWasWithHost(uint(guestID), uint(ownerID), ctx)
+	reservationGroup, err := update(groupID, userResponse.Id)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(toReservationGroupDto(reservationGroup))
 }
 
-func (h *Handler) GetWheatherGuestWasInAccomodation(w http.ResponseWriter, r *http.Request) {
-	span := tracer.StartSpanFromRequest("getWheatherGuestWasInAccomodationHandler", h.Tracer, r)
-	defer span.Finish()
-	span.LogFields(
-		tracer.LogString("handler", fmt.Sprintf("handling get whether guest was in accomodation at %s\n", r.URL.Path)),
-	)
-	ctx := tracer.ContextWithSpan(context.Background(), span)
-
-	w.Header().Set("Content-Type", "application/json")
-	userResponse := h.authorizeGuest(r)
-	if userResponse.Role != "GUEST" {
-		tracer.LogError(span, errors.New("Unauthorized"))
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not an authorised guest", StatusCode: http.StatusUnauthorized})
+func writeReservationGroups(w http.ResponseWriter, reservationGroups *[]model.ReservationGroup) {
+	if reservationGroups == nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "reservation groups could not be loaded", StatusCode: http.StatusInternalServerError})
 		return
 	}
 
-	params := mux.Vars(r)
-	guestID, _ := strconv.Atoi(params["guestId"])
-	accommodationID, _ := strconv.Atoi(params["accomodationId"])
-
-	response := h.Service.GetWheatherGuestWasInAccomodation(uint(guestID), uint(accommodationID), ctx)
+	reservationGroupsDto := []model.ReservationGroupDto{}
+	for i := range *reservationGroups {
+		reservationGroupsDto = append(reservationGroupsDto, toReservationGroupDto(&(*reservationGroups)[i]))
+	}
 
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(reservationGroupsDto)
 }
 
 func (h *Handler) DeleteReservationRequest(w http.ResponseWriter, r *http.Request) {
 	span := tracer.StartSpanFromRequest("deleteReservationRequestHandler", h.Tracer, r)
-	defer span.Finish()
-	span.LogFields(
+	defer span.End()
+	span.SetAttributes(
 		tracer.LogString("handler", fmt.Sprintf("handling delete reservation request at %s\n", r.URL.Path)),
 	)
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
-	userResponse := h.authorizeGuest(r)
+	userResponse := h.authorizeGuest(r, ctx)
 	if userResponse.Role != "GUEST" {
 		tracer.LogError(span, errors.New("Unauthorized"))
 		w.WriteHeader(http.StatusUnauthorized)
@@ -261,11 +1264,11 @@ func (h *Handler) DeleteReservationRequest(w http.ResponseWriter, r *http.Reques
 
 func (h *Handler) AcceptReservationRequest(w http.ResponseWriter, r *http.Request) {
 	span := tracer.StartSpanFromRequest("acceptReservationRequestHandler", h.Tracer, r)
-	defer span.Finish()
-	span.LogFields(
+	defer span.End()
+	span.SetAttributes(
 		tracer.LogString("handler", fmt.Sprintf("handling accept reservation request at %s\n", r.URL.Path)),
 	)
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
 	w.Header().Set("Content-Type", "application/json")
 
@@ -280,7 +1283,7 @@ func (h *Handler) AcceptReservationRequest(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	userResponse := h.authorizeHost(r)
+	userResponse := h.authorizeHost(r, ctx)
 	if userResponse.Role != "HOST" {
 		tracer.LogError(span, errors.New("Unauthorized"))
 		w.WriteHeader(http.StatusUnauthorized)
@@ -297,28 +1300,20 @@ func (h *Handler) AcceptReservationRequest(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	reservationRequestsDto := model.ReservationRequestDto{
-		ID:                reservation.ID.Hex(),
-		Status:            reservation.Status,
-		GuestNumber:       reservation.GuestNumber,
-		GuestID:           reservation.GuestID,
-		AccommodationID:   reservation.AccommodationID,
-		StartDate:         reservation.StartDate,
-		EndDate:           reservation.EndDate,
-		AccommodationName: reservation.AccommodationName}
+	reservationRequestsDto := toReservationRequestDto(reservation)
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(reservationRequestsDto)
 }
 
 func (h *Handler) CancelReservationRequest(w http.ResponseWriter, r *http.Request) {
 	span := tracer.StartSpanFromRequest("cancelReservationRequestHandler", h.Tracer, r)
-	defer span.Finish()
-	span.LogFields(
+	defer span.End()
+	span.SetAttributes(
 		tracer.LogString("handler", fmt.Sprintf("handling cancel reservation request at %s\n", r.URL.Path)),
 	)
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
-	userResponse := h.authorizeGuest(r)
+	userResponse := h.authorizeGuest(r, ctx)
 	if userResponse.Role != "GUEST" {
 		tracer.LogError(span, errors.New("Unauthorized"))
 		w.WriteHeader(http.StatusUnauthorized)
@@ -339,7 +1334,130 @@ func (h *Handler) CancelReservationRequest(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	_, err = h.Service.CancelReservationRequest(objectId, userResponse.Id, ctx)
+	reservation, err := h.Service.CancelReservationRequest(objectId, userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toReservationRequestDto(reservation))
+}
+
+func (h *Handler) CheckIn(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("checkInHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling check in at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	id, _ := params["id"]
+
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	userResponse := h.authorizeHost(r, ctx)
+	if userResponse == nil || userResponse.Role != "HOST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	reservation, err := h.Service.CheckIn(objectId, userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toReservationRequestDto(reservation))
+}
+
+func (h *Handler) CheckOut(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("checkOutHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling check out at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	id, _ := params["id"]
+
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	userResponse := h.authorizeHost(r, ctx)
+	if userResponse == nil || userResponse.Role != "HOST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	reservation, err := h.Service.CheckOut(objectId, userResponse.Id, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toReservationRequestDto(reservation))
+}
+
+func (h *Handler) MarkNoShow(w http.ResponseWriter, r *http.Request) {
+	span := tracer.StartSpanFromRequest("markNoShowHandler", h.Tracer, r)
+	defer span.End()
+	span.SetAttributes(
+		tracer.LogString("handler", fmt.Sprintf("handling mark no-show at %s\n", r.URL.Path)),
+	)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
+
+	w.Header().Set("Content-Type", "application/json")
+
+	params := mux.Vars(r)
+	id, _ := params["id"]
+
+	objectId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		tracer.LogError(span, err)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: err.Error(), StatusCode: http.StatusBadRequest})
+		return
+	}
+
+	userResponse := h.authorizeHost(r, ctx)
+	if userResponse == nil || userResponse.Role != "HOST" {
+		tracer.LogError(span, errors.New("Unauthorized"))
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(model.ErrorResponse{Message: "user is not a host", StatusCode: http.StatusUnauthorized})
+		return
+	}
+
+	reservation, err := h.Service.MarkNoShow(objectId, userResponse.Id, ctx)
 	if err != nil {
 		tracer.LogError(span, err)
 		w.WriteHeader(http.StatusBadRequest)
@@ -348,17 +1466,18 @@ func (h *Handler) CancelReservationRequest(w http.ResponseWriter, r *http.Reques
 	}
 
 	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(toReservationRequestDto(reservation))
 }
 
 func (h *Handler) CountGuestsCancelledReservations(w http.ResponseWriter, r *http.Request) {
 	span := tracer.StartSpanFromRequest("getCountGuestCancelledReservationsHandler", h.Tracer, r)
-	defer span.Finish()
-	span.LogFields(
+	defer span.End()
+	span.SetAttributes(
 		tracer.LogString("handler", fmt.Sprintf("handling count guests cancelled reservations at %s\n", r.URL.Path)),
 	)
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
-	userResponse := h.authorizeHost(r)
+	userResponse := h.authorizeHost(r, ctx)
 	if userResponse.Role != "HOST" {
 		tracer.LogError(span, errors.New("Unauthorized"))
 		w.WriteHeader(http.StatusUnauthorized)
@@ -386,13 +1505,13 @@ func (h *Handler) CountGuestsCancelledReservations(w http.ResponseWriter, r *htt
 
 func (h *Handler) GetGuestsReservations(w http.ResponseWriter, r *http.Request) {
 	span := tracer.StartSpanFromRequest("getGuestsReservationsHandler", h.Tracer, r)
-	defer span.Finish()
-	span.LogFields(
+	defer span.End()
+	span.SetAttributes(
 		tracer.LogString("handler", fmt.Sprintf("handling get guests all reservations at %s\n", r.URL.Path)),
 	)
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
-	userResponse := h.authorizeGuest(r)
+	userResponse := h.authorizeGuest(r, ctx)
 	if userResponse.Role != "GUEST" {
 		tracer.LogError(span, errors.New("Unauthorized"))
 		w.WriteHeader(http.StatusUnauthorized)
@@ -410,15 +1529,7 @@ func (h *Handler) GetGuestsReservations(w http.ResponseWriter, r *http.Request)
 	reservationRequestsDto := []model.ReservationRequestDto{}
 
 	for _, reservationRequest := range *activeReservations {
-		reservationRequestsDto = append(reservationRequestsDto, model.ReservationRequestDto{
-			ID:                reservationRequest.ID.Hex(),
-			Status:            reservationRequest.Status,
-			GuestNumber:       reservationRequest.GuestNumber,
-			GuestID:           reservationRequest.GuestID,
-			AccommodationID:   reservationRequest.AccommodationID,
-			StartDate:         reservationRequest.StartDate,
-			EndDate:           reservationRequest.EndDate,
-			AccommodationName: reservationRequest.AccommodationName})
+		reservationRequestsDto = append(reservationRequestsDto, toReservationRequestDto(&reservationRequest))
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -427,13 +1538,13 @@ func (h *Handler) GetGuestsReservations(w http.ResponseWriter, r *http.Request)
 
 func (h *Handler) GetOwnersReservations(w http.ResponseWriter, r *http.Request) {
 	span := tracer.StartSpanFromRequest("getOwnersAllHandler", h.Tracer, r)
-	defer span.Finish()
-	span.LogFields(
+	defer span.End()
+	span.SetAttributes(
 		tracer.LogString("handler", fmt.Sprintf("handling get owners all reservations at %s\n", r.URL.Path)),
 	)
-	ctx := tracer.ContextWithSpan(context.Background(), span)
+	ctx := tracer.ContextWithSpan(r.Context(), span)
 
-	userResponse := h.authorizeHost(r)
+	userResponse := h.authorizeHost(r, ctx)
 	if userResponse.Role != "HOST" {
 		tracer.LogError(span, errors.New("Unauthorized"))
 		w.WriteHeader(http.StatusUnauthorized)
@@ -454,6 +1565,12 @@ func (h *Handler) GetOwnersReservations(w http.ResponseWriter, r *http.Request)
 			model.SUBMITTED,
 			model.DECLINED,
 			model.CANCELLED,
+			model.PAYMENT_PENDING,
+			model.PAYMENT_FAILED,
+			model.EXPIRED,
+			model.CHECKED_IN,
+			model.COMPLETED,
+			model.NO_SHOW,
 		}
 	} else {
 		statuses = []model.ReservationRequestStatus{
@@ -466,37 +1583,164 @@ func (h *Handler) GetOwnersReservations(w http.ResponseWriter, r *http.Request)
 	reservationRequestsDto := []model.ReservationRequestDto{}
 
 	for _, reservationRequest := range *activeReservations {
-		reservationRequestsDto = append(reservationRequestsDto, model.ReservationRequestDto{
-			ID:                reservationRequest.ID.Hex(),
-			Status:            reservationRequest.Status,
-			GuestNumber:       reservationRequest.GuestNumber,
-			GuestID:           reservationRequest.GuestID,
-			AccommodationID:   reservationRequest.AccommodationID,
-			StartDate:         reservationRequest.StartDate,
-			EndDate:           reservationRequest.EndDate,
-			AccommodationName: reservationRequest.AccommodationName})
+		reservationRequestsDto = append(reservationRequestsDto, toReservationRequestDto(&reservationRequest))
 	}
 
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(reservationRequestsDto)
 }
 
-func (h *Handler) authorizeHost(r *http.Request) *model.UserResponseDTO {
+// parsePeriod reads the from and to query parameters, defaulting to the month starting today.
+func parsePeriod(r *http.Request) (time.Time, time.Time, error) {
+	from := util.StartOfDay(time.Now().UTC())
+	if value := r.URL.Query().Get("from"); value != "" {
+		parsed, err := time.Parse(util.DateLayout, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		from = parsed
+	}
+
+	to := from.AddDate(0, 1, 0)
+	if value := r.URL.Query().Get("to"); value != "" {
+		parsed, err := time.Parse(util.DateLayout, value)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		to = parsed
+	}
+
+	return from, to, nil
+}
+
+func parseUintQuery(r *http.Request, name string, defaultValue uint) (uint, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, errors.New("Query parameter " + name + " must be a non-negative number")
+	}
+
+	return uint(parsed), nil
+}
+
+func toReservationRequestDto(reservationRequest *model.ReservationRequest) model.ReservationRequestDto {
+	groupID := ""
+	if reservationRequest.GroupID != nil {
+		groupID = reservationRequest.GroupID.Hex()
+	}
+
+	seriesID := ""
+	if reservationRequest.SeriesID != nil {
+		seriesID = reservationRequest.SeriesID.Hex()
+	}
+
+	return model.ReservationRequestDto{
+		ID:                reservationRequest.ID.Hex(),
+		Status:            reservationRequest.Status,
+		GuestNumber:       reservationRequest.GuestNumber,
+		GuestID:           reservationRequest.GuestID,
+		AccommodationID:   reservationRequest.AccommodationID,
+		GroupID:           groupID,
+		SeriesID:          seriesID,
+		StartDate:         reservationRequest.StartDate,
+		EndDate:           reservationRequest.EndDate,
+		TimeZone:          reservationRequest.TimeZone,
+		CheckInDate:       reservationRequest.CheckInDate,
+		CheckOutDate:      reservationRequest.CheckOutDate,
+		AccommodationName: reservationRequest.AccommodationName,
+		Price:             reservationRequest.Price,
+		Refund:            reservationRequest.Refund,
+		Payment:           reservationRequest.Payment,
+		CheckedInAt:       reservationRequest.CheckedInAt,
+		CheckedOutAt:      reservationRequest.CheckedOutAt}
+}
+
+func toReservationGroupDto(reservationGroup *model.ReservationGroup) model.ReservationGroupDto {
+	reservationGroupDto := model.ReservationGroupDto{
+		ID:                  reservationGroup.ID.Hex(),
+		ReservationRequests: []model.ReservationRequestDto{},
+	}
+
+	for i, reservationRequest := range reservationGroup.ReservationRequests {
+		if i == 0 {
+			reservationGroupDto.GuestID = reservationRequest.GuestID
+			reservationGroupDto.OwnerID = reservationRequest.OwnerID
+			reservationGroupDto.Status = reservationRequest.Status
+		} else if reservationGroupDto.Status != reservationRequest.Status {
+			reservationGroupDto.Status = ""
+		}
+
+		if reservationRequest.Price != nil {
+			reservationGroupDto.Total += reservationRequest.Price.Total
+		}
+
+		reservationGroupDto.ReservationRequests = append(reservationGroupDto.ReservationRequests, toReservationRequestDto(&reservationRequest))
+	}
+
+	return reservationGroupDto
+}
+
+func toReservationSeriesDto(reservationSeries *model.ReservationSeries) model.ReservationSeriesDto {
+	reservationSeriesDto := model.ReservationSeriesDto{
+		ID:                  reservationSeries.ID.Hex(),
+		ReservationRequests: []model.ReservationRequestDto{},
+		Conflicts:           reservationSeries.Conflicts,
+	}
+
+	for _, reservationRequest := range reservationSeries.ReservationRequests {
+		reservationSeriesDto.ReservationRequests = append(reservationSeriesDto.ReservationRequests, toReservationRequestDto(&reservationRequest))
+	}
+
+	return reservationSeriesDto
+}
+
+func (h *Handler) authorizeHost(r *http.Request, ctx context.Context) *model.UserResponseDTO {
 	tokenString := r.Header.Get("Authorization")
-	userResponse, err := client.AuthorizeHost(tokenString)
+	userResponse, err := client.AuthorizeHost(tokenString, ctx)
 	if err != nil {
+		logging.OrDefault(h.Logger).DebugContext(ctx, "authorizing host failed", "error", err)
 		return nil
 	}
 
 	return &userResponse
 }
 
-func (h *Handler) authorizeGuest(r *http.Request) *model.UserResponseDTO {
+// authorizeUser accepts both guests and hosts.
+func (h *Handler) authorizeUser(r *http.Request, ctx context.Context) *model.UserResponseDTO {
+	userResponse := h.authorizeGuest(r, ctx)
+	if userResponse != nil && userResponse.Role == model.GUEST {
+		return userResponse
+	}
+
+	userResponse = h.authorizeHost(r, ctx)
+	if userResponse != nil && userResponse.Role == model.HOST {
+		return userResponse
+	}
+
+	return nil
+}
+
+func (h *Handler) authorizeGuest(r *http.Request, ctx context.Context) *model.UserResponseDTO {
 	tokenString := r.Header.Get("Authorization")
-	userResponse, err := client.AuthorizeGueest(tokenString)
+	userResponse, err := client.AuthorizeGueest(tokenString, ctx)
 	if err != nil {
+		logging.OrDefault(h.Logger).DebugContext(ctx, "authorizing guest failed", "error", err)
 		return nil
 	}
 
 	return &userResponse
 }
+
+func writeHealthReport(w http.ResponseWriter, report health.Report, healthy bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if healthy {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,74 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/windbnb/reservation-service/repository"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// MongoChecker pings the primary of the database. Nothing works without it, so it is critical.
+func MongoChecker(db *mongo.Database) Checker {
+	return NewChecker("mongo", true, func(ctx context.Context) error {
+		if db == nil {
+			return errors.New("Database is not connected")
+		}
+
+		return db.Client().Ping(ctx, readpref.Primary())
+	})
+}
+
+// UpstreamChecker calls the liveness probe of one instance of another service. The service degrades rather
+// than stopping to serve when one of them is down, so it is not critical.
+func UpstreamChecker(name string, servicePath string) Checker {
+	return NewChecker(name, false, func(ctx context.Context) error {
+		serviceUrl, err := util.GetServicePathRoundRobin(servicePath)
+		if err != nil {
+			return err
+		}
+
+		request, err := http.NewRequestWithContext(ctx, "GET", serviceUrl.Next().Host+"/probe/liveness", nil)
+		if err != nil {
+			return err
+		}
+
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+
+		if response.StatusCode != http.StatusOK {
+			return errors.New("Liveness probe responded with status " + strconv.Itoa(response.StatusCode))
+		}
+
+		return nil
+	})
+}
+
+// OutboxLagChecker fails when the oldest unpublished outbox event waits for longer than maximumLag, which
+// means the dispatcher stopped or its subscribers keep failing. Parked events are not waited for.
+func OutboxLagChecker(repo repository.IRepository, maximumLag time.Duration) Checker {
+	return NewChecker("outbox", false, func(ctx context.Context) error {
+		events := repo.FindUnpublishedEvents(1, ctx)
+		if events == nil {
+			return errors.New("Outbox could not be read")
+		}
+
+		if len(*events) == 0 {
+			return nil
+		}
+
+		if lag := time.Since((*events)[0].CreatedAt); lag > maximumLag {
+			return errors.New("Oldest unpublished event waits for " + lag.Round(time.Second).String())
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,160 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Status string
+
+const (
+	UP Status = "UP"
+	// DEGRADED means only checks that are not critical failed; the service still serves most requests.
+	DEGRADED Status = "DEGRADED"
+	DOWN     Status = "DOWN"
+)
+
+// Checker checks one dependency of the service. The service is DOWN when a critical checker fails.
+type Checker interface {
+	Name() string
+	Critical() bool
+	Check(ctx context.Context) error
+}
+
+// NewChecker adapts the function to a Checker.
+func NewChecker(name string, critical bool, check func(ctx context.Context) error) Checker {
+	return &checker{name: name, critical: critical, check: check}
+}
+
+type checker struct {
+	name     string
+	critical bool
+	check    func(ctx context.Context) error
+}
+
+func (c *checker) Name() string {
+	return c.name
+}
+
+func (c *checker) Critical() bool {
+	return c.critical
+}
+
+func (c *checker) Check(ctx context.Context) error {
+	return c.check(ctx)
+}
+
+type CheckResult struct {
+	Name      string  `json:"name"`
+	Status    Status  `json:"status"`
+	Critical  bool    `json:"critical"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type Report struct {
+	Status    Status        `json:"status"`
+	Ready     bool          `json:"ready"`
+	CheckedAt time.Time     `json:"checkedAt"`
+	Checks    []CheckResult `json:"checks"`
+}
+
+// Monitor runs the registered checkers and keeps their report for CacheTTL, so frequent probes do not reach
+// the dependencies on every call. Checkers must be registered before the monitor is used.
+type Monitor struct {
+	// CacheTTL is how long a report is answered without running the checkers again.
+	CacheTTL time.Duration
+	// Timeout bounds every single check; a check that does not finish in time fails.
+	Timeout time.Duration
+
+	checkers []Checker
+	ready    atomic.Bool
+	mu       sync.Mutex
+	report   *Report
+}
+
+func (m *Monitor) Register(checker Checker) {
+	m.checkers = append(m.checkers, checker)
+}
+
+// SetReady marks whether the service accepts traffic; it is not ready until it started and once it began
+// shutting down, whatever its dependencies report.
+func (m *Monitor) SetReady(ready bool) {
+	m.ready.Store(ready)
+}
+
+// Report returns the last report if it is recent enough, otherwise it runs all checkers concurrently.
+// Concurrent callers wait for the same run instead of starting their own.
+func (m *Monitor) Report(ctx context.Context) Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.report == nil || time.Since(m.report.CheckedAt) >= m.CacheTTL {
+		// the report is shared, it must not fail because the probe that triggered it went away
+		report := m.check(context.WithoutCancel(ctx))
+		m.report = &report
+	}
+
+	report := *m.report
+	report.Ready = m.ready.Load() && report.Status != DOWN
+	return report
+}
+
+func (m *Monitor) check(ctx context.Context) Report {
+	results := make([]CheckResult, len(m.checkers))
+
+	wg := sync.WaitGroup{}
+	for i, checker := range m.checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = m.run(checker, ctx)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	status := UP
+	for _, result := range results {
+		if result.Status == DOWN && result.Critical {
+			status = DOWN
+		} else if result.Status == DOWN && status == UP {
+			status = DEGRADED
+		}
+	}
+
+	return Report{Status: status, CheckedAt: time.Now(), Checks: results}
+}
+
+func (m *Monitor) run(checker Checker, ctx context.Context) CheckResult {
+	checkCtx, cancel := context.WithTimeout(ctx, m.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := safeCheck(checker, checkCtx)
+	result := CheckResult{
+		Name:      checker.Name(),
+		Status:    UP,
+		Critical:  checker.Critical(),
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = DOWN
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+// safeCheck reports a panicking checker as failed instead of taking the service down with it.
+func safeCheck(checker Checker, ctx context.Context) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("Check panicked: %v", recovered)
+		}
+	}()
+
+	return checker.Check(ctx)
+}
@@ -0,0 +1,210 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+)
+
+// Event is the part of a VEVENT the service works with. All-day events start and end at midnight UTC
+// of their dates, the end being exclusive as in the iCalendar format.
+type Event struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+	AllDay  bool
+}
+
+// Encode writes the events as an iCalendar (RFC 5545) document.
+func Encode(w io.Writer, calendarName string, events []Event) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Windbnb//Reservation Service//EN",
+		"CALSCALE:GREGORIAN",
+		"X-WR-CALNAME:" + escapeText(calendarName),
+	}
+
+	stamp := time.Now().UTC().Format(dateTimeLayout) + "Z"
+	for _, event := range events {
+		lines = append(lines, "BEGIN:VEVENT", "UID:"+event.UID, "DTSTAMP:"+stamp)
+		if event.AllDay {
+			lines = append(lines,
+				"DTSTART;VALUE=DATE:"+event.Start.Format(dateLayout),
+				"DTEND;VALUE=DATE:"+event.End.Format(dateLayout))
+		} else {
+			lines = append(lines,
+				"DTSTART:"+event.Start.UTC().Format(dateTimeLayout)+"Z",
+				"DTEND:"+event.End.UTC().Format(dateTimeLayout)+"Z")
+		}
+		lines = append(lines, "SUMMARY:"+escapeText(event.Summary), "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := io.WriteString(w, fold(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Parse reads the VEVENTs of an iCalendar document. Events without a start are skipped, and events
+// without an end last one day when they are all-day and are instantaneous otherwise.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []Event{}
+	var event *Event
+	for _, line := range lines {
+		name, params, value, found := splitLine(line)
+		if !found {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event = &Event{}
+		case name == "END" && value == "VEVENT":
+			if event != nil && !event.Start.IsZero() {
+				if event.End.IsZero() {
+					event.End = event.Start
+					if event.AllDay {
+						event.End = event.Start.AddDate(0, 0, 1)
+					}
+				}
+				events = append(events, *event)
+			}
+			event = nil
+		case event == nil:
+			continue
+		case name == "UID":
+			event.UID = value
+		case name == "SUMMARY":
+			event.Summary = unescapeText(value)
+		case name == "DTSTART":
+			event.Start, event.AllDay, err = parseTime(params, value)
+			if err != nil {
+				return nil, err
+			}
+		case name == "DTEND":
+			event.End, _, err = parseTime(params, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return events, nil
+}
+
+func parseTime(params map[string]string, value string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == len(dateLayout) {
+		date, err := time.Parse(dateLayout, value)
+		return date, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		dateTime, err := time.Parse(dateTimeLayout, strings.TrimSuffix(value, "Z"))
+		return dateTime, false, err
+	}
+
+	location := time.UTC
+	if tzid, found := params["TZID"]; found {
+		if loaded, err := time.LoadLocation(tzid); err == nil {
+			location = loaded
+		}
+	}
+
+	dateTime, err := time.ParseInLocation(dateTimeLayout, value, location)
+	return dateTime, false, err
+}
+
+func splitLine(line string) (string, map[string]string, string, bool) {
+	separator := strings.Index(line, ":")
+	if separator < 0 {
+		return "", nil, "", false
+	}
+
+	parts := strings.Split(line[:separator], ";")
+	params := map[string]string{}
+	for _, param := range parts[1:] {
+		if key, value, found := strings.Cut(param, "="); found {
+			params[strings.ToUpper(key)] = strings.Trim(value, "\"")
+		}
+	}
+
+	return strings.ToUpper(parts[0]), params, line[separator+1:], true
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	lines := []string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(lines) == 0 || strings.ToUpper(strings.TrimSpace(lines[0])) != "BEGIN:VCALENDAR" {
+		return nil, errors.New("Calendar is not in iCalendar format")
+	}
+
+	return lines, nil
+}
+
+// fold splits lines longer than 75 octets as required by RFC 5545.
+func fold(line string) string {
+	const limit = 75
+	if len(line) <= limit {
+		return line
+	}
+
+	var folded strings.Builder
+	// continuation lines start with a space, which counts towards their limit
+	for width := limit; len(line) > width; width = limit - 1 {
+		cut := width
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		folded.WriteString(line[:cut] + "\r\n ")
+		line = line[cut:]
+	}
+	folded.WriteString(line)
+
+	return folded.String()
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+var textEscaper = strings.NewReplacer("\\", "\\\\", ";", "\\;", ",", "\\,", "\n", "\\n")
+var textUnescaper = strings.NewReplacer("\\\\", "\\", "\\;", ";", "\\,", ",", "\\n", "\n", "\\N", "\n")
+
+func escapeText(text string) string {
+	return textEscaper.Replace(text)
+}
+
+func unescapeText(text string) string {
+	return textUnescaper.Replace(text)
+}
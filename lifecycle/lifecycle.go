@@ -0,0 +1,95 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/windbnb/reservation-service/logging"
+)
+
+// Component is a part of the service that has to be started before the components depending on it and stopped
+// after them. Either function may be nil.
+type Component struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+// Manager starts components one after another, in the order they depend on each other, and stops the started
+// ones in reverse order.
+type Manager struct {
+	Logger  *slog.Logger
+	started []Component
+}
+
+// Start starts the component. It is stopped by Stop only when it started successfully.
+func (m *Manager) Start(ctx context.Context, component Component) error {
+	logger := logging.OrDefault(m.Logger)
+	begin := time.Now()
+
+	if component.Start != nil {
+		if err := component.Start(ctx); err != nil {
+			logger.ErrorContext(ctx, "starting component failed", "component", component.Name, "error", err)
+			return fmt.Errorf("starting %s: %w", component.Name, err)
+		}
+	}
+
+	m.started = append(m.started, component)
+	logger.InfoContext(ctx, "component started", "component", component.Name, "duration_ms", time.Since(begin).Milliseconds())
+	return nil
+}
+
+// Stop stops the started components in reverse order. Every component is stopped even when the ones before it
+// failed or the deadline of ctx passed, so that each releases what it can; all errors are returned joined.
+func (m *Manager) Stop(ctx context.Context) error {
+	logger := logging.OrDefault(m.Logger)
+	problems := []error{}
+
+	for i := len(m.started) - 1; i >= 0; i-- {
+		component := m.started[i]
+		if component.Stop == nil {
+			continue
+		}
+
+		begin := time.Now()
+		if err := component.Stop(ctx); err != nil {
+			logger.ErrorContext(ctx, "stopping component failed", "component", component.Name, "error", err)
+			problems = append(problems, fmt.Errorf("stopping %s: %w", component.Name, err))
+			continue
+		}
+		logger.InfoContext(ctx, "component stopped", "component", component.Name, "duration_ms", time.Since(begin).Milliseconds())
+	}
+
+	m.started = nil
+	return errors.Join(problems...)
+}
+
+// Retry calls fn up to attempts times until it succeeds, waiting backoff after the first failure and twice as
+// long after each further one. It gives up early when ctx is done and returns the last error.
+func Retry(ctx context.Context, attempts int, backoff time.Duration, logger *slog.Logger, fn func(ctx context.Context) error) error {
+	logger = logging.OrDefault(logger)
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+
+		if attempt == attempts {
+			break
+		}
+
+		logger.WarnContext(ctx, "attempt failed, retrying", "attempt", attempt, "attempts", attempts, "backoff", backoff.String(), "error", err)
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+
+	return err
+}
@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// New returns a logger writing records of at least the given level to w, as JSON unless format is "text".
+// Every record logged with a context carries the request ID and the trace and span IDs found in it.
+func New(w io.Writer, level string, format string) *slog.Logger {
+	options := &slog.HandlerOptions{Level: parseLevel(level)}
+
+	var handler slog.Handler = slog.NewJSONHandler(w, options)
+	if strings.EqualFold(format, "text") {
+		handler = slog.NewTextHandler(w, options)
+	}
+
+	return slog.New(contextHandler{Handler: handler})
+}
+
+// Init creates the logger writing to w and makes it the default logger.
+func Init(w io.Writer, level string, format string) *slog.Logger {
+	logger := New(w, level, format)
+	slog.SetDefault(logger)
+	return logger
+}
+
+// OrDefault returns the logger, or the default logger when it is nil.
+func OrDefault(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.Default()
+	}
+
+	return logger
+}
+
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+
+	return slog.LevelInfo
+}
+
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/windbnb/reservation-service/tracer"
+)
+
+// RequestIDHeader carries the ID correlating the log lines of a request, across services as well.
+const RequestIDHeader = "X-Request-ID"
+
+const maximumRequestIDLength = 128
+
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+	"Proxy-Authorization": true,
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx holding the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the ID of the request ctx belongs to, or an empty string outside of a request.
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// InjectRequestID forwards the ID of the request ctx belongs to with the outbound HTTP request.
+func InjectRequestID(ctx context.Context, request *http.Request) {
+	if requestID := RequestID(ctx); requestID != "" {
+		request.Header.Set(RequestIDHeader, requestID)
+	}
+}
+
+// Headers logs HTTP headers with the credentials they carry redacted.
+type Headers http.Header
+
+func (h Headers) LogValue() slog.Value {
+	attrs := []slog.Attr{}
+	for name, values := range h {
+		if redactedHeaders[http.CanonicalHeaderKey(name)] {
+			attrs = append(attrs, slog.String(name, "REDACTED"))
+			continue
+		}
+		attrs = append(attrs, slog.Any(name, values))
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+// Middleware gives every request an ID, taken from the X-Request-ID header when the caller sent a usable one,
+// echoes it in the response and logs an access log line once the request is handled. The request headers are
+// only logged at debug level.
+func Middleware(logger *slog.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, requestID)
+
+		// the access log line joins the trace of the caller, the spans of the handlers are its children
+		ctx := WithRequestID(tracer.Extract(r), requestID)
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(recorder, r.WithContext(ctx))
+
+		level := slog.LevelInfo
+		if recorder.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", recorder.bytes),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("user_agent", r.UserAgent()),
+		}
+		if logger.Enabled(ctx, slog.LevelDebug) {
+			attrs = append(attrs, slog.Any("headers", Headers(r.Header)))
+		}
+
+		logger.LogAttrs(ctx, level, "request handled", attrs...)
+	})
+}
+
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maximumRequestIDLength {
+		return false
+	}
+
+	for _, c := range requestID {
+		if c <= ' ' || c > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(body []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(body)
+	r.bytes += n
+	return n, err
+}
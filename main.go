@@ -2,69 +2,188 @@ package main
 
 import (
 	"context"
-	"github.com/opentracing/opentracing-go"
-	"github.com/rs/cors"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/windbnb/reservation-service/tracer"
-	"log"
-	"net/http"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/trace"
+	"io"
+	"log/slog"
 	"os"
 	"os/signal"
+	"sort"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/windbnb/reservation-service/handler"
+	"github.com/windbnb/reservation-service/client"
+	"github.com/windbnb/reservation-service/config"
+	"github.com/windbnb/reservation-service/lifecycle"
+	"github.com/windbnb/reservation-service/logging"
+	"github.com/windbnb/reservation-service/migration"
+	"github.com/windbnb/reservation-service/payment"
 	"github.com/windbnb/reservation-service/repository"
-	"github.com/windbnb/reservation-service/router"
 	"github.com/windbnb/reservation-service/service"
 	"github.com/windbnb/reservation-service/util"
 )
 
+type command struct {
+	description string
+	run         func(ctx context.Context, cfg config.Config, logger *slog.Logger, args []string) error
+}
+
+var commands = map[string]command{
+	"serve":     {"run the service (the default)", serve},
+	"migrate":   {"apply the pending database migrations and create the indexes", migrate},
+	"seed":      {"generate realistic reservation requests for load tests", seed},
+	"export":    {"dump reservation requests matching a filter as JSONL or CSV", exportReservationRequests},
+	"reconcile": {"check the confirmed stays against the accommodation service", reconcile},
+	"expire":    {"expire the SUBMITTED reservation requests hosts did not respond to, once", expire},
+}
+
 func main() {
-	quit := make(chan os.Signal)
-	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
 
-	db := util.ConnectToDatabase()
+	if name == "help" || (name == "serve" && len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help")) {
+		usage()
+		return
+	}
+
+	command, found := commands[name]
+	if !found {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		usage()
+		os.Exit(2)
+	}
 
-	tracer, closer := tracer.Init("reservation-service")
-	opentracing.SetGlobalTracer(tracer)
-	router := router.ConfigureRouter(&handler.Handler{
-		Tracer:  tracer,
-		Closer:  closer,
-		Service: &service.ReservationRequestService{Repo: &repository.Repository{Db: db}}})
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
 
-	servicePath, servicePathFound := os.LookupEnv("SERVICE_PATH")
-	if !servicePathFound {
-		servicePath = "localhost:8083"
+	cfg, err := config.Load()
+	// the operational commands may write their output to standard output, so only the service logs there
+	logOutput := os.Stderr
+	if name == "serve" {
+		logOutput = os.Stdout
+	}
+	logger := logging.Init(logOutput, cfg.Logging.Level, cfg.Logging.Format)
+	if err != nil {
+		logger.Error("configuration is not valid", "error", err)
+		os.Exit(1)
 	}
+	logger.Info("effective configuration", "command", name, "config", cfg)
 
-	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:3005"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowCredentials: true,
-		Debug:            true,
-		AllowedHeaders:   []string{"Accept", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization"},
-	})
-	srv := &http.Server{Addr: servicePath, Handler: c.Handler(router)}
-
-	go func() {
-		log.Println("server starting")
-		if err := srv.ListenAndServe(); err != nil {
-			if err != http.ErrServerClosed {
-				log.Fatal(err)
-			}
-		}
-	}()
+	util.DefaultTimeZone = cfg.Reservations.DefaultTimeZone
+	client.UserServiceURL = cfg.Upstreams.UserServiceURL
+	client.AccommodationServiceURL = cfg.Upstreams.AccommodationServiceURL
+	client.RequestTimeout = cfg.Upstreams.RequestTimeout
+	client.Logger = logger
+
+	err = command.run(ctx, cfg, logger, args)
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(2)
+	}
+	if err != nil {
+		logger.Error("command failed", "command", name, "error", err)
+		os.Exit(1)
+	}
+	logger.Info("command finished", "command", name)
+}
 
-	<-quit
+func usage() {
+	names := []string{}
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
-	log.Println("service shutting down ...")
+	fmt.Fprintln(os.Stderr, "usage: reservation-service [command] [flags]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].description)
+	}
+	fmt.Fprintln(os.Stderr, "\nRun a command with -h for its flags. Every command reads the same configuration.")
+}
 
-	// gracefully stop server
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// connect starts the components every command needs, the database and then the tracer.
+func connect(ctx context.Context, cfg config.Config, app *lifecycle.Manager, logger *slog.Logger) (*mongo.Database, trace.Tracer, io.Closer, error) {
+	var db *mongo.Database
+	err := app.Start(ctx, lifecycle.Component{
+		Name: "mongo",
+		Start: func(ctx context.Context) error {
+			return lifecycle.Retry(ctx, cfg.Database.ConnectAttempts, cfg.Database.ConnectBackoff, logger, func(ctx context.Context) (err error) {
+				db, err = util.ConnectToDatabase(cfg.Database.URI, cfg.Database.Name, cfg.Database.ConnectTimeout, ctx)
+				return err
+			})
+		},
+		Stop: func(ctx context.Context) error {
+			return db.Client().Disconnect(ctx)
+		}})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var traceTracer trace.Tracer
+	var closer io.Closer
+	err = app.Start(ctx, lifecycle.Component{
+		Name: "tracer",
+		Start: func(ctx context.Context) error {
+			traceTracer, closer = tracer.Init("reservation-service")
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			return closer.Close()
+		}})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return db, traceTracer, closer, nil
+}
+
+// withRepository runs fn with the repository of a connected database and disconnects afterwards.
+func withRepository(ctx context.Context, cfg config.Config, logger *slog.Logger, fn func(db *mongo.Database, repo *repository.Repository) error) error {
+	app := &lifecycle.Manager{Logger: logger}
+	db, _, _, err := connect(ctx, cfg, app, logger)
+	if err == nil {
+		err = fn(db, &repository.Repository{Db: db, Logger: logger})
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal(err)
+	return errors.Join(err, app.Stop(stopCtx))
+}
+
+// migrateSchema applies the pending migrations, when enabled, and creates the indexes.
+func migrateSchema(runMigrations bool, db *mongo.Database, repo *repository.Repository, logger *slog.Logger, ctx context.Context) error {
+	if runMigrations {
+		migrationCtx, cancelMigrations := context.WithTimeout(ctx, time.Minute)
+		defer cancelMigrations()
+
+		applied, err := migration.Run(db, migration.Migrations, migrationCtx)
+		if err != nil {
+			return err
+		}
+		if applied > 0 {
+			logger.Info("applied database migrations", "count", applied)
+		}
 	}
-	log.Println("server stopped")
+
+	return repo.EnsureIndexes(ctx)
+}
+
+func newService(cfg config.Config, repo repository.IRepository, logger *slog.Logger) *service.ReservationRequestService {
+	return &service.ReservationRequestService{
+		Repo:                repo,
+		PaymentProvider:     payment.NewFakePaymentProvider(cfg.Reservations.PaymentAuthorizationTTL),
+		ResponseWindow:      cfg.Reservations.ResponseWindow,
+		ReviewWindow:        cfg.Reservations.ReviewWindow,
+		CalendarFeedSecret:  cfg.Calendar.FeedSecret,
+		CalendarImportDir:   cfg.Calendar.ImportDir,
+		CalendarImportHosts: cfg.Calendar.ImportHosts,
+		Logger:              logger}
 }
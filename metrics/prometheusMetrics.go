@@ -3,6 +3,7 @@ package metrics
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
@@ -13,6 +14,7 @@ import (
 type responseWriter struct {
 	http.ResponseWriter
 	statusCode int
+	size       int
 }
 
 func (r *responseWriter) WriteHeader(status int) {
@@ -20,58 +22,51 @@ func (r *responseWriter) WriteHeader(status int) {
 	r.ResponseWriter.WriteHeader(status)
 }
 
-var (
-	// The Prometheus metrics that will be exposed.
-	httpHits = prometheus.NewCounter(
-		prometheus.CounterOpts{
-			Name: "http_hit_total",
-			Help: "Total number of HTTP hits.",
-		},
-	)
+func (r *responseWriter) Write(body []byte) (int, error) {
+	size, err := r.ResponseWriter.Write(body)
+	r.size += size
+	return size, err
+}
 
-	trafficAccumulationMetric = prometheus.NewCounter(
+var (
+	// The Prometheus metrics that will be exposed. Requests are labelled by the path template of their route,
+	// never by the raw path or anything about the client, so the number of time series stays bounded.
+	httpRequests = prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Name: "traffic_total",
-			Help: "Total traffic in GB.",
+			Name: "http_requests_total",
+			Help: "Total number of HTTP requests by route, method and status class.",
 		},
-	)
+		[]string{"route", "method", "status_class"})
 
-	httpHitsSuccess = prometheus.NewCounter(
-		prometheus.CounterOpts{
-			Name: "success_http_hit_total",
-			Help: "Total number of successfull HTTP hits.",
+	httpRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "Duration of HTTP requests by route, method and status class.",
+			Buckets: prometheus.DefBuckets,
 		},
-	)
+		[]string{"route", "method", "status_class"})
 
-	httpHitsFailed = prometheus.NewCounter(
-		prometheus.CounterOpts{
-			Name: "failed_http_hit_total",
-			Help: "Total number of failed HTTP hits.",
+	httpResponseSize = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_response_size_bytes",
+			Help:    "Size of HTTP response bodies by route and method.",
+			Buckets: prometheus.ExponentialBuckets(100, 4, 8),
 		},
-	)
+		[]string{"route", "method"})
 
-	httpStatusNotFoundCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "not_found_response_status_counter",
-			Help: "Counter for the 404 status of the HTTP response.",
+	httpRequestsInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests currently being served by route.",
 		},
-		[]string{"status"})
-
-	uniqueVisitorCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "unique_visitor_counter",
-			Help: "Counter for each unique visitor.",
-		},
-		[]string{"visitor"})
+		[]string{"route"})
 
 	// Add all metrics that will be resisted
 	metricsList = []prometheus.Collector{
-		httpHits,
-		httpHitsSuccess,
-		httpHitsFailed,
-		uniqueVisitorCounter,
-		httpStatusNotFoundCounter,
-		trafficAccumulationMetric,
+		httpRequests,
+		httpRequestDuration,
+		httpResponseSize,
+		httpRequestsInFlight,
 	}
 
 	// Prometheus Registry to register metrics.
@@ -89,31 +84,42 @@ func MetricsHandler() http.Handler {
 
 func MetricProxy(f func(http.ResponseWriter, *http.Request)) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		route := mux.CurrentRoute(r)
-		path, _ := route.GetPathTemplate()
-
-		userAgent := r.Header.Get("User-Agent")
-		rw := &responseWriter{w, http.StatusOK}
+		route := routeTemplate(r)
+		method := r.Method
 
-		f(rw, r) // original function call
+		inFlight := httpRequestsInFlight.WithLabelValues(route)
+		inFlight.Inc()
+		defer inFlight.Dec()
 
-		httpHits.Inc()
+		rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		start := time.Now()
 
-		requestSize, _ := strconv.Atoi((r.Header.Get("Content-Length")))
-		trafficAccumulationMetric.Add(float64(requestSize) / 1e9)
+		f(rw, r) // original function call
 
-		uniqueVisitorCounter.WithLabelValues(r.RemoteAddr + " (" + r.Host + ") via " + userAgent).Inc()
+		statusClass := strconv.Itoa(rw.statusCode/100) + "xx"
+		httpRequests.WithLabelValues(route, method, statusClass).Inc()
+		httpRequestDuration.WithLabelValues(route, method, statusClass).Observe(time.Since(start).Seconds())
+		httpResponseSize.WithLabelValues(route, method).Observe(float64(rw.size))
+	}
+}
 
-		if rw.statusCode >= 200 && rw.statusCode < 400 {
-			httpHitsSuccess.Inc()
-		}
+// MetricMiddleware records the metrics of MetricProxy for every route of the router, including the requests a later
+// middleware answers without calling the handler.
+func MetricMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(MetricProxy(next.ServeHTTP))
+}
 
-		if rw.statusCode >= 400 && rw.statusCode < 600 {
-			httpHitsFailed.Inc()
-		}
+// routeTemplate returns the path template of the matched route, such as /api/reservationRequest/{id}/accept.
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "unmatched"
+	}
 
-		if rw.statusCode == 404 {
-			httpStatusNotFoundCounter.WithLabelValues(path).Inc()
-		}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return "unmatched"
 	}
+
+	return template
 }
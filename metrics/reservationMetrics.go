@@ -0,0 +1,153 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/windbnb/reservation-service/model"
+)
+
+const (
+	// DECLINED_BY_HOST labels reservation requests the host declined, DECLINED_COMPETING the ones declined
+	// because another reservation request for the same nights was accepted.
+	DECLINED_BY_HOST   = "host"
+	DECLINED_COMPETING = "competing"
+)
+
+var (
+	expiredReservationRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reservation_requests_expired_total",
+			Help: "Total number of SUBMITTED reservation requests that expired, by reason.",
+		},
+		[]string{"reason"})
+
+	createdReservationRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reservation_requests_created_total",
+			Help: "Total number of created reservation requests, by accept type of the accommodation.",
+		},
+		[]string{"accept_type"})
+
+	acceptedReservationRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reservation_requests_accepted_total",
+			Help: "Total number of accepted reservation requests, by accept type of the accommodation.",
+		},
+		[]string{"accept_type"})
+
+	declinedReservationRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reservation_requests_declined_total",
+			Help: "Total number of declined reservation requests, by accept type of the accommodation and reason.",
+		},
+		[]string{"accept_type", "reason"})
+
+	cancelledReservationRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reservation_requests_cancelled_total",
+			Help: "Total number of reservations cancelled by guests, by accept type of the accommodation.",
+		},
+		[]string{"accept_type"})
+
+	deletedReservationRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "reservation_requests_deleted_total",
+			Help: "Total number of SUBMITTED reservation requests withdrawn by guests, by accept type of the accommodation.",
+		},
+		[]string{"accept_type"})
+
+	timeToAccept = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "reservation_time_to_accept_seconds",
+			Help:    "Time from creating a reservation request until it is accepted, by accept type of the accommodation.",
+			Buckets: []float64{1, 10, 60, 600, 3600, 6 * 3600, 24 * 3600, 48 * 3600, 7 * 24 * 3600},
+		},
+		[]string{"accept_type"})
+
+	leadTime = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "reservation_lead_time_days",
+			Help:    "Days between creating a reservation request and checking in, by accept type of the accommodation.",
+			Buckets: []float64{1, 3, 7, 14, 30, 60, 90, 180, 365},
+		},
+		[]string{"accept_type"})
+
+	stayLength = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "reservation_stay_length_nights",
+			Help:    "Number of nights of created reservation requests, by accept type of the accommodation.",
+			Buckets: []float64{1, 2, 3, 5, 7, 14, 28, 60},
+		},
+		[]string{"accept_type"})
+
+	submittedBacklog = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "reservation_requests_submitted_backlog",
+			Help: "Number of SUBMITTED reservation requests waiting for the response of the host.",
+		},
+		[]string{"host_id"})
+)
+
+func init() {
+	prometheusRegistry.MustRegister(
+		expiredReservationRequests,
+		createdReservationRequests,
+		acceptedReservationRequests,
+		declinedReservationRequests,
+		cancelledReservationRequests,
+		deletedReservationRequests,
+		timeToAccept,
+		leadTime,
+		stayLength,
+		submittedBacklog,
+	)
+}
+
+func ReservationRequestExpired(reason model.ExpiryReason) {
+	expiredReservationRequests.WithLabelValues(string(reason)).Inc()
+}
+
+func ReservationRequestCreated(reservationRequest *model.ReservationRequest, nights int) {
+	label := acceptTypeLabel(reservationRequest)
+	createdReservationRequests.WithLabelValues(label).Inc()
+	leadTime.WithLabelValues(label).Observe(reservationRequest.StartDate.Sub(reservationRequest.CreatedAt).Hours() / 24)
+	stayLength.WithLabelValues(label).Observe(float64(nights))
+}
+
+func ReservationRequestAccepted(reservationRequest *model.ReservationRequest, acceptedAt time.Time) {
+	label := acceptTypeLabel(reservationRequest)
+	acceptedReservationRequests.WithLabelValues(label).Inc()
+	timeToAccept.WithLabelValues(label).Observe(acceptedAt.Sub(reservationRequest.CreatedAt).Seconds())
+}
+
+func ReservationRequestsDeclined(reservationRequest *model.ReservationRequest, reason string, count int) {
+	declinedReservationRequests.WithLabelValues(acceptTypeLabel(reservationRequest), reason).Add(float64(count))
+}
+
+func ReservationRequestCancelled(reservationRequest *model.ReservationRequest) {
+	cancelledReservationRequests.WithLabelValues(acceptTypeLabel(reservationRequest)).Inc()
+}
+
+func ReservationRequestDeleted(reservationRequest *model.ReservationRequest) {
+	deletedReservationRequests.WithLabelValues(acceptTypeLabel(reservationRequest)).Inc()
+}
+
+// SetSubmittedBacklog replaces the backlog of every host, so hosts without SUBMITTED requests drop out.
+func SetSubmittedBacklog(backlog map[uint]int) {
+	submittedBacklog.Reset()
+	for hostID, count := range backlog {
+		submittedBacklog.WithLabelValues(strconv.FormatUint(uint64(hostID), 10)).Set(float64(count))
+	}
+}
+
+// acceptTypeLabel is the accept type the accommodation had when the reservation request was created. Requests
+// created before it was recorded are labelled UNKNOWN.
+func acceptTypeLabel(reservationRequest *model.ReservationRequest) string {
+	if reservationRequest.AcceptReservationType == "" {
+		return "UNKNOWN"
+	}
+
+	return string(reservationRequest.AcceptReservationType)
+}
@@ -0,0 +1,74 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migration changes stored documents from one schema version to the next. Up has to be safe to run again
+// after a partial failure, since the version is only recorded once Up succeeds.
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(db *mongo.Database, ctx context.Context) error
+}
+
+type appliedMigration struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"appliedAt"`
+}
+
+// Run applies the migrations not yet recorded in the schema_migration collection, in the order of their
+// versions, and returns how many were applied.
+func Run(db *mongo.Database, migrations []Migration, ctx context.Context) (int, error) {
+	collection := db.Collection("schema_migration")
+
+	cursor, err := collection.Find(ctx, bson.D{})
+	if err != nil {
+		return 0, err
+	}
+
+	applied := map[int]bool{}
+	for cursor.Next(ctx) {
+		var appliedMigration appliedMigration
+		if err := cursor.Decode(&appliedMigration); err != nil {
+			cursor.Close(ctx)
+			return 0, err
+		}
+		applied[appliedMigration.Version] = true
+	}
+	cursor.Close(ctx)
+
+	pending := []Migration{}
+	for _, migration := range migrations {
+		if !applied[migration.Version] {
+			pending = append(pending, migration)
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].Version < pending[j].Version })
+
+	for i, migration := range pending {
+		slog.InfoContext(ctx, "applying migration", "version", migration.Version, "description", migration.Description)
+		if err := migration.Up(db, ctx); err != nil {
+			return i, fmt.Errorf("migration %d failed: %w", migration.Version, err)
+		}
+
+		_, err := collection.InsertOne(ctx, appliedMigration{
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   time.Now(),
+		})
+		if err != nil {
+			return i, err
+		}
+	}
+
+	return len(pending), nil
+}
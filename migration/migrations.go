@@ -0,0 +1,64 @@
+package migration
+
+import (
+	"context"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Migrations lists every schema migration of the service. New migrations are appended with the next version.
+var Migrations = []Migration{
+	{
+		Version:     1,
+		Description: "add local check-in and check-out dates to reservation requests and time zones to waitlist entries",
+		Up:          addLocalStayDates,
+	},
+	{
+		Version:     2,
+		Description: "complete the accepted stays that ended before check-in and check-out were tracked",
+		Up:          completeEndedAcceptedStays,
+	},
+}
+
+// addLocalStayDates places the stays stored before time zones were supported in the default time zone, which is
+// the only one they could have been meant in.
+func addLocalStayDates(db *mongo.Database, ctx context.Context) error {
+	timeZone := util.LoadLocation("").String()
+
+	localDate := func(field string) bson.D {
+		return bson.D{{"$dateToString", bson.D{
+			{"format", "%Y-%m-%d"},
+			{"date", field},
+			{"timezone", timeZone},
+		}}}
+	}
+
+	_, err := db.Collection("reservation_request").UpdateMany(ctx,
+		bson.D{{"checkInDate", bson.D{{"$in", bson.A{nil, ""}}}}},
+		mongo.Pipeline{{{"$set", bson.D{
+			{"timeZone", timeZone},
+			{"checkInDate", localDate("$startDate")},
+			{"checkOutDate", localDate("$endDate")},
+		}}}})
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Collection("waitlist_entry").UpdateMany(ctx,
+		bson.D{{"timeZone", bson.D{{"$in", bson.A{nil, ""}}}}},
+		bson.D{{"$set", bson.D{{"timeZone", timeZone}}}})
+	return err
+}
+
+// completeEndedAcceptedStays completes the stays that ended while still ACCEPTED. Before stays were completed, a stay
+// was finished once its end date passed, and the queries of finished stays now only look for COMPLETED ones.
+func completeEndedAcceptedStays(db *mongo.Database, ctx context.Context) error {
+	_, err := db.Collection("reservation_request").UpdateMany(ctx,
+		bson.D{{"status", model.ACCEPTED}, {"endDate", bson.D{{"$lt", time.Now()}}}},
+		bson.D{{"$set", bson.D{{"status", model.COMPLETED}}}})
+	return err
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// BookingRules restrict which stays guests may request for an accommodation. Zero values disable a rule.
+type BookingRules struct {
+	AccommodationID uint `bson:"accommodationID" json:"accommodationID"`
+	OwnerID         uint `bson:"ownerID" json:"ownerID"`
+	MinimumNights   uint `bson:"minimumNights" json:"minimumNights"`
+	MaximumNights   uint `bson:"maximumNights" json:"maximumNights"`
+	// AdvanceNoticeHours is how long before the check-in the stay has to be requested at the latest.
+	AdvanceNoticeHours uint `bson:"advanceNoticeHours" json:"advanceNoticeHours"`
+	// BookingHorizonMonths is how far into the future check-ins may be.
+	BookingHorizonMonths uint `bson:"bookingHorizonMonths" json:"bookingHorizonMonths"`
+	// CheckInWeekdays lists the days of the week, such as MONDAY, the stay may start on. Empty allows every day.
+	CheckInWeekdays []string `bson:"checkInWeekdays" json:"checkInWeekdays"`
+	// BufferDays is the number of free nights required between two stays.
+	BufferDays uint      `bson:"bufferDays" json:"bufferDays"`
+	UpdatedAt  time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+type BookingRuleCode string
+
+const (
+	MINIMUM_NIGHTS   BookingRuleCode = "MINIMUM_NIGHTS"
+	MAXIMUM_NIGHTS   BookingRuleCode = "MAXIMUM_NIGHTS"
+	ADVANCE_NOTICE   BookingRuleCode = "ADVANCE_NOTICE"
+	BOOKING_HORIZON  BookingRuleCode = "BOOKING_HORIZON"
+	CHECK_IN_WEEKDAY BookingRuleCode = "CHECK_IN_WEEKDAY"
+	BUFFER_DAYS      BookingRuleCode = "BUFFER_DAYS"
+)
+
+type RuleViolation struct {
+	Code    BookingRuleCode `json:"code"`
+	Message string          `json:"message"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CalendarImport is an external iCalendar feed of an accommodation that is synchronised periodically.
+type CalendarImport struct {
+	ID              primitive.ObjectID `bson:"_id" json:"id"`
+	AccommodationID uint               `bson:"accommodationID" json:"accommodationID"`
+	OwnerID         uint               `bson:"ownerID" json:"ownerID"`
+	URL             string             `bson:"url" json:"url"`
+	// TimeZone is the time zone of the accommodation, in which timed events block the local nights they span.
+	TimeZone     string     `bson:"timeZone" json:"timeZone"`
+	CreatedAt    time.Time  `bson:"createdAt" json:"createdAt"`
+	LastSyncedAt *time.Time `bson:"lastSyncedAt,omitempty" json:"lastSyncedAt,omitempty"`
+	LastError    string     `bson:"lastError,omitempty" json:"lastError,omitempty"`
+}
+
+// BlockedPeriod is a period booked on another platform, imported from an external calendar, during which
+// the accommodation cannot be reserved.
+type BlockedPeriod struct {
+	ID               primitive.ObjectID `bson:"_id"`
+	AccommodationID  uint               `bson:"accommodationID"`
+	CalendarImportID primitive.ObjectID `bson:"calendarImportID"`
+	UID              string             `bson:"uid"`
+	Summary          string             `bson:"summary"`
+	StartDate        time.Time          `bson:"startDate"`
+	EndDate          time.Time          `bson:"endDate"`
+}
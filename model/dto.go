@@ -3,8 +3,10 @@ package model
 import "time"
 
 type ErrorResponse struct {
-	Message    string `json:"message"`
-	StatusCode int    `json:"statusCode"`
+	Message    string               `json:"message"`
+	StatusCode int                  `json:"statusCode"`
+	Violations []RuleViolation      `json:"violations,omitempty"`
+	Conflicts  []OccurrenceConflict `json:"conflicts,omitempty"`
 }
 
 type AcceptReservationType string
@@ -15,13 +17,44 @@ const (
 )
 
 type AccommodationInfo struct {
-	Id                    uint                  `json:"id"`
-	MinimimGuests         uint                  `json:"minimimGuests"`
-	MaximumGuests         uint                  `json:"maximumGuests"`
-	AvailableTerms        []AvailableTerm       `json:"availableTerms"`
-	UserID                uint                  `json:"userID"`
-	AcceptReservationType AcceptReservationType `json:"acceptReservationType"`
-	Name                  string                `json:"name"`
+	Id                    uint                   `json:"id"`
+	MinimimGuests         uint                   `json:"minimimGuests"`
+	MaximumGuests         uint                   `json:"maximumGuests"`
+	AvailableTerms        []AvailableTerm        `json:"availableTerms"`
+	UserID                uint                   `json:"userID"`
+	AcceptReservationType AcceptReservationType  `json:"acceptReservationType"`
+	Name                  string                 `json:"name"`
+	Price                 float64                `json:"price"`
+	PricingType           PricingType            `json:"pricingType"`
+	SeasonalRates         []SeasonalRate         `json:"seasonalRates"`
+	WeekendUplift         float64                `json:"weekendUplift"`
+	LengthOfStayDiscounts []LengthOfStayDiscount `json:"lengthOfStayDiscounts"`
+	CancellationPolicy    CancellationPolicy     `json:"cancellationPolicy"`
+	// TimeZone is the IANA time zone of the accommodation, such as Europe/Belgrade.
+	TimeZone string `json:"timeZone"`
+	// CheckInTime and CheckOutTime are local times of day such as 15:00.
+	CheckInTime  string `json:"checkInTime"`
+	CheckOutTime string `json:"checkOutTime"`
+}
+
+type PricingType string
+
+const (
+	PER_NIGHT PricingType = "PER_NIGHT"
+	PER_GUEST PricingType = "PER_GUEST"
+)
+
+// SeasonalRate overrides the base price for nights between StartDate (inclusive) and EndDate (exclusive).
+type SeasonalRate struct {
+	StartDate time.Time `json:"startDate"`
+	EndDate   time.Time `json:"endDate"`
+	Price     float64   `json:"price"`
+}
+
+// LengthOfStayDiscount is a percentage discount applied to stays of at least MinimumNights nights.
+type LengthOfStayDiscount struct {
+	MinimumNights uint    `json:"minimumNights"`
+	Percentage    float64 `json:"percentage"`
 }
 
 type AvailableTerm struct {
@@ -33,11 +66,21 @@ type ReservationRequestDto struct {
 	Status            ReservationRequestStatus `json:"status"`
 	GuestID           uint                     `json:"guestID"`
 	AccommodationID   uint                     `json:"accommodationID"`
+	GroupID           string                   `json:"groupID,omitempty"`
+	SeriesID          string                   `json:"seriesID,omitempty"`
 	StartDate         time.Time                `json:"startDate"`
 	EndDate           time.Time                `json:"endDate"`
+	TimeZone          string                   `json:"timeZone,omitempty"`
+	CheckInDate       string                   `json:"checkInDate,omitempty"`
+	CheckOutDate      string                   `json:"checkOutDate,omitempty"`
 	GuestNumber       uint                     `json:"guestNumber"`
 	ID                string                   `json:"id"`
 	AccommodationName string                   `json:"accommodationName"`
+	Price             *PriceBreakdown          `json:"price,omitempty"`
+	Refund            *Refund                  `json:"refund,omitempty"`
+	Payment           *Payment                 `json:"payment,omitempty"`
+	CheckedInAt       *time.Time               `json:"checkedInAt,omitempty"`
+	CheckedOutAt      *time.Time               `json:"checkedOutAt,omitempty"`
 }
 
 type UserRole string
@@ -73,3 +116,115 @@ type ReservedTermResponse struct {
 type CancelledReservations struct {
 	Count int `json:"count"`
 }
+
+type RatingEligibilityRequest struct {
+	GuestID         uint `json:"guestID"`
+	HostID          uint `json:"hostID"`
+	AccommodationID uint `json:"accommodationID"`
+}
+
+// RatingEligibilityDto describes whether a guest may review a host, or an accommodation when AccommodationID is set,
+// together with the completed stays that qualify.
+type RatingEligibilityDto struct {
+	GuestID         uint              `json:"guestID"`
+	HostID          uint              `json:"hostID,omitempty"`
+	AccommodationID uint              `json:"accommodationID,omitempty"`
+	Eligible        bool              `json:"eligible"`
+	Stays           []EligibleStayDto `json:"stays"`
+}
+
+type EligibleStayDto struct {
+	ReservationRequestID string    `json:"reservationRequestID"`
+	AccommodationID      uint      `json:"accommodationID"`
+	AccommodationName    string    `json:"accommodationName"`
+	StartDate            time.Time `json:"startDate"`
+	EndDate              time.Time `json:"endDate"`
+	ReviewWindowEndsAt   time.Time `json:"reviewWindowEndsAt"`
+	ReviewSubmitted      bool      `json:"reviewSubmitted"`
+	CanReview            bool      `json:"canReview"`
+}
+
+type NightStatus string
+
+const (
+	AVAILABLE   NightStatus = "AVAILABLE"
+	BOOKED      NightStatus = "BOOKED"
+	REQUESTED   NightStatus = "REQUESTED"
+	UNAVAILABLE NightStatus = "UNAVAILABLE"
+	BLOCKED     NightStatus = "BLOCKED"
+)
+
+type CalendarDto struct {
+	AccommodationID uint               `json:"accommodationID"`
+	From            string             `json:"from"`
+	To              string             `json:"to"`
+	Nights          []CalendarNightDto `json:"nights"`
+}
+
+// CalendarNightDto describes the night starting on Date. A BOOKED night names the reservation request holding it,
+// while RequestedReservationRequestIDs lists every SUBMITTED request competing for it.
+type CalendarNightDto struct {
+	Date                           string      `json:"date"`
+	Status                         NightStatus `json:"status"`
+	ReservationRequestID           string      `json:"reservationRequestID,omitempty"`
+	RequestedReservationRequestIDs []string    `json:"requestedReservationRequestIDs,omitempty"`
+}
+
+type CalendarImportRequest struct {
+	URL string `json:"url"`
+}
+
+type CalendarFeedDto struct {
+	URL string `json:"url"`
+}
+
+// AvailabilityDto lists the bookable nights of an accommodation as date ranges, together with the earliest
+// windows in which a stay of the requested length can start.
+type AvailabilityDto struct {
+	AccommodationID uint           `json:"accommodationID"`
+	From            string         `json:"from"`
+	To              string         `json:"to"`
+	Nights          uint           `json:"nights"`
+	AvailableRanges []DateRangeDto `json:"availableRanges"`
+	Windows         []DateRangeDto `json:"windows"`
+}
+
+// DateRangeDto is the period between the check-in date StartDate and the check-out date EndDate.
+type DateRangeDto struct {
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+// BulkAvailabilityRequest asks which of the accommodations are free for the whole period between the
+// check-in date From and the check-out date To, both in DateLayout.
+type BulkAvailabilityRequest struct {
+	AccommodationIDs []uint `json:"accommodationIDs"`
+	From             string `json:"from"`
+	To               string `json:"to"`
+}
+
+type BulkAvailabilityDto struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Available   []uint `json:"available"`
+	Unavailable []uint `json:"unavailable"`
+}
+
+// ReservationGroupDto is a booking group with its reservation requests. Status is only set while all of
+// them share it, and Total is the sum of their prices.
+type ReservationGroupDto struct {
+	ID                  string                   `json:"id"`
+	GuestID             uint                     `json:"guestID"`
+	OwnerID             uint                     `json:"ownerID"`
+	Status              ReservationRequestStatus `json:"status,omitempty"`
+	Total               float64                  `json:"total"`
+	ReservationRequests []ReservationRequestDto  `json:"reservationRequests"`
+}
+
+// ReservationSeriesDto lists the reservation requests of a series together with the occurrences that could not
+// be booked when it was created.
+type ReservationSeriesDto struct {
+	ID                  string                  `json:"id"`
+	ReservationRequests []ReservationRequestDto `json:"reservationRequests"`
+	Conflicts           []OccurrenceConflict    `json:"conflicts,omitempty"`
+}
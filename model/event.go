@@ -0,0 +1,94 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EventType string
+
+const (
+	RESERVATION_CANCELLED EventType = "RESERVATION_CANCELLED"
+	RESERVATION_EXPIRED   EventType = "RESERVATION_EXPIRED"
+	RESERVATION_DECLINED  EventType = "RESERVATION_DECLINED"
+	WAITLIST_NOTIFIED     EventType = "WAITLIST_NOTIFIED"
+)
+
+type ExpiryReason string
+
+const (
+	RESPONSE_WINDOW_ELAPSED ExpiryReason = "RESPONSE_WINDOW_ELAPSED"
+	START_DATE_PASSED       ExpiryReason = "START_DATE_PASSED"
+)
+
+type DeclineReason string
+
+const (
+	DECLINED_BY_HOST           DeclineReason = "DECLINED_BY_HOST"
+	COMPETING_REQUEST_ACCEPTED DeclineReason = "COMPETING_REQUEST_ACCEPTED"
+)
+
+// Event is stored in the outbox in a separate write after the reservation request was changed, and is published
+// to the subscribers asynchronously. The outbox is best-effort: when saving the event fails, the change is kept and
+// the failure is only logged, so subscribers miss it.
+type Event struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	Type        EventType          `bson:"type"`
+	Payload     string             `bson:"payload"`
+	CreatedAt   time.Time          `bson:"createdAt"`
+	PublishedAt *time.Time         `bson:"publishedAt"`
+	// Attempts counts the dispatches that failed, and LastError holds the error of the last one.
+	Attempts  int    `bson:"attempts"`
+	LastError string `bson:"lastError,omitempty"`
+	// HandledBy names the handlers that already handled the event, so a retry does not run them again.
+	HandledBy []string `bson:"handledBy,omitempty"`
+	// ParkedAt is set when the event failed too many times to be dispatched again.
+	ParkedAt *time.Time `bson:"parkedAt"`
+}
+
+type ReservationCancelledEvent struct {
+	ReservationRequestID string             `json:"reservationRequestID"`
+	GuestID              uint               `json:"guestID"`
+	OwnerID              uint               `json:"ownerID"`
+	AccommodationID      uint               `json:"accommodationID"`
+	RefundAmount         float64            `json:"refundAmount"`
+	Penalty              float64            `json:"penalty"`
+	CancellationPolicy   CancellationPolicy `json:"cancellationPolicy"`
+	AuthorizationID      string             `json:"authorizationID"`
+}
+
+type ReservationExpiredEvent struct {
+	ReservationRequestID string       `json:"reservationRequestID"`
+	GuestID              uint         `json:"guestID"`
+	OwnerID              uint         `json:"ownerID"`
+	AccommodationID      uint         `json:"accommodationID"`
+	StartDate            time.Time    `json:"startDate"`
+	EndDate              time.Time    `json:"endDate"`
+	Reason               ExpiryReason `json:"reason"`
+}
+
+// ReservationDeclinedEvent tells the guest the reservation request was declined. GroupID and SeriesID are set when
+// it belongs to a group or a series.
+type ReservationDeclinedEvent struct {
+	ReservationRequestID string        `json:"reservationRequestID"`
+	GuestID              uint          `json:"guestID"`
+	OwnerID              uint          `json:"ownerID"`
+	AccommodationID      uint          `json:"accommodationID"`
+	GroupID              string        `json:"groupID,omitempty"`
+	SeriesID             string        `json:"seriesID,omitempty"`
+	StartDate            time.Time     `json:"startDate"`
+	EndDate              time.Time     `json:"endDate"`
+	Reason               DeclineReason `json:"reason"`
+}
+
+// WaitlistNotifiedEvent tells the guest the dates they waited for are available. ReservationRequestID is set
+// when the waitlist entry was converted into a reservation request.
+type WaitlistNotifiedEvent struct {
+	WaitlistEntryID      string    `json:"waitlistEntryID"`
+	GuestID              uint      `json:"guestID"`
+	AccommodationID      uint      `json:"accommodationID"`
+	StartDate            time.Time `json:"startDate"`
+	EndDate              time.Time `json:"endDate"`
+	ReservationRequestID string    `json:"reservationRequestID,omitempty"`
+}
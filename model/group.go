@@ -0,0 +1,10 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// ReservationGroup is a set of reservation requests of one guest for accommodations of the same host and the
+// same dates, which are accepted, declined and cancelled together.
+type ReservationGroup struct {
+	ID                  primitive.ObjectID
+	ReservationRequests []ReservationRequest
+}
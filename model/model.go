@@ -8,21 +8,129 @@ import (
 type ReservationRequestStatus string
 
 const (
-	SUBMITTED ReservationRequestStatus = "SUBMITTED"
-	ACCEPTED  ReservationRequestStatus = "ACCEPTED"
-	DECLINED  ReservationRequestStatus = "DECLINED"
-	CANCELLED ReservationRequestStatus = "CANCELLED"
+	SUBMITTED       ReservationRequestStatus = "SUBMITTED"
+	ACCEPTED        ReservationRequestStatus = "ACCEPTED"
+	DECLINED        ReservationRequestStatus = "DECLINED"
+	CANCELLED       ReservationRequestStatus = "CANCELLED"
+	PAYMENT_PENDING ReservationRequestStatus = "PAYMENT_PENDING"
+	PAYMENT_FAILED  ReservationRequestStatus = "PAYMENT_FAILED"
+	EXPIRED         ReservationRequestStatus = "EXPIRED"
+	CHECKED_IN      ReservationRequestStatus = "CHECKED_IN"
+	COMPLETED       ReservationRequestStatus = "COMPLETED"
+	NO_SHOW         ReservationRequestStatus = "NO_SHOW"
 )
 
+// DateBlockingStatuses are the statuses of reservation requests that hold their dates, so no other
+// reservation of the same accommodation may overlap with them.
+var DateBlockingStatuses = []ReservationRequestStatus{ACCEPTED, PAYMENT_PENDING, CHECKED_IN}
+
+// ActiveStatuses are the statuses of confirmed stays that did not finish yet.
+var ActiveStatuses = []ReservationRequestStatus{ACCEPTED, CHECKED_IN}
+
+// ReservationRequest is a stay from StartDate, the check-in time on the check-in date, to EndDate, the check-out
+// time on the check-out date. CheckInDate and CheckOutDate are the same dates in the time zone of the accommodation,
+// so nights are counted on the local calendar regardless of where the guest or the server are.
 type ReservationRequest struct {
-	ID                primitive.ObjectID       `bson:"_id"`
-	StartDate         time.Time                `bson:"startDate"`
-	EndDate           time.Time                `bson:"endDate"`
-	AccommodationID   uint                     `bson:"accommodationID"`
-	GuestID           uint                     `bson:"guestID"`
-	GuestNumber       uint                     `bson:"guestNumber"`
-	Status            ReservationRequestStatus `bson:"status"`
-	OwnerID           uint                     `bson:"ownerID"`
-	ReservedTermId    uint                     `bson:"reservedTermId"`
-	AccommodationName string                   `json:"accommodationName"`
+	ID                      primitive.ObjectID       `bson:"_id"`
+	StartDate               time.Time                `bson:"startDate"`
+	EndDate                 time.Time                `bson:"endDate"`
+	TimeZone                string                   `bson:"timeZone"`
+	CheckInDate             string                   `bson:"checkInDate"`
+	CheckOutDate            string                   `bson:"checkOutDate"`
+	AccommodationID         uint                     `bson:"accommodationID"`
+	GroupID                 *primitive.ObjectID      `bson:"groupID,omitempty"`
+	SeriesID                *primitive.ObjectID      `bson:"seriesID,omitempty"`
+	GuestID                 uint                     `bson:"guestID"`
+	GuestNumber             uint                     `bson:"guestNumber"`
+	Status                  ReservationRequestStatus `bson:"status"`
+	OwnerID                 uint                     `bson:"ownerID"`
+	AcceptReservationType   AcceptReservationType    `bson:"acceptReservationType,omitempty"`
+	ReservedTermId          uint                     `bson:"reservedTermId"`
+	AccommodationName       string                   `json:"accommodationName"`
+	Price                   *PriceBreakdown          `bson:"price,omitempty"`
+	CancellationPolicy      CancellationPolicy       `bson:"cancellationPolicy"`
+	Refund                  *Refund                  `bson:"refund,omitempty"`
+	Payment                 *Payment                 `bson:"payment,omitempty"`
+	CreatedAt               time.Time                `bson:"createdAt"`
+	SeedRun                 string                   `bson:"seedRun,omitempty"`
+	CheckedInAt             *time.Time               `bson:"checkedInAt,omitempty"`
+	CheckedOutAt            *time.Time               `bson:"checkedOutAt,omitempty"`
+	HostReviewedAt          *time.Time               `bson:"hostReviewedAt,omitempty"`
+	AccommodationReviewedAt *time.Time               `bson:"accommodationReviewedAt,omitempty"`
+}
+
+// PriceBreakdown is a snapshot of the price calculated for a stay. It is stored on the
+// reservation request when it is created, so later pricing changes do not alter it.
+type PriceBreakdown struct {
+	PricingType        PricingType    `bson:"pricingType" json:"pricingType"`
+	GuestNumber        uint           `bson:"guestNumber" json:"guestNumber"`
+	Nights             []NightlyPrice `bson:"nights" json:"nights"`
+	Subtotal           float64        `bson:"subtotal" json:"subtotal"`
+	DiscountPercentage float64        `bson:"discountPercentage" json:"discountPercentage"`
+	Discount           float64        `bson:"discount" json:"discount"`
+	Total              float64        `bson:"total" json:"total"`
+	CalculatedAt       time.Time      `bson:"calculatedAt" json:"calculatedAt"`
+}
+
+type NightlyPrice struct {
+	Date          time.Time `bson:"date" json:"date"`
+	BasePrice     float64   `bson:"basePrice" json:"basePrice"`
+	Seasonal      bool      `bson:"seasonal" json:"seasonal"`
+	WeekendUplift float64   `bson:"weekendUplift" json:"weekendUplift"`
+	Price         float64   `bson:"price" json:"price"`
+}
+
+type CancellationPolicy string
+
+const (
+	FLEXIBLE CancellationPolicy = "FLEXIBLE"
+	MODERATE CancellationPolicy = "MODERATE"
+	STRICT   CancellationPolicy = "STRICT"
+)
+
+// Refund is calculated from the price snapshot and the cancellation policy when a guest cancels a reservation.
+type Refund struct {
+	Policy       CancellationPolicy `bson:"policy" json:"policy"`
+	Amount       float64            `bson:"amount" json:"amount"`
+	Penalty      float64            `bson:"penalty" json:"penalty"`
+	CalculatedAt time.Time          `bson:"calculatedAt" json:"calculatedAt"`
+}
+
+type PaymentStatus string
+
+const (
+	AUTHORIZATION_PENDING PaymentStatus = "AUTHORIZATION_PENDING"
+	AUTHORIZED            PaymentStatus = "AUTHORIZED"
+	AUTHORIZATION_FAILED  PaymentStatus = "AUTHORIZATION_FAILED"
+	CAPTURED              PaymentStatus = "CAPTURED"
+	VOIDED                PaymentStatus = "VOIDED"
+)
+
+// Payment tracks the authorization taken from the payment provider when a reservation request is accepted.
+type Payment struct {
+	AuthorizationID string        `bson:"authorizationID" json:"authorizationID"`
+	Status          PaymentStatus `bson:"status" json:"status"`
+	Amount          float64       `bson:"amount" json:"amount"`
+	ExpiresAt       time.Time     `bson:"expiresAt" json:"expiresAt"`
+	CapturedAt      *time.Time    `bson:"capturedAt,omitempty" json:"capturedAt,omitempty"`
+	FailureReason   string        `bson:"failureReason,omitempty" json:"failureReason,omitempty"`
+}
+
+type ReviewTarget string
+
+const (
+	HOST_REVIEW          ReviewTarget = "HOST"
+	ACCOMMODATION_REVIEW ReviewTarget = "ACCOMMODATION"
+)
+
+// ReservationRequestFilter selects reservation requests for bulk reads. Zero fields do not filter. CheckInFrom and
+// CheckInTo bound the local check-in date of the stay, in the time zone of the accommodation, CheckInFrom inclusive
+// and CheckInTo exclusive.
+type ReservationRequestFilter struct {
+	Statuses        []ReservationRequestStatus
+	AccommodationID uint
+	GuestID         uint
+	OwnerID         uint
+	CheckInFrom     string
+	CheckInTo       string
 }
@@ -0,0 +1,30 @@
+package model
+
+type ReconciliationIssueType string
+
+const (
+	// RESERVED_TERM_NOT_RECORDED is a confirmed stay with no reserved term recorded on it. Whether the accommodation
+	// service still holds the recorded terms is not checked, since it offers no way to look one up.
+	RESERVED_TERM_NOT_RECORDED ReconciliationIssueType = "RESERVED_TERM_NOT_RECORDED"
+	// ACCOMMODATION_NOT_FOUND is a confirmed stay in an accommodation the accommodation service does not know.
+	ACCOMMODATION_NOT_FOUND ReconciliationIssueType = "ACCOMMODATION_NOT_FOUND"
+	// OWNER_MISMATCH is a confirmed stay recorded for a host who no longer owns the accommodation.
+	OWNER_MISMATCH ReconciliationIssueType = "OWNER_MISMATCH"
+	// ACCOMMODATION_UNREACHABLE is a confirmed stay that could not be checked since the call failed.
+	ACCOMMODATION_UNREACHABLE ReconciliationIssueType = "ACCOMMODATION_UNREACHABLE"
+)
+
+type ReconciliationIssue struct {
+	Type                 ReconciliationIssueType `json:"type"`
+	ReservationRequestID string                  `json:"reservationRequestID"`
+	AccommodationID      uint                    `json:"accommodationID"`
+	Detail               string                  `json:"detail,omitempty"`
+	Repaired             bool                    `json:"repaired"`
+}
+
+// ReconciliationReport is the outcome of comparing the confirmed stays with the accommodation service.
+type ReconciliationReport struct {
+	Checked  int                   `json:"checked"`
+	Issues   []ReconciliationIssue `json:"issues"`
+	Repaired int                   `json:"repaired"`
+}
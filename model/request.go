@@ -4,10 +4,42 @@ import (
 	"time"
 )
 
+// CreateReservationRequest asks for a stay starting on CheckInDate, a date in DateLayout on the local calendar of
+// the accommodation. Clients not sending it yet have the date taken from StartDate as they sent it. With Recurrence
+// set the stay is repeated as a series, and SkipConflicts books the occurrences that are free even when others are not.
+// GuestID is never read from the body, it is the authenticated guest. The JSON names match the field names of
+// clients written before the tags, since decoding ignores the case.
 type CreateReservationRequest struct {
-	StartDate       time.Time
-	NumberOfDays    uint
-	AccommodationID uint
-	GuestID         uint
-	GuestNumber     uint
+	StartDate       time.Time       `json:"startDate"`
+	CheckInDate     string          `json:"checkInDate,omitempty"`
+	NumberOfDays    uint            `json:"numberOfDays"`
+	AccommodationID uint            `json:"accommodationID"`
+	GuestID         uint            `json:"-"`
+	GuestNumber     uint            `json:"guestNumber"`
+	Recurrence      *RecurrenceSpec `json:"recurrence,omitempty"`
+	SkipConflicts   bool            `json:"skipConflicts,omitempty"`
+}
+
+type JoinWaitlistRequest struct {
+	StartDate       time.Time `json:"startDate"`
+	CheckInDate     string    `json:"checkInDate,omitempty"`
+	NumberOfDays    uint      `json:"numberOfDays"`
+	AccommodationID uint      `json:"accommodationID"`
+	GuestID         uint      `json:"-"`
+	GuestNumber     uint      `json:"guestNumber"`
+	AutoSubmit      bool      `json:"autoSubmit,omitempty"`
+}
+
+// CreateReservationGroupRequest books several accommodations of the same host for the same stay at once.
+type CreateReservationGroupRequest struct {
+	StartDate    time.Time              `json:"startDate"`
+	CheckInDate  string                 `json:"checkInDate,omitempty"`
+	NumberOfDays uint                   `json:"numberOfDays"`
+	GuestID      uint                   `json:"-"`
+	Units        []ReservationGroupUnit `json:"units"`
+}
+
+type ReservationGroupUnit struct {
+	AccommodationID uint `json:"accommodationID"`
+	GuestNumber     uint `json:"guestNumber"`
 }
@@ -0,0 +1,34 @@
+package model
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+type RecurrenceFrequency string
+
+const (
+	WEEKLY  RecurrenceFrequency = "WEEKLY"
+	MONTHLY RecurrenceFrequency = "MONTHLY"
+)
+
+// RecurrenceSpec repeats a stay every Interval weeks or months, Count times or until the check-in date Until,
+// whichever comes first. Until is a date in DateLayout and includes stays checking in on it.
+type RecurrenceSpec struct {
+	Frequency RecurrenceFrequency `json:"frequency"`
+	Interval  uint                `json:"interval,omitempty"`
+	Count     uint                `json:"count,omitempty"`
+	Until     string              `json:"until,omitempty"`
+}
+
+// ReservationSeries is the set of reservation requests created from one recurring stay. Each of them is accepted
+// and cancelled on its own, while the guest may also cancel the whole series at once.
+type ReservationSeries struct {
+	ID                  primitive.ObjectID
+	ReservationRequests []ReservationRequest
+	Conflicts           []OccurrenceConflict
+}
+
+// OccurrenceConflict explains why the stay of a series checking in on CheckInDate cannot be booked.
+type OccurrenceConflict struct {
+	CheckInDate string          `json:"checkInDate"`
+	Message     string          `json:"message"`
+	Violations  []RuleViolation `json:"violations,omitempty"`
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type WaitlistStatus string
+
+const (
+	WAITING   WaitlistStatus = "WAITING"
+	NOTIFIED  WaitlistStatus = "NOTIFIED"
+	CONVERTED WaitlistStatus = "CONVERTED"
+)
+
+// WaitlistEntry is a guest waiting for dates of an accommodation that are taken to free up. Entries with
+// AutoSubmit set are turned into SUBMITTED reservation requests as soon as the dates are available.
+type WaitlistEntry struct {
+	ID                   primitive.ObjectID  `bson:"_id" json:"id"`
+	GuestID              uint                `bson:"guestID" json:"guestID"`
+	AccommodationID      uint                `bson:"accommodationID" json:"accommodationID"`
+	StartDate            time.Time           `bson:"startDate" json:"startDate"`
+	EndDate              time.Time           `bson:"endDate" json:"endDate"`
+	TimeZone             string              `bson:"timeZone" json:"timeZone"`
+	GuestNumber          uint                `bson:"guestNumber" json:"guestNumber"`
+	AutoSubmit           bool                `bson:"autoSubmit" json:"autoSubmit"`
+	Status               WaitlistStatus      `bson:"status" json:"status"`
+	CreatedAt            time.Time           `bson:"createdAt" json:"createdAt"`
+	NotifiedAt           *time.Time          `bson:"notifiedAt,omitempty" json:"notifiedAt,omitempty"`
+	ReservationRequestID *primitive.ObjectID `bson:"reservationRequestID,omitempty" json:"reservationRequestID,omitempty"`
+}
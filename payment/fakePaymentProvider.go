@@ -0,0 +1,159 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FakePaymentProvider keeps authorizations in memory and is meant for local development and tests.
+// Authorizations above DeclineAmountAbove are declined and those above PendingAmountAbove stay pending
+// until they are confirmed with Confirm. Zero limits disable the respective behaviour.
+type FakePaymentProvider struct {
+	AuthorizationTTL   time.Duration
+	DeclineAmountAbove float64
+	PendingAmountAbove float64
+
+	mutex          sync.Mutex
+	authorizations map[string]*fakeAuthorization
+}
+
+type fakeAuthorization struct {
+	Authorization
+	captured float64
+	refunded float64
+	voided   bool
+}
+
+func NewFakePaymentProvider(authorizationTTL time.Duration) *FakePaymentProvider {
+	return &FakePaymentProvider{
+		AuthorizationTTL: authorizationTTL,
+		authorizations:   map[string]*fakeAuthorization{},
+	}
+}
+
+func (p *FakePaymentProvider) Authorize(request AuthorizationRequest, ctx context.Context) (*Authorization, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	authorization := Authorization{
+		ID:        primitive.NewObjectID().Hex(),
+		Status:    model.AUTHORIZED,
+		Amount:    request.Amount,
+		ExpiresAt: time.Now().Add(p.AuthorizationTTL),
+	}
+
+	if p.DeclineAmountAbove > 0 && request.Amount > p.DeclineAmountAbove {
+		authorization.Status = model.AUTHORIZATION_FAILED
+		authorization.DeclineReason = "insufficient funds"
+	} else if p.PendingAmountAbove > 0 && request.Amount > p.PendingAmountAbove {
+		authorization.Status = model.AUTHORIZATION_PENDING
+	}
+
+	p.authorizations[authorization.ID] = &fakeAuthorization{Authorization: authorization}
+	slog.InfoContext(ctx, "fake payment provider authorization", "status", authorization.Status, "authorizationID", authorization.ID, "amount", request.Amount, "reference", request.Reference)
+
+	return &authorization, nil
+}
+
+// Confirm completes a pending authorization as if the guest passed the card challenge.
+func (p *FakePaymentProvider) Confirm(authorizationID string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	authorization, found := p.authorizations[authorizationID]
+	if !found {
+		return ErrAuthorizationNotFound
+	}
+
+	if authorization.Status == model.AUTHORIZATION_PENDING {
+		authorization.Status = model.AUTHORIZED
+	}
+
+	return nil
+}
+
+func (p *FakePaymentProvider) Capture(authorizationID string, amount float64, ctx context.Context) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	authorization, err := p.usableAuthorization(authorizationID)
+	if err != nil {
+		return err
+	}
+
+	if authorization.Status == model.AUTHORIZATION_PENDING {
+		return ErrAuthorizationPending
+	}
+
+	if authorization.Status == model.AUTHORIZATION_FAILED {
+		return errors.New("Payment authorization was declined")
+	}
+
+	if authorization.captured+amount > authorization.Amount {
+		return errors.New("Capture amount exceeds authorized amount")
+	}
+
+	authorization.captured += amount
+	return nil
+}
+
+func (p *FakePaymentProvider) Void(authorizationID string, ctx context.Context) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	authorization, found := p.authorizations[authorizationID]
+	if !found {
+		return ErrAuthorizationNotFound
+	}
+
+	if authorization.voided {
+		return ErrAuthorizationVoided
+	}
+
+	if authorization.captured > 0 {
+		return ErrAuthorizationCaptured
+	}
+
+	authorization.voided = true
+	return nil
+}
+
+func (p *FakePaymentProvider) Refund(authorizationID string, amount float64, ctx context.Context) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	authorization, found := p.authorizations[authorizationID]
+	if !found {
+		return ErrAuthorizationNotFound
+	}
+
+	if authorization.refunded+amount > authorization.captured {
+		return errors.New("Refund amount exceeds captured amount")
+	}
+
+	authorization.refunded += amount
+	return nil
+}
+
+func (p *FakePaymentProvider) usableAuthorization(authorizationID string) (*fakeAuthorization, error) {
+	authorization, found := p.authorizations[authorizationID]
+	if !found {
+		return nil, ErrAuthorizationNotFound
+	}
+
+	if authorization.voided {
+		return nil, ErrAuthorizationVoided
+	}
+
+	if authorization.captured == 0 && time.Now().After(authorization.ExpiresAt) {
+		return nil, ErrAuthorizationExpired
+	}
+
+	return authorization, nil
+}
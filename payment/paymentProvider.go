@@ -0,0 +1,44 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+)
+
+var (
+	ErrAuthorizationNotFound = errors.New("Payment authorization does not exist")
+	ErrAuthorizationPending  = errors.New("Payment authorization is still pending")
+	ErrAuthorizationExpired  = errors.New("Payment authorization has expired")
+	ErrAuthorizationVoided   = errors.New("Payment authorization has been voided")
+	ErrAuthorizationCaptured = errors.New("Payment authorization has been captured")
+)
+
+type AuthorizationRequest struct {
+	Reference string
+	GuestID   uint
+	Amount    float64
+}
+
+// Authorization is the result of an authorization attempt. Its Status is AUTHORIZED when the funds are held,
+// AUTHORIZATION_PENDING while the provider waits on the guest (for example for a card challenge) and
+// AUTHORIZATION_FAILED when it was declined. Funds that are not captured until ExpiresAt are released.
+type Authorization struct {
+	ID            string
+	Status        model.PaymentStatus
+	Amount        float64
+	ExpiresAt     time.Time
+	DeclineReason string
+}
+
+// PaymentProvider is implemented by the payment gateways reservations can be paid with.
+type PaymentProvider interface {
+	Authorize(request AuthorizationRequest, ctx context.Context) (*Authorization, error)
+	Capture(authorizationID string, amount float64, ctx context.Context) error
+	// Void releases the funds of an authorization nothing was captured from. It returns ErrAuthorizationVoided
+	// when the authorization was voided already and ErrAuthorizationCaptured when funds were captured from it.
+	Void(authorizationID string, ctx context.Context) error
+	Refund(authorizationID string, amount float64, ctx context.Context) error
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// EnsureIndexes creates the indexes the availability queries rely on. Creating an index that already exists is a no-op.
+func (r *Repository) EnsureIndexes(ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "ensureIndexesRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 10*time.Second)
+	defer cancel()
+
+	_, err := r.Db.Collection("reservation_request").Indexes().CreateOne(dbCtx, mongo.IndexModel{
+		Keys: bson.D{{"accommodationID", 1}, {"status", 1}, {"checkInDate", 1}, {"checkOutDate", 1}},
+	})
+	if err != nil {
+		r.logError(span, err, ctx)
+		return err
+	}
+
+	_, err = r.Db.Collection("blocked_period").Indexes().CreateOne(dbCtx, mongo.IndexModel{
+		Keys: bson.D{{"accommodationID", 1}, {"startDate", 1}, {"endDate", 1}},
+	})
+	if err != nil {
+		r.logError(span, err, ctx)
+		return err
+	}
+
+	return nil
+}
+
+// FindUnavailableAccommodations returns which of the accommodations have a date blocking reservation request or
+// a blocked period holding a local night between the dates from and to. Every accommodation is looked up with the
+// same query, answered from the indexes created by EnsureIndexes.
+func (r *Repository) FindUnavailableAccommodations(accommodationIDs []uint, from time.Time, to time.Time, ctx context.Context) *[]uint {
+	span := tracer.StartSpanFromContext(ctx, "findUnavailableAccommodationsRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 2*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.D{
+			{"accommodationID", bson.D{{"$in", accommodationIDs}}},
+			{"status", bson.D{{"$in", model.DateBlockingStatuses}}},
+			{"checkInDate", bson.D{{"$lt", to.Format(util.DateLayout)}}},
+			{"checkOutDate", bson.D{{"$gt", from.Format(util.DateLayout)}}},
+		}}},
+		{{"$group", bson.D{{"_id", "$accommodationID"}}}},
+	}
+
+	cursor, err := r.Db.Collection("reservation_request").Aggregate(dbCtx, pipeline)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+	defer cursor.Close(dbCtx)
+
+	unavailable := map[uint]bool{}
+	for cursor.Next(dbCtx) {
+		var group struct {
+			AccommodationID uint `bson:"_id"`
+		}
+		err := cursor.Decode(&group)
+		if err != nil {
+			r.logError(span, err, ctx)
+			continue
+		}
+
+		unavailable[group.AccommodationID] = true
+	}
+
+	blockedAccommodationIDs, err := r.Db.Collection("blocked_period").Distinct(dbCtx, "accommodationID", bson.D{
+		{"accommodationID", bson.D{{"$in", accommodationIDs}}},
+		{"startDate", bson.D{{"$lt", to}}},
+		{"endDate", bson.D{{"$gt", from}}},
+	})
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	for _, blockedAccommodationID := range blockedAccommodationIDs {
+		switch id := blockedAccommodationID.(type) {
+		case int32:
+			unavailable[uint(id)] = true
+		case int64:
+			unavailable[uint(id)] = true
+		}
+	}
+
+	accommodations := []uint{}
+	for accommodationID := range unavailable {
+		accommodations = append(accommodations, accommodationID)
+	}
+
+	return &accommodations
+}
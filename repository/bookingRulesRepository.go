@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindBookingRules returns the booking rules of the accommodation, or empty rules when the host did not set any.
+// It returns nil only when the rules could not be loaded.
+func (r *Repository) FindBookingRules(accommodationID uint, ctx context.Context) *model.BookingRules {
+	span := tracer.StartSpanFromContext(ctx, "findBookingRulesRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	bookingRules := model.BookingRules{AccommodationID: accommodationID}
+	err := r.Db.Collection("booking_rules").FindOne(dbCtx, bson.D{{"accommodationID", accommodationID}}).Decode(&bookingRules)
+	if err == mongo.ErrNoDocuments {
+		return &bookingRules
+	}
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	return &bookingRules
+}
+
+func (r *Repository) SaveBookingRules(bookingRules *model.BookingRules, ctx context.Context) *model.BookingRules {
+	span := tracer.StartSpanFromContext(ctx, "saveBookingRulesRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	_, err := r.Db.Collection("booking_rules").ReplaceOne(dbCtx,
+		bson.D{{"accommodationID", bookingRules.AccommodationID}},
+		bookingRules,
+		options.Replace().SetUpsert(true))
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	return bookingRules
+}
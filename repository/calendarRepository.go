@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (r *Repository) SaveCalendarImport(calendarImport *model.CalendarImport, ctx context.Context) *model.CalendarImport {
+	span := tracer.StartSpanFromContext(ctx, "saveCalendarImportRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	calendarImport.ID = primitive.NewObjectID()
+	_, err := r.Db.Collection("calendar_import").InsertOne(dbCtx, &calendarImport)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	return calendarImport
+}
+
+// FindCalendarImports returns the calendar imports of the accommodation, or of all accommodations when accommodationID is 0.
+func (r *Repository) FindCalendarImports(accommodationID uint, ctx context.Context) *[]model.CalendarImport {
+	span := tracer.StartSpanFromContext(ctx, "findCalendarImportsRepository")
+	defer span.End()
+
+	calendarImports := []model.CalendarImport{}
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 3*time.Second)
+	defer cancel()
+
+	filter := bson.D{}
+	if accommodationID != 0 {
+		filter = bson.D{{"accommodationID", accommodationID}}
+	}
+
+	cursor, err := r.Db.Collection("calendar_import").Find(dbCtx, filter)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+	defer cursor.Close(dbCtx)
+
+	for cursor.Next(dbCtx) {
+		var calendarImport model.CalendarImport
+		err := cursor.Decode(&calendarImport)
+		if err != nil {
+			r.logError(span, err, ctx)
+			continue
+		}
+
+		calendarImports = append(calendarImports, calendarImport)
+	}
+
+	return &calendarImports
+}
+
+func (r *Repository) FindCalendarImport(calendarImportID primitive.ObjectID, ctx context.Context) *model.CalendarImport {
+	span := tracer.StartSpanFromContext(ctx, "findCalendarImportRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	var calendarImport model.CalendarImport
+	err := r.Db.Collection("calendar_import").FindOne(dbCtx, bson.D{{"_id", calendarImportID}}).Decode(&calendarImport)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	return &calendarImport
+}
+
+// DeleteCalendarImport deletes the calendar import together with the periods it blocked.
+func (r *Repository) DeleteCalendarImport(calendarImportID primitive.ObjectID, ctx context.Context) bool {
+	span := tracer.StartSpanFromContext(ctx, "deleteCalendarImportRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 3*time.Second)
+	defer cancel()
+
+	_, err := r.Db.Collection("blocked_period").DeleteMany(dbCtx, bson.D{{"calendarImportID", calendarImportID}})
+	if err != nil {
+		r.logError(span, err, ctx)
+		return false
+	}
+
+	one, err := r.Db.Collection("calendar_import").DeleteOne(dbCtx, bson.D{{"_id", calendarImportID}})
+	if err != nil {
+		r.logError(span, err, ctx)
+		return false
+	}
+
+	return one.DeletedCount == 1
+}
+
+func (r *Repository) UpdateCalendarImportSync(calendarImport *model.CalendarImport, ctx context.Context) *model.CalendarImport {
+	span := tracer.StartSpanFromContext(ctx, "updateCalendarImportSyncRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	updateQuery := bson.D{{"$set", bson.D{
+		{"lastSyncedAt", calendarImport.LastSyncedAt},
+		{"lastError", calendarImport.LastError},
+	}}}
+	_, err := r.Db.Collection("calendar_import").UpdateByID(dbCtx, calendarImport.ID, updateQuery)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	return calendarImport
+}
+
+// ReplaceBlockedPeriods replaces the periods blocked by the calendar import with the given ones.
+func (r *Repository) ReplaceBlockedPeriods(calendarImport *model.CalendarImport, blockedPeriods []model.BlockedPeriod, ctx context.Context) bool {
+	span := tracer.StartSpanFromContext(ctx, "replaceBlockedPeriodsRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 5*time.Second)
+	defer cancel()
+
+	_, err := r.Db.Collection("blocked_period").DeleteMany(dbCtx, bson.D{{"calendarImportID", calendarImport.ID}})
+	if err != nil {
+		r.logError(span, err, ctx)
+		return false
+	}
+
+	if len(blockedPeriods) == 0 {
+		return true
+	}
+
+	documents := []interface{}{}
+	for _, blockedPeriod := range blockedPeriods {
+		blockedPeriod.ID = primitive.NewObjectID()
+		blockedPeriod.CalendarImportID = calendarImport.ID
+		blockedPeriod.AccommodationID = calendarImport.AccommodationID
+		documents = append(documents, blockedPeriod)
+	}
+
+	_, err = r.Db.Collection("blocked_period").InsertMany(dbCtx, documents)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return false
+	}
+
+	return true
+}
+
+// FindBlockedPeriods returns the blocked periods of the accommodation overlapping with the period between from and to.
+func (r *Repository) FindBlockedPeriods(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.BlockedPeriod {
+	span := tracer.StartSpanFromContext(ctx, "findBlockedPeriodsRepository")
+	defer span.End()
+
+	blockedPeriods := []model.BlockedPeriod{}
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 3*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{"accommodationID", accommodationID},
+		{"startDate", bson.D{{"$lt", to}}},
+		{"endDate", bson.D{{"$gt", from}}},
+	}
+	cursor, err := r.Db.Collection("blocked_period").Find(dbCtx, filter)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+	defer cursor.Close(dbCtx)
+
+	for cursor.Next(dbCtx) {
+		var blockedPeriod model.BlockedPeriod
+		err := cursor.Decode(&blockedPeriod)
+		if err != nil {
+			r.logError(span, err, ctx)
+			continue
+		}
+
+		blockedPeriods = append(blockedPeriods, blockedPeriod)
+	}
+
+	return &blockedPeriods
+}
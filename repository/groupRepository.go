@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SaveReservationRequests inserts the reservation requests of a group together. Mongo deployments without
+// replica sets have no transactions, so the requests inserted before a failure are deleted again.
+func (r *Repository) SaveReservationRequests(reservationRequests []*model.ReservationRequest, ctx context.Context) bool {
+	span := tracer.StartSpanFromContext(ctx, "saveReservationRequestsRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 3*time.Second)
+	defer cancel()
+
+	ids := bson.A{}
+	documents := []interface{}{}
+	for _, reservationRequest := range reservationRequests {
+		reservationRequest.ID = primitive.NewObjectID()
+		ids = append(ids, reservationRequest.ID)
+		documents = append(documents, reservationRequest)
+	}
+
+	_, err := r.Db.Collection("reservation_request").InsertMany(dbCtx, documents)
+	if err != nil {
+		r.logError(span, err, ctx)
+
+		// the request may have been cancelled already, the cleanup has to run regardless
+		cleanupCtx, cancelCleanup := context.WithTimeout(tracer.ContextWithSpan(context.Background(), span), 3*time.Second)
+		defer cancelCleanup()
+
+		_, err = r.Db.Collection("reservation_request").DeleteMany(cleanupCtx, bson.D{{"_id", bson.D{{"$in", ids}}}})
+		if err != nil {
+			r.logError(span, err, ctx)
+		}
+		return false
+	}
+
+	return true
+}
+
+func (r *Repository) FindGroupReservationRequests(groupID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "findGroupReservationRequestsRepository")
+	defer span.End()
+
+	return r.findGroupedReservationRequests(bson.D{{"groupID", groupID}}, ctx)
+}
+
+func (r *Repository) FindGuestsGroupedReservationRequests(guestID uint, ctx context.Context) *[]model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "findGuestsGroupedReservationRequestsRepository")
+	defer span.End()
+
+	return r.findGroupedReservationRequests(bson.D{
+		{"guestID", guestID},
+		{"groupID", bson.D{{"$exists", true}}},
+	}, ctx)
+}
+
+func (r *Repository) FindOwnersGroupedReservationRequests(ownerID uint, ctx context.Context) *[]model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "findOwnersGroupedReservationRequestsRepository")
+	defer span.End()
+
+	return r.findGroupedReservationRequests(bson.D{
+		{"ownerID", ownerID},
+		{"groupID", bson.D{{"$exists", true}}},
+	}, ctx)
+}
+
+// findGroupedReservationRequests returns the matching reservation requests ordered by group, newest group first.
+func (r *Repository) findGroupedReservationRequests(filter bson.D, ctx context.Context) *[]model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "findGroupedReservationRequestsRepository")
+	defer span.End()
+
+	reservationRequests := []model.ReservationRequest{}
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 3*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{{"groupID", -1}, {"_id", 1}})
+	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter, findOptions)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+	defer cursor.Close(dbCtx)
+
+	for cursor.Next(dbCtx) {
+		var reservationRequest model.ReservationRequest
+		err := cursor.Decode(&reservationRequest)
+		if err != nil {
+			r.logError(span, err, ctx)
+			continue
+		}
+
+		reservationRequests = append(reservationRequests, reservationRequest)
+	}
+
+	return &reservationRequests
+}
@@ -2,13 +2,19 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"log/slog"
 	"time"
 
+	"github.com/windbnb/reservation-service/logging"
 	"github.com/windbnb/reservation-service/model"
 	"github.com/windbnb/reservation-service/tracer"
+	"github.com/windbnb/reservation-service/util"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type IRepository interface {
@@ -26,36 +32,131 @@ type IRepository interface {
 	FindGuestInAccomodation(guestID uint, accomodationID uint, ctx context.Context) bool
 	FindGuestsAllReservations(guestID uint, ctx context.Context) *[]model.ReservationRequest
 	FindOwnersReservations(ownerID uint, ctx context.Context, status []model.ReservationRequestStatus) *[]model.ReservationRequest
+	UpdateReservationRequestRefund(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest
+	SaveEvent(event *model.Event, ctx context.Context) *model.Event
+	FindUnpublishedEvents(limit int64, ctx context.Context) *[]model.Event
+	MarkEventPublished(event *model.Event, ctx context.Context) bool
+	RecordEventAttempt(event *model.Event, ctx context.Context) bool
+	ParkEvent(event *model.Event, ctx context.Context) bool
+	UpdateReservationRequestPayment(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest
+	FindReservationRequestsByStatus(status model.ReservationRequestStatus, ctx context.Context) *[]model.ReservationRequest
+	FindReservationRequests(filter model.ReservationRequestFilter, ctx context.Context) *[]model.ReservationRequest
+	FindExpiredSubmittedReservationRequests(submittedBefore time.Time, startingBefore time.Time, ctx context.Context) *[]model.ReservationRequest
+	ExpireReservationRequest(reservationRequest *model.ReservationRequest, ctx context.Context) bool
+	TransitionReservationRequestStatus(reservationRequest *model.ReservationRequest, from model.ReservationRequestStatus, to model.ReservationRequestStatus, ctx context.Context) bool
+	UpdateReservationRequestStay(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest
+	CompleteFinishedStays(endedBefore time.Time, ctx context.Context) int
+	FindCompletedStays(eligibilityRequests []model.RatingEligibilityRequest, ctx context.Context) *[]model.ReservationRequest
+	UpdateReservationRequestReview(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest
+	FindReservationRequestsInPeriod(accommodationID uint, from time.Time, to time.Time, statuses []model.ReservationRequestStatus, ctx context.Context) *[]model.ReservationRequest
+	SaveCalendarImport(calendarImport *model.CalendarImport, ctx context.Context) *model.CalendarImport
+	FindCalendarImports(accommodationID uint, ctx context.Context) *[]model.CalendarImport
+	FindCalendarImport(calendarImportID primitive.ObjectID, ctx context.Context) *model.CalendarImport
+	DeleteCalendarImport(calendarImportID primitive.ObjectID, ctx context.Context) bool
+	UpdateCalendarImportSync(calendarImport *model.CalendarImport, ctx context.Context) *model.CalendarImport
+	ReplaceBlockedPeriods(calendarImport *model.CalendarImport, blockedPeriods []model.BlockedPeriod, ctx context.Context) bool
+	FindBlockedPeriods(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.BlockedPeriod
+	FindUnavailableAccommodations(accommodationIDs []uint, from time.Time, to time.Time, ctx context.Context) *[]uint
+	SaveWaitlistEntry(waitlistEntry *model.WaitlistEntry, ctx context.Context) *model.WaitlistEntry
+	FindWaitlistEntry(waitlistEntryID primitive.ObjectID, ctx context.Context) *model.WaitlistEntry
+	FindGuestsWaitlistEntries(guestID uint, ctx context.Context) *[]model.WaitlistEntry
+	FindWaitingWaitlistEntries(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.WaitlistEntry
+	UpdateWaitlistEntry(waitlistEntry *model.WaitlistEntry, ctx context.Context) *model.WaitlistEntry
+	DeleteWaitlistEntry(waitlistEntryID primitive.ObjectID, ctx context.Context) bool
+	FindBookingRules(accommodationID uint, ctx context.Context) *model.BookingRules
+	SaveBookingRules(bookingRules *model.BookingRules, ctx context.Context) *model.BookingRules
+	SaveReservationRequests(reservationRequests []*model.ReservationRequest, ctx context.Context) bool
+	FindGroupReservationRequests(groupID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest
+	FindGuestsGroupedReservationRequests(guestID uint, ctx context.Context) *[]model.ReservationRequest
+	FindOwnersGroupedReservationRequests(ownerID uint, ctx context.Context) *[]model.ReservationRequest
+	FindSeriesReservationRequests(seriesID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest
+	FindCompetingReservationRequests(reservationRequest *model.ReservationRequest, ctx context.Context) *[]model.ReservationRequest
+	CountSubmittedReservationRequestsByOwner(ctx context.Context) *map[uint]int
+	DeleteSeededReservationRequests(seedRun string, ctx context.Context) (int, error)
 }
 
 type Repository struct {
-	Db *mongo.Database
+	Db     *mongo.Database
+	Logger *slog.Logger
 }
 
+// logError records the failed database operation on its span and in the log. Finding no document is only
+// logged at debug level, the callers answer it as a missing entity.
+func (r *Repository) logError(span trace.Span, err error, ctx context.Context) {
+	tracer.LogError(span, err)
+
+	level := slog.LevelError
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		level = slog.LevelDebug
+	}
+	logging.OrDefault(r.Logger).Log(tracer.ContextWithSpan(ctx, span), level, "database operation failed", "error", err)
+}
+
+// FindAcceptedReservationRequests returns the reservation requests of the accommodation that hold their dates,
+// which are the accepted ones and the ones waiting for the payment to be authorized.
 func (r *Repository) FindAcceptedReservationRequests(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "findReservationRequestsRepository")
-	defer span.Finish()
+	defer span.End()
 
 	reservationRequests := []model.ReservationRequest{}
-	dbCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 3*time.Second)
 	defer cancel()
 
 	filter := bson.D{
 		{"accommodationID", accomodationId},
-		{"status", model.ACCEPTED},
+		{"status", bson.D{{"$in", model.DateBlockingStatuses}}},
 	}
 
 	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter)
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	for cursor.Next(dbCtx) {
+		var reservationRequest model.ReservationRequest
+		err := cursor.Decode(&reservationRequest)
+		if err != nil {
+			r.logError(span, err, ctx)
+			continue
+		}
+
+		reservationRequests = append(reservationRequests, reservationRequest)
+	}
+
+	return &reservationRequests
+}
+
+// FindReservationRequestsInPeriod returns the reservation requests of the accommodation with one of the given
+// statuses that hold a local night between the dates from and to.
+func (r *Repository) FindReservationRequestsInPeriod(accommodationID uint, from time.Time, to time.Time, statuses []model.ReservationRequestStatus, ctx context.Context) *[]model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "findReservationRequestsInPeriodRepository")
+	defer span.End()
+
+	reservationRequests := []model.ReservationRequest{}
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 3*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{"accommodationID", accommodationID},
+		{"status", bson.D{{"$in", statuses}}},
+		{"checkInDate", bson.D{{"$lt", to.Format(util.DateLayout)}}},
+		{"checkOutDate", bson.D{{"$gt", from.Format(util.DateLayout)}}},
+	}
+	findOptions := options.Find().SetSort(bson.D{{"_id", 1}})
+
+	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter, findOptions)
+	if err != nil {
+		r.logError(span, err, ctx)
 		return nil
 	}
+	defer cursor.Close(dbCtx)
 
 	for cursor.Next(dbCtx) {
 		var reservationRequest model.ReservationRequest
 		err := cursor.Decode(&reservationRequest)
 		if err != nil {
-			tracer.LogError(span, err)
+			r.logError(span, err, ctx)
 			continue
 		}
 
@@ -67,15 +168,15 @@ func (r *Repository) FindAcceptedReservationRequests(accomodationId uint, ctx co
 
 func (r *Repository) SaveReservationRequest(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "saveReservationRequestRepository")
-	defer span.Finish()
+	defer span.End()
 
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	reservationRequest.ID = primitive.NewObjectID()
 	_, err := r.Db.Collection("reservation_request").InsertOne(dbCtx, &reservationRequest)
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
 		return nil
 	}
 
@@ -84,21 +185,21 @@ func (r *Repository) SaveReservationRequest(reservationRequest *model.Reservatio
 
 func (r *Repository) FindGuestsActive(guestID uint, ctx context.Context) *[]model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "findGuestsActiveRepository")
-	defer span.Finish()
+	defer span.End()
 
 	reservationRequests := []model.ReservationRequest{}
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	filter := bson.D{
 		{"guestID", guestID},
-		{"status", model.ACCEPTED},
+		{"status", bson.D{{"$in", model.ActiveStatuses}}},
 		{"endDate", bson.D{{"$gte", time.Now()}}},
 	}
 	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter)
 
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
 		return nil
 	}
 	defer cursor.Close(dbCtx)
@@ -107,7 +208,7 @@ func (r *Repository) FindGuestsActive(guestID uint, ctx context.Context) *[]mode
 		var reservationRequest model.ReservationRequest
 		err := cursor.Decode(&reservationRequest)
 		if err != nil {
-			tracer.LogError(span, err)
+			r.logError(span, err, ctx)
 			continue
 		}
 
@@ -119,27 +220,26 @@ func (r *Repository) FindGuestsActive(guestID uint, ctx context.Context) *[]mode
 
 func (r *Repository) FindGuestWithHost(guestID uint, ownerID uint, ctx context.Context) bool {
 	span := tracer.StartSpanFromContext(ctx, "findGuestWithHostRepository")
-	defer span.Finish()
+	defer span.End()
 
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	filter := bson.D{
 		{"guestID", guestID},
 		{"ownerID", ownerID},
-		{"status", model.ACCEPTED},
-		{"endDate", bson.D{{"$lt", time.Now()}}},
+		{"status", model.COMPLETED},
 	}
 	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter)
 
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
 		return false
 	}
 	defer cursor.Close(dbCtx)
 
 	for cursor.Next(dbCtx) {
-		return true;
+		return true
 	}
 
 	return false
@@ -147,49 +247,100 @@ func (r *Repository) FindGuestWithHost(guestID uint, ownerID uint, ctx context.C
 
 func (r *Repository) FindGuestInAccomodation(guestID uint, accomodationID uint, ctx context.Context) bool {
 	span := tracer.StartSpanFromContext(ctx, "findGuestInAccomodationRepository")
-	defer span.Finish()
+	defer span.End()
 
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	filter := bson.D{
 		{"guestID", guestID},
 		{"accommodationID", accomodationID},
-		{"status", model.ACCEPTED},
-		{"endDate", bson.D{{"$lt", time.Now()}}},
+		{"status", model.COMPLETED},
 	}
 	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter)
 
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
 		return false
 	}
 	defer cursor.Close(dbCtx)
 
 	for cursor.Next(dbCtx) {
-		return true;
+		return true
 	}
 
 	return false
 }
 
+// FindCompletedStays returns, in a single query, the completed stays of every guest with the given host,
+// or in the given accommodation when the request names one.
+func (r *Repository) FindCompletedStays(eligibilityRequests []model.RatingEligibilityRequest, ctx context.Context) *[]model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "findCompletedStaysRepository")
+	defer span.End()
+
+	reservationRequests := []model.ReservationRequest{}
+	if len(eligibilityRequests) == 0 {
+		return &reservationRequests
+	}
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 3*time.Second)
+	defer cancel()
+
+	pairs := bson.A{}
+	for _, eligibilityRequest := range eligibilityRequests {
+		pair := bson.D{{"guestID", eligibilityRequest.GuestID}}
+		if eligibilityRequest.AccommodationID != 0 {
+			pair = append(pair, bson.E{"accommodationID", eligibilityRequest.AccommodationID})
+		}
+		if eligibilityRequest.HostID != 0 || eligibilityRequest.AccommodationID == 0 {
+			pair = append(pair, bson.E{"ownerID", eligibilityRequest.HostID})
+		}
+		pairs = append(pairs, pair)
+	}
+
+	filter := bson.D{
+		{"status", model.COMPLETED},
+		{"$or", pairs},
+	}
+	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter)
+
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+	defer cursor.Close(dbCtx)
+
+	for cursor.Next(dbCtx) {
+		var reservationRequest model.ReservationRequest
+		err := cursor.Decode(&reservationRequest)
+		if err != nil {
+			r.logError(span, err, ctx)
+			continue
+		}
+
+		reservationRequests = append(reservationRequests, reservationRequest)
+	}
+
+	return &reservationRequests
+}
+
 func (r *Repository) FindOwnersActive(ownerID uint, ctx context.Context) *[]model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "findOwnersActiveRepository")
-	defer span.Finish()
+	defer span.End()
 
 	reservationRequests := []model.ReservationRequest{}
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	filter := bson.D{
 		{"ownerID", ownerID},
-		{"status", model.ACCEPTED},
+		{"status", bson.D{{"$in", model.ActiveStatuses}}},
 		{"endDate", bson.D{{"$gte", time.Now()}}},
 	}
 	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter)
 
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
 		return nil
 	}
 	defer cursor.Close(dbCtx)
@@ -209,27 +360,26 @@ func (r *Repository) FindOwnersActive(ownerID uint, ctx context.Context) *[]mode
 
 func (r *Repository) FindGuestsActivePast(guestID uint, ownerID uint, ctx context.Context) bool {
 	span := tracer.StartSpanFromContext(ctx, "findGuestsActivePastRepository")
-	defer span.Finish()
+	defer span.End()
 
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	filter := bson.D{
 		{"guestID", guestID},
 		{"ownerID", ownerID},
-		{"status", model.ACCEPTED},
-		{"endDate", bson.D{{"$lt", time.Now()}}},
+		{"status", model.COMPLETED},
 	}
 	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter)
 
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
 		return false
 	}
 	defer cursor.Close(dbCtx)
 
 	for cursor.Next(dbCtx) {
-		return true;
+		return true
 	}
 
 	return false
@@ -237,10 +387,10 @@ func (r *Repository) FindGuestsActivePast(guestID uint, ownerID uint, ctx contex
 
 func (r *Repository) FindGuestsAllReservations(guestID uint, ctx context.Context) *[]model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "findGuestsAllRepository")
-	defer span.Finish()
+	defer span.End()
 
 	reservationRequests := []model.ReservationRequest{}
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	filter := bson.D{
@@ -249,7 +399,7 @@ func (r *Repository) FindGuestsAllReservations(guestID uint, ctx context.Context
 	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter)
 
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
 		return nil
 	}
 	defer cursor.Close(dbCtx)
@@ -258,7 +408,7 @@ func (r *Repository) FindGuestsAllReservations(guestID uint, ctx context.Context
 		var reservationRequest model.ReservationRequest
 		err := cursor.Decode(&reservationRequest)
 		if err != nil {
-			tracer.LogError(span, err)
+			r.logError(span, err, ctx)
 			continue
 		}
 
@@ -270,10 +420,10 @@ func (r *Repository) FindGuestsAllReservations(guestID uint, ctx context.Context
 
 func (r *Repository) FindOwnersSubmitted(ownerID uint, ctx context.Context) *[]model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "findOwnersSubmittedRepository")
-	defer span.Finish()
+	defer span.End()
 
 	reservationRequests := []model.ReservationRequest{}
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	filter := bson.D{
@@ -283,7 +433,136 @@ func (r *Repository) FindOwnersSubmitted(ownerID uint, ctx context.Context) *[]m
 	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter)
 
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
+		return nil
+	}
+	defer cursor.Close(dbCtx)
+
+	for cursor.Next(dbCtx) {
+		var reservationRequest model.ReservationRequest
+		err := cursor.Decode(&reservationRequest)
+		if err != nil {
+			continue
+		}
+
+		reservationRequests = append(reservationRequests, reservationRequest)
+	}
+
+	return &reservationRequests
+}
+
+func (r *Repository) FindReservationRequestsByStatus(status model.ReservationRequestStatus, ctx context.Context) *[]model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "findReservationRequestsByStatusRepository")
+	defer span.End()
+
+	reservationRequests := []model.ReservationRequest{}
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 3*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{"status", status},
+	}
+	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter)
+
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+	defer cursor.Close(dbCtx)
+
+	for cursor.Next(dbCtx) {
+		var reservationRequest model.ReservationRequest
+		err := cursor.Decode(&reservationRequest)
+		if err != nil {
+			r.logError(span, err, ctx)
+			continue
+		}
+
+		reservationRequests = append(reservationRequests, reservationRequest)
+	}
+
+	return &reservationRequests
+}
+
+// FindReservationRequests returns the reservation requests matching the filter ordered by the start of the stay.
+// It reads whole collections for the operational commands, so it is given more time than the other queries.
+func (r *Repository) FindReservationRequests(filter model.ReservationRequestFilter, ctx context.Context) *[]model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "findReservationRequestsRepository")
+	defer span.End()
+
+	reservationRequests := []model.ReservationRequest{}
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), time.Minute)
+	defer cancel()
+
+	query := bson.D{}
+	if len(filter.Statuses) > 0 {
+		query = append(query, bson.E{"status", bson.D{{"$in", filter.Statuses}}})
+	}
+	if filter.AccommodationID != 0 {
+		query = append(query, bson.E{"accommodationID", filter.AccommodationID})
+	}
+	if filter.GuestID != 0 {
+		query = append(query, bson.E{"guestID", filter.GuestID})
+	}
+	if filter.OwnerID != 0 {
+		query = append(query, bson.E{"ownerID", filter.OwnerID})
+	}
+	// local dates are stored as YYYY-MM-DD, so they compare as strings
+	checkInDate := bson.D{}
+	if filter.CheckInFrom != "" {
+		checkInDate = append(checkInDate, bson.E{"$gte", filter.CheckInFrom})
+	}
+	if filter.CheckInTo != "" {
+		checkInDate = append(checkInDate, bson.E{"$lt", filter.CheckInTo})
+	}
+	if len(checkInDate) > 0 {
+		query = append(query, bson.E{"checkInDate", checkInDate})
+	}
+
+	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, query, options.Find().SetSort(bson.D{{"startDate", 1}, {"_id", 1}}))
+
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+	defer cursor.Close(dbCtx)
+
+	for cursor.Next(dbCtx) {
+		var reservationRequest model.ReservationRequest
+		err := cursor.Decode(&reservationRequest)
+		if err != nil {
+			r.logError(span, err, ctx)
+			continue
+		}
+
+		reservationRequests = append(reservationRequests, reservationRequest)
+	}
+
+	return &reservationRequests
+}
+
+// FindExpiredSubmittedReservationRequests returns the SUBMITTED reservation requests that were created before
+// submittedBefore or whose stay starts before startingBefore. The creation time is read from the object ID,
+// so requests stored before they had a creation date are covered too.
+func (r *Repository) FindExpiredSubmittedReservationRequests(submittedBefore time.Time, startingBefore time.Time, ctx context.Context) *[]model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "findExpiredSubmittedReservationRequestsRepository")
+	defer span.End()
+
+	reservationRequests := []model.ReservationRequest{}
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 3*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{"status", model.SUBMITTED},
+		{"$or", bson.A{
+			bson.D{{"_id", bson.D{{"$lt", primitive.NewObjectIDFromTimestamp(submittedBefore)}}}},
+			bson.D{{"startDate", bson.D{{"$lt", startingBefore}}}},
+		}},
+	}
+	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter)
+
+	if err != nil {
+		r.logError(span, err, ctx)
 		return nil
 	}
 	defer cursor.Close(dbCtx)
@@ -292,6 +571,7 @@ func (r *Repository) FindOwnersSubmitted(ownerID uint, ctx context.Context) *[]m
 		var reservationRequest model.ReservationRequest
 		err := cursor.Decode(&reservationRequest)
 		if err != nil {
+			r.logError(span, err, ctx)
 			continue
 		}
 
@@ -303,10 +583,10 @@ func (r *Repository) FindOwnersSubmitted(ownerID uint, ctx context.Context) *[]m
 
 func (r *Repository) FindOwnersReservations(ownerID uint, ctx context.Context, status []model.ReservationRequestStatus) *[]model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "findOwnersSubmittedRepository")
-	defer span.Finish()
+	defer span.End()
 
 	reservationRequests := []model.ReservationRequest{}
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	filter := bson.D{
@@ -316,7 +596,7 @@ func (r *Repository) FindOwnersReservations(ownerID uint, ctx context.Context, s
 	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter)
 
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
 		return nil
 	}
 	defer cursor.Close(dbCtx)
@@ -336,9 +616,9 @@ func (r *Repository) FindOwnersReservations(ownerID uint, ctx context.Context, s
 
 func (r *Repository) DeleteReservationRequest(reservationRequestID primitive.ObjectID, ctx context.Context) bool {
 	span := tracer.StartSpanFromContext(ctx, "saveAccomodationRepository")
-	defer span.Finish()
+	defer span.End()
 
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	filter := bson.D{
@@ -347,7 +627,7 @@ func (r *Repository) DeleteReservationRequest(reservationRequestID primitive.Obj
 
 	one, err := r.Db.Collection("reservation_request").DeleteOne(dbCtx, filter)
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
 		return false
 	}
 
@@ -356,9 +636,9 @@ func (r *Repository) DeleteReservationRequest(reservationRequestID primitive.Obj
 
 func (r *Repository) FindReservationRequest(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "findReservationRequestRepository")
-	defer span.Finish()
+	defer span.End()
 
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	filter := bson.D{
@@ -368,7 +648,7 @@ func (r *Repository) FindReservationRequest(reservationRequestID primitive.Objec
 	var reservationRequest model.ReservationRequest
 	err := r.Db.Collection("reservation_request").FindOne(dbCtx, filter).Decode(&reservationRequest)
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
 		return nil
 	}
 
@@ -377,64 +657,65 @@ func (r *Repository) FindReservationRequest(reservationRequestID primitive.Objec
 
 func (r *Repository) AcceptReservationRequest(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "acceptReservationRequestRepository")
-	defer span.Finish()
+	defer span.End()
+
+	updateQuery := bson.D{{"$set", bson.D{{"status", model.ACCEPTED}}}}
+	err := r.updateReservationRequest(reservationRequest, updateQuery, ctx)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
 
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	return reservationRequest
+}
+
+// FindCompetingReservationRequests returns the SUBMITTED reservation requests competing for any of the local nights
+// of the reservation request.
+func (r *Repository) FindCompetingReservationRequests(reservationRequest *model.ReservationRequest, ctx context.Context) *[]model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "findCompetingReservationRequestsRepository")
+	defer span.End()
+
+	reservationRequests := []model.ReservationRequest{}
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	filter := bson.D{
-		{
-			"$and",
-			bson.A{
-				bson.D{
-					{
-						"$or",
-						bson.A{
-							bson.D{
-								{"$and", bson.A{
-									bson.D{{"startDate", bson.D{{"$gte", reservationRequest.StartDate}}}},
-									bson.D{{"startDate", bson.D{{"$lte", reservationRequest.EndDate}}}},
-								}},
-							},
-							bson.D{
-								{"$and", bson.A{
-									bson.D{{"endDate", bson.D{{"$gte", reservationRequest.StartDate}}}},
-									bson.D{{"endDate", bson.D{{"$lte", reservationRequest.EndDate}}}},
-								}},
-							},
-						},
-					},
-				},
-				bson.D{
-					{
-						"status", model.SUBMITTED,
-					},
-				},
-			},
-		},
-	}
-
-	declinedReservationRequest := bson.D{{"$set", bson.D{{"status", model.DECLINED}}}}
-	acceptedReservationRequest := bson.D{{"$set", bson.D{{"status", model.ACCEPTED}}}}
-
-	_, err := r.Db.Collection("reservation_request").UpdateMany(dbCtx, filter, declinedReservationRequest)
-	_, err = r.Db.Collection("reservation_request").UpdateByID(dbCtx, reservationRequest.ID, acceptedReservationRequest)
-	if err != nil {
-		tracer.LogError(span, err)
+		{"_id", bson.D{{"$ne", reservationRequest.ID}}},
+		{"accommodationID", reservationRequest.AccommodationID},
+		{"status", model.SUBMITTED},
+		{"checkInDate", bson.D{{"$lt", reservationRequest.CheckOutDate}}},
+		{"checkOutDate", bson.D{{"$gt", reservationRequest.CheckInDate}}},
+	}
+	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, filter)
+
+	if err != nil {
+		r.logError(span, err, ctx)
 		return nil
 	}
+	defer cursor.Close(dbCtx)
 
-	return reservationRequest
+	for cursor.Next(dbCtx) {
+		var reservationRequest model.ReservationRequest
+		err := cursor.Decode(&reservationRequest)
+		if err != nil {
+			r.logError(span, err, ctx)
+			continue
+		}
+
+		reservationRequests = append(reservationRequests, reservationRequest)
+	}
+
+	return &reservationRequests
 }
 
 func (r *Repository) UpdateReservationRequestReservedTerm(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "updateReservationRequestReservedTermRepository")
-	defer span.Finish()
+	defer span.End()
 
 	updateQuery := bson.D{{"$set", bson.D{{"reservedTermId", reservationRequest.ReservedTermId}}}}
 	err := r.updateReservationRequest(reservationRequest, updateQuery, ctx)
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
 		return nil
 	}
 
@@ -443,12 +724,183 @@ func (r *Repository) UpdateReservationRequestReservedTerm(reservationRequest *mo
 
 func (r *Repository) UpdateReservationRequestStatus(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "updateReservationRequestStatusRepository")
-	defer span.Finish()
+	defer span.End()
+
+	updateQuery := bson.D{{"$set", bson.D{{"status", reservationRequest.Status}}}}
+	err := r.updateReservationRequest(reservationRequest, updateQuery, ctx)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	return reservationRequest
+}
+
+// ExpireReservationRequest moves the reservation request to EXPIRED only if it is still SUBMITTED, so a host
+// accepting it at the same time wins over the expiry.
+func (r *Repository) ExpireReservationRequest(reservationRequest *model.ReservationRequest, ctx context.Context) bool {
+	span := tracer.StartSpanFromContext(ctx, "expireReservationRequestRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{"_id", reservationRequest.ID},
+		{"status", model.SUBMITTED},
+	}
+	updateQuery := bson.D{{"$set", bson.D{{"status", model.EXPIRED}}}}
+
+	result, err := r.Db.Collection("reservation_request").UpdateOne(dbCtx, filter, updateQuery)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return false
+	}
+
+	if result.ModifiedCount == 1 {
+		reservationRequest.Status = model.EXPIRED
+	}
+
+	return result.ModifiedCount == 1
+}
+
+// TransitionReservationRequestStatus changes the status of the reservation request to to only while it is still
+// from, and reports whether it did, so a change made in the meantime by another request is not overwritten.
+func (r *Repository) TransitionReservationRequestStatus(reservationRequest *model.ReservationRequest, from model.ReservationRequestStatus, to model.ReservationRequestStatus, ctx context.Context) bool {
+	span := tracer.StartSpanFromContext(ctx, "transitionReservationRequestStatusRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{"_id", reservationRequest.ID},
+		{"status", from},
+	}
+	updateQuery := bson.D{{"$set", bson.D{{"status", to}}}}
+
+	result, err := r.Db.Collection("reservation_request").UpdateOne(dbCtx, filter, updateQuery)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return false
+	}
+
+	if result.ModifiedCount == 1 {
+		reservationRequest.Status = to
+	}
+
+	return result.ModifiedCount == 1
+}
+
+func (r *Repository) UpdateReservationRequestStay(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "updateReservationRequestStayRepository")
+	defer span.End()
+
+	updateQuery := bson.D{{"$set", bson.D{
+		{"status", reservationRequest.Status},
+		{"checkedInAt", reservationRequest.CheckedInAt},
+		{"checkedOutAt", reservationRequest.CheckedOutAt},
+	}}}
+	err := r.updateReservationRequest(reservationRequest, updateQuery, ctx)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	return reservationRequest
+}
+
+// CompleteFinishedStays completes the accepted and checked in stays that ended before endedBefore and returns how many
+// were completed. Checked in stays are checked out at their end date; accepted stays the host never checked the guest
+// in to are completed without either time.
+func (r *Repository) CompleteFinishedStays(endedBefore time.Time, ctx context.Context) int {
+	span := tracer.StartSpanFromContext(ctx, "completeFinishedStaysRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 5*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{"status", bson.D{{"$in", model.ActiveStatuses}}},
+		{"endDate", bson.D{{"$lt", endedBefore}}},
+	}
+	// expressions see the stay as it was before the update, so $status is still ACCEPTED or CHECKED_IN
+	updateQuery := mongo.Pipeline{{{"$set", bson.D{
+		{"status", model.COMPLETED},
+		{"checkedOutAt", bson.D{{"$cond", bson.A{bson.D{{"$eq", bson.A{"$status", model.CHECKED_IN}}}, "$endDate", "$$REMOVE"}}}},
+	}}}}
+
+	result, err := r.Db.Collection("reservation_request").UpdateMany(dbCtx, filter, updateQuery)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return 0
+	}
+
+	return int(result.ModifiedCount)
+}
+
+// DeleteSeededReservationRequests removes the reservation requests generated by the seed run, or by any seed run
+// when seedRun is empty, and returns how many were removed.
+func (r *Repository) DeleteSeededReservationRequests(seedRun string, ctx context.Context) (int, error) {
+	span := tracer.StartSpanFromContext(ctx, "deleteSeededReservationRequestsRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 30*time.Second)
+	defer cancel()
+
+	filter := bson.D{{"seedRun", bson.D{{"$exists", true}, {"$ne", ""}}}}
+	if seedRun != "" {
+		filter = bson.D{{"seedRun", seedRun}}
+	}
+
+	result, err := r.Db.Collection("reservation_request").DeleteMany(dbCtx, filter)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return 0, err
+	}
+
+	return int(result.DeletedCount), nil
+}
+
+func (r *Repository) UpdateReservationRequestReview(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "updateReservationRequestReviewRepository")
+	defer span.End()
+
+	updateQuery := bson.D{{"$set", bson.D{
+		{"hostReviewedAt", reservationRequest.HostReviewedAt},
+		{"accommodationReviewedAt", reservationRequest.AccommodationReviewedAt},
+	}}}
+	err := r.updateReservationRequest(reservationRequest, updateQuery, ctx)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	return reservationRequest
+}
+
+func (r *Repository) UpdateReservationRequestPayment(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "updateReservationRequestPaymentRepository")
+	defer span.End()
 
-	updateQuery := bson.D{{"$set", bson.D{{"status", model.CANCELLED}}}}
+	updateQuery := bson.D{{"$set", bson.D{{"payment", reservationRequest.Payment}}}}
 	err := r.updateReservationRequest(reservationRequest, updateQuery, ctx)
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	return reservationRequest
+}
+
+func (r *Repository) UpdateReservationRequestRefund(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "updateReservationRequestRefundRepository")
+	defer span.End()
+
+	updateQuery := bson.D{{"$set", bson.D{{"refund", reservationRequest.Refund}}}}
+	err := r.updateReservationRequest(reservationRequest, updateQuery, ctx)
+	if err != nil {
+		r.logError(span, err, ctx)
 		return nil
 	}
 
@@ -457,20 +909,58 @@ func (r *Repository) UpdateReservationRequestStatus(reservationRequest *model.Re
 
 func (r *Repository) updateReservationRequest(reservationRequest *model.ReservationRequest, updateQuery bson.D, ctx context.Context) error {
 	span := tracer.StartSpanFromContext(ctx, "updateReservationRequestRepository")
-	defer span.Finish()
+	defer span.End()
 
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	_, err := r.Db.Collection("reservation_request").UpdateByID(dbCtx, reservationRequest.ID, updateQuery)
 	return err
 }
 
+// CountSubmittedReservationRequestsByOwner returns how many SUBMITTED reservation requests every host has
+// to respond to. Hosts without any are left out.
+func (r *Repository) CountSubmittedReservationRequestsByOwner(ctx context.Context) *map[uint]int {
+	span := tracer.StartSpanFromContext(ctx, "countSubmittedReservationRequestsByOwnerRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 5*time.Second)
+	defer cancel()
+
+	pipeline := mongo.Pipeline{
+		{{"$match", bson.D{{"status", model.SUBMITTED}}}},
+		{{"$group", bson.D{{"_id", "$ownerID"}, {"count", bson.D{{"$sum", 1}}}}}},
+	}
+
+	cursor, err := r.Db.Collection("reservation_request").Aggregate(dbCtx, pipeline)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+	defer cursor.Close(dbCtx)
+
+	counts := map[uint]int{}
+	for cursor.Next(dbCtx) {
+		var ownerCount struct {
+			OwnerID uint `bson:"_id"`
+			Count   int  `bson:"count"`
+		}
+		if err := cursor.Decode(&ownerCount); err != nil {
+			r.logError(span, err, ctx)
+			continue
+		}
+
+		counts[ownerCount.OwnerID] = ownerCount.Count
+	}
+
+	return &counts
+}
+
 func (r *Repository) CountGuestsCancelled(guestId uint, ctx context.Context) int {
 	span := tracer.StartSpanFromContext(ctx, "saveAccomodationRepository")
-	defer span.Finish()
+	defer span.End()
 
-	dbCtx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
 	defer cancel()
 
 	filter := bson.D{
@@ -480,9 +970,127 @@ func (r *Repository) CountGuestsCancelled(guestId uint, ctx context.Context) int
 	count, err := r.Db.Collection("reservation_request").CountDocuments(dbCtx, filter)
 
 	if err != nil {
-		tracer.LogError(span, err)
+		r.logError(span, err, ctx)
 		return 0
 	}
 
 	return int(count)
 }
+
+func (r *Repository) SaveEvent(event *model.Event, ctx context.Context) *model.Event {
+	span := tracer.StartSpanFromContext(ctx, "saveEventRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	event.ID = primitive.NewObjectID()
+	_, err := r.Db.Collection("outbox_event").InsertOne(dbCtx, &event)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	return event
+}
+
+func (r *Repository) FindUnpublishedEvents(limit int64, ctx context.Context) *[]model.Event {
+	span := tracer.StartSpanFromContext(ctx, "findUnpublishedEventsRepository")
+	defer span.End()
+
+	events := []model.Event{}
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 3*time.Second)
+	defer cancel()
+
+	filter := bson.D{
+		{"publishedAt", nil},
+		{"parkedAt", nil},
+	}
+	findOptions := options.Find().SetSort(bson.D{{"_id", 1}}).SetLimit(limit)
+
+	cursor, err := r.Db.Collection("outbox_event").Find(dbCtx, filter, findOptions)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+	defer cursor.Close(dbCtx)
+
+	for cursor.Next(dbCtx) {
+		var event model.Event
+		err := cursor.Decode(&event)
+		if err != nil {
+			r.logError(span, err, ctx)
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	return &events
+}
+
+func (r *Repository) MarkEventPublished(event *model.Event, ctx context.Context) bool {
+	span := tracer.StartSpanFromContext(ctx, "markEventPublishedRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	publishedAt := time.Now()
+	updateQuery := bson.D{{"$set", bson.D{{"publishedAt", publishedAt}}}}
+	result, err := r.Db.Collection("outbox_event").UpdateByID(dbCtx, event.ID, updateQuery)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return false
+	}
+
+	event.PublishedAt = &publishedAt
+	return result.ModifiedCount == 1
+}
+
+// RecordEventAttempt stores the failed dispatch of the event: its attempts, last error and the handlers that handled it.
+func (r *Repository) RecordEventAttempt(event *model.Event, ctx context.Context) bool {
+	span := tracer.StartSpanFromContext(ctx, "recordEventAttemptRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	updateQuery := bson.D{{"$set", bson.D{
+		{"attempts", event.Attempts},
+		{"lastError", event.LastError},
+		{"handledBy", event.HandledBy},
+	}}}
+	result, err := r.Db.Collection("outbox_event").UpdateByID(dbCtx, event.ID, updateQuery)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return false
+	}
+
+	return result.ModifiedCount == 1
+}
+
+// ParkEvent stores the last failed dispatch of the event and excludes it from FindUnpublishedEvents.
+func (r *Repository) ParkEvent(event *model.Event, ctx context.Context) bool {
+	span := tracer.StartSpanFromContext(ctx, "parkEventRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	parkedAt := time.Now()
+	updateQuery := bson.D{{"$set", bson.D{
+		{"attempts", event.Attempts},
+		{"lastError", event.LastError},
+		{"handledBy", event.HandledBy},
+		{"parkedAt", parkedAt},
+	}}}
+	result, err := r.Db.Collection("outbox_event").UpdateByID(dbCtx, event.ID, updateQuery)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return false
+	}
+
+	event.ParkedAt = &parkedAt
+	return result.ModifiedCount == 1
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// FindSeriesReservationRequests returns the reservation requests of the series in the order of their stays.
+func (r *Repository) FindSeriesReservationRequests(seriesID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest {
+	span := tracer.StartSpanFromContext(ctx, "findSeriesReservationRequestsRepository")
+	defer span.End()
+
+	reservationRequests := []model.ReservationRequest{}
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 3*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().SetSort(bson.D{{"startDate", 1}})
+	cursor, err := r.Db.Collection("reservation_request").Find(dbCtx, bson.D{{"seriesID", seriesID}}, findOptions)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+	defer cursor.Close(dbCtx)
+
+	for cursor.Next(dbCtx) {
+		var reservationRequest model.ReservationRequest
+		err := cursor.Decode(&reservationRequest)
+		if err != nil {
+			r.logError(span, err, ctx)
+			continue
+		}
+
+		reservationRequests = append(reservationRequests, reservationRequest)
+	}
+
+	return &reservationRequests
+}
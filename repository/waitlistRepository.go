@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *Repository) SaveWaitlistEntry(waitlistEntry *model.WaitlistEntry, ctx context.Context) *model.WaitlistEntry {
+	span := tracer.StartSpanFromContext(ctx, "saveWaitlistEntryRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	waitlistEntry.ID = primitive.NewObjectID()
+	_, err := r.Db.Collection("waitlist_entry").InsertOne(dbCtx, &waitlistEntry)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	return waitlistEntry
+}
+
+func (r *Repository) FindWaitlistEntry(waitlistEntryID primitive.ObjectID, ctx context.Context) *model.WaitlistEntry {
+	span := tracer.StartSpanFromContext(ctx, "findWaitlistEntryRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	var waitlistEntry model.WaitlistEntry
+	err := r.Db.Collection("waitlist_entry").FindOne(dbCtx, bson.D{{"_id", waitlistEntryID}}).Decode(&waitlistEntry)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	return &waitlistEntry
+}
+
+func (r *Repository) FindGuestsWaitlistEntries(guestID uint, ctx context.Context) *[]model.WaitlistEntry {
+	span := tracer.StartSpanFromContext(ctx, "findGuestsWaitlistEntriesRepository")
+	defer span.End()
+
+	return r.findWaitlistEntries(bson.D{{"guestID", guestID}}, ctx)
+}
+
+// FindWaitingWaitlistEntries returns the WAITING entries of the accommodation for future dates overlapping with
+// the period between from and to, in the order the guests joined the waitlist.
+func (r *Repository) FindWaitingWaitlistEntries(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.WaitlistEntry {
+	span := tracer.StartSpanFromContext(ctx, "findWaitingWaitlistEntriesRepository")
+	defer span.End()
+
+	filter := bson.D{
+		{"accommodationID", accommodationID},
+		{"status", model.WAITING},
+		{"startDate", bson.D{{"$lt", to}, {"$gt", time.Now()}}},
+		{"endDate", bson.D{{"$gt", from}}},
+	}
+
+	return r.findWaitlistEntries(filter, ctx)
+}
+
+func (r *Repository) findWaitlistEntries(filter bson.D, ctx context.Context) *[]model.WaitlistEntry {
+	span := tracer.StartSpanFromContext(ctx, "findWaitlistEntriesRepository")
+	defer span.End()
+
+	waitlistEntries := []model.WaitlistEntry{}
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 3*time.Second)
+	defer cancel()
+
+	cursor, err := r.Db.Collection("waitlist_entry").Find(dbCtx, filter, options.Find().SetSort(bson.D{{"createdAt", 1}, {"_id", 1}}))
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+	defer cursor.Close(dbCtx)
+
+	for cursor.Next(dbCtx) {
+		var waitlistEntry model.WaitlistEntry
+		err := cursor.Decode(&waitlistEntry)
+		if err != nil {
+			r.logError(span, err, ctx)
+			continue
+		}
+
+		waitlistEntries = append(waitlistEntries, waitlistEntry)
+	}
+
+	return &waitlistEntries
+}
+
+func (r *Repository) UpdateWaitlistEntry(waitlistEntry *model.WaitlistEntry, ctx context.Context) *model.WaitlistEntry {
+	span := tracer.StartSpanFromContext(ctx, "updateWaitlistEntryRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	updateQuery := bson.D{{"$set", bson.D{
+		{"status", waitlistEntry.Status},
+		{"notifiedAt", waitlistEntry.NotifiedAt},
+		{"reservationRequestID", waitlistEntry.ReservationRequestID},
+	}}}
+	_, err := r.Db.Collection("waitlist_entry").UpdateByID(dbCtx, waitlistEntry.ID, updateQuery)
+	if err != nil {
+		r.logError(span, err, ctx)
+		return nil
+	}
+
+	return waitlistEntry
+}
+
+func (r *Repository) DeleteWaitlistEntry(waitlistEntryID primitive.ObjectID, ctx context.Context) bool {
+	span := tracer.StartSpanFromContext(ctx, "deleteWaitlistEntryRepository")
+	defer span.End()
+
+	dbCtx, cancel := context.WithTimeout(tracer.ContextWithSpan(ctx, span), 1*time.Second)
+	defer cancel()
+
+	one, err := r.Db.Collection("waitlist_entry").DeleteOne(dbCtx, bson.D{{"_id", waitlistEntryID}})
+	if err != nil {
+		r.logError(span, err, ctx)
+		return false
+	}
+
+	return one.DeletedCount == 1
+}
@@ -2,29 +2,69 @@ package router
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/windbnb/reservation-service/api"
 	"github.com/windbnb/reservation-service/handler"
 	"github.com/windbnb/reservation-service/metrics"
 )
 
 func ConfigureRouter(handler *handler.Handler) *mux.Router {
+	spec := api.MustLoadSpec()
+
 	router := mux.NewRouter()
-	router.HandleFunc("/api/reservationRequest/new", metrics.MetricProxy(handler.CreateReservationRequest)).Methods("POST")
-	router.HandleFunc("/api/reservationRequest/guest/{id}", metrics.MetricProxy(handler.GetGuestsActive)).Methods("GET")
-	router.HandleFunc("/api/reservationRequest/owner/{id}", metrics.MetricProxy(handler.GetOwnersActive)).Methods("GET")
-	router.HandleFunc("/api/reservationRequest/{id}", metrics.MetricProxy(handler.DeleteReservationRequest)).Methods("DELETE")
-	router.HandleFunc("/api/reservationRequest/{id}/accept", metrics.MetricProxy(handler.AcceptReservationRequest)).Methods("PUT")
-	router.HandleFunc("/api/reservationRequest/{id}/cancel", metrics.MetricProxy(handler.CancelReservationRequest)).Methods("PUT")
-	router.HandleFunc("/api/reservationRequest/{guestId}/cancelled", metrics.MetricProxy(handler.CountGuestsCancelledReservations)).Methods("GET")
-	router.HandleFunc("/api/reservationRequest/guest/{id}/all", metrics.MetricProxy(handler.GetGuestsReservations)).Methods("GET")
-	router.HandleFunc("/api/reservationRequest/owners/{id}", metrics.MetricProxy(handler.GetOwnersReservations)).Methods("GET")
+	// metrics come first, so the requests the validation rejects are recorded as well
+	router.Use(metrics.MetricMiddleware, api.ValidateRequests(spec))
+	router.HandleFunc("/api/reservationRequest/new", handler.CreateReservationRequest).Methods("POST")
+	router.HandleFunc("/api/reservationRequest/quote", handler.QuoteReservationRequest).Methods("POST")
+	router.HandleFunc("/api/reservationRequest/guest/{id}", handler.GetGuestsActive).Methods("GET")
+	router.HandleFunc("/api/reservationRequest/owner/{id}", handler.GetOwnersActive).Methods("GET")
+	router.HandleFunc("/api/reservationRequest/{id}", handler.DeleteReservationRequest).Methods("DELETE")
+	router.HandleFunc("/api/reservationRequest/{id}/accept", handler.AcceptReservationRequest).Methods("PUT")
+	router.HandleFunc("/api/reservationRequest/{id}/cancel", handler.CancelReservationRequest).Methods("PUT")
+	router.HandleFunc("/api/reservationRequest/{id}/checkIn", handler.CheckIn).Methods("PUT")
+	router.HandleFunc("/api/reservationRequest/{id}/checkOut", handler.CheckOut).Methods("PUT")
+	router.HandleFunc("/api/reservationRequest/{id}/noShow", handler.MarkNoShow).Methods("PUT")
+	router.HandleFunc("/api/reservationRequest/{guestId}/cancelled", handler.CountGuestsCancelledReservations).Methods("GET")
+	router.HandleFunc("/api/reservationRequest/guest/{id}/all", handler.GetGuestsReservations).Methods("GET")
+	router.HandleFunc("/api/reservationRequest/owners/{id}", handler.GetOwnersReservations).Methods("GET")
 
 	router.HandleFunc("/api/reservationRequest/guest/{guestId}/host/{hostId}", handler.GetWheatherGuestWasWithHost).Methods("GET")
 	router.HandleFunc("/api/reservationRequest/guest/{guestId}/accomodation/{accomodationId}", handler.GetWheatherGuestWasInAccomodation).Methods("GET")
+	router.HandleFunc("/api/reservationRequest/guest/{guestId}/eligibility", handler.GetRatingEligibility).Methods("GET")
+	router.HandleFunc("/api/reservationRequest/eligibility", handler.GetRatingEligibilities).Methods("POST")
+	router.HandleFunc("/api/reservationRequest/{id}/review", handler.MarkReviewSubmitted).Methods("PUT")
+
+	router.HandleFunc("/api/reservationGroup/new", handler.CreateReservationGroup).Methods("POST")
+	router.HandleFunc("/api/reservationGroup/guest", handler.GetGuestsReservationGroups).Methods("GET")
+	router.HandleFunc("/api/reservationGroup/owner", handler.GetOwnersReservationGroups).Methods("GET")
+	router.HandleFunc("/api/reservationGroup/{id}", handler.GetReservationGroup).Methods("GET")
+	router.HandleFunc("/api/reservationGroup/{id}/accept", handler.AcceptReservationGroup).Methods("PUT")
+	router.HandleFunc("/api/reservationGroup/{id}/decline", handler.DeclineReservationGroup).Methods("PUT")
+	router.HandleFunc("/api/reservationGroup/{id}/cancel", handler.CancelReservationGroup).Methods("PUT")
+
+	router.HandleFunc("/api/reservationSeries/{id}", handler.GetReservationSeries).Methods("GET")
+	router.HandleFunc("/api/reservationSeries/{id}/cancel", handler.CancelReservationSeries).Methods("PUT")
+
+	router.HandleFunc("/api/waitlist", handler.JoinWaitlist).Methods("POST")
+	router.HandleFunc("/api/waitlist", handler.GetGuestsWaitlistEntries).Methods("GET")
+	router.HandleFunc("/api/waitlist/{id}", handler.LeaveWaitlist).Methods("DELETE")
+
+	router.HandleFunc("/api/accommodations/{id}/calendar", handler.GetAccommodationCalendar).Methods("GET")
+	router.HandleFunc("/api/accommodations/{id}/availability", handler.GetAvailability).Methods("GET")
+	router.HandleFunc("/api/accommodations/availability", handler.CheckBulkAvailability).Methods("POST")
+	router.HandleFunc("/api/accommodations/{id}/bookingRules", handler.GetBookingRules).Methods("GET")
+	router.HandleFunc("/api/accommodations/{id}/bookingRules", handler.UpdateBookingRules).Methods("PUT")
+	router.HandleFunc("/api/accommodations/{id}/calendar/feed", handler.GetCalendarFeed).Methods("GET")
+	router.HandleFunc("/api/accommodations/{id}/calendar.ics", handler.ExportCalendar).Methods("GET")
+	router.HandleFunc("/api/accommodations/{id}/calendar/imports", handler.AddCalendarImport).Methods("POST")
+	router.HandleFunc("/api/accommodations/{id}/calendar/imports", handler.GetCalendarImports).Methods("GET")
+	router.HandleFunc("/api/accommodations/{id}/calendar/imports/{importId}", handler.DeleteCalendarImport).Methods("DELETE")
 
 	router.HandleFunc("/probe/liveness", handler.Healthcheck)
 	router.HandleFunc("/probe/readiness", handler.Ready)
 
 	router.Path("/metrics").Handler(metrics.MetricsHandler())
 
+	router.HandleFunc(api.SpecPath, api.SpecHandler(spec)).Methods("GET")
+
 	return router
 }
@@ -0,0 +1,77 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Job is a unit of background work that is run periodically by the Scheduler.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context)
+}
+
+// Scheduler runs registered jobs in their own goroutines until it is stopped.
+type Scheduler struct {
+	jobs   []Job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (s *Scheduler) Register(job Job) {
+	s.jobs = append(s.jobs, job)
+}
+
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.run(ctx, job)
+	}
+}
+
+// Stop signals all jobs to stop and waits for the runs in progress to finish, or until ctx is done, in which case
+// the runs still in progress are left behind and ctx.Err() is returned.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job) {
+	defer s.wg.Done()
+
+	slog.Info("job started", "job", job.Name, "interval", job.Interval.String())
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("job stopped", "job", job.Name)
+			return
+		case <-ticker.C:
+			job.Run(ctx)
+		}
+	}
+}
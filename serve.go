@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"github.com/rs/cors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/windbnb/reservation-service/config"
+	"github.com/windbnb/reservation-service/events"
+	"github.com/windbnb/reservation-service/handler"
+	"github.com/windbnb/reservation-service/health"
+	"github.com/windbnb/reservation-service/lifecycle"
+	"github.com/windbnb/reservation-service/logging"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/repository"
+	"github.com/windbnb/reservation-service/router"
+	"github.com/windbnb/reservation-service/scheduler"
+)
+
+// serve runs the service until it is interrupted or its server fails.
+func serve(ctx context.Context, cfg config.Config, logger *slog.Logger, args []string) error {
+	if err := flag.NewFlagSet("serve", flag.ContinueOnError).Parse(args); err != nil {
+		return err
+	}
+
+	app := &lifecycle.Manager{Logger: logger}
+	serverErrors, err := start(ctx, cfg, app, logger)
+	if err == nil {
+		select {
+		case <-ctx.Done():
+		case err = <-serverErrors:
+			logger.Error("server failed", "error", err)
+		}
+	} else if ctx.Err() != nil {
+		// interrupted while starting, which is not a failure
+		err = nil
+	}
+
+	logger.Info("service shutting down")
+	stopCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	return errors.Join(err, app.Stop(stopCtx))
+}
+
+// start starts the components of the service in the order they depend on each other: the database, the tracer,
+// the schema, the background jobs and finally the HTTP server. The returned channel receives the error the server
+// fails with after it started.
+func start(ctx context.Context, cfg config.Config, app *lifecycle.Manager, logger *slog.Logger) (<-chan error, error) {
+	db, traceTracer, closer, err := connect(ctx, cfg, app, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	repo := &repository.Repository{Db: db, Logger: logger}
+	err = app.Start(ctx, lifecycle.Component{
+		Name: "schema",
+		Start: func(ctx context.Context) error {
+			return migrateSchema(cfg.Features.Migrations, db, repo, logger, ctx)
+		}})
+	if err != nil {
+		return nil, err
+	}
+
+	reservationRequestService := newService(cfg, repo, logger)
+	paymentProvider := reservationRequestService.PaymentProvider
+	dispatcher := &events.Dispatcher{Repo: repo, MaximumAttempts: cfg.Jobs.OutboxMaximumAttempts}
+	dispatcher.Subscribe(model.RESERVATION_CANCELLED, "payment-service", events.NewPaymentServiceStandIn(paymentProvider))
+	dispatcher.Subscribe(model.RESERVATION_EXPIRED, "notification-service", events.NotificationServiceStandIn)
+	dispatcher.Subscribe(model.RESERVATION_DECLINED, "notification-service", events.DeclineNotificationServiceStandIn)
+	dispatcher.Subscribe(model.WAITLIST_NOTIFIED, "notification-service", events.WaitlistNotificationServiceStandIn)
+
+	jobs := &scheduler.Scheduler{}
+	jobs.Register(scheduler.Job{
+		Name:     "outbox-dispatcher",
+		Interval: cfg.Jobs.OutboxDispatchInterval,
+		Run:      dispatcher.DispatchPending})
+	jobs.Register(scheduler.Job{
+		Name:     "pending-payments",
+		Interval: cfg.Jobs.PendingPaymentsInterval,
+		Run:      reservationRequestService.ProcessPendingPayments})
+	jobs.Register(scheduler.Job{
+		Name:     "reservation-expiry",
+		Interval: cfg.Jobs.ReservationExpiryInterval,
+		Run: func(ctx context.Context) {
+			if expired := reservationRequestService.ExpireSubmittedReservationRequests(ctx); expired > 0 {
+				logger.InfoContext(ctx, "expired reservation requests", "count", expired)
+			}
+		}})
+	jobs.Register(scheduler.Job{
+		Name:     "stay-completion",
+		Interval: cfg.Jobs.StayCompletionInterval,
+		Run: func(ctx context.Context) {
+			if completed := reservationRequestService.CompleteFinishedStays(ctx); completed > 0 {
+				logger.InfoContext(ctx, "completed stays", "count", completed)
+			}
+		}})
+	jobs.Register(scheduler.Job{
+		Name:     "submitted-backlog",
+		Interval: cfg.Jobs.SubmittedBacklogInterval,
+		Run:      reservationRequestService.RefreshSubmittedBacklog})
+	if cfg.Features.CalendarImportSync {
+		jobs.Register(scheduler.Job{
+			Name:     "calendar-import",
+			Interval: cfg.Jobs.CalendarImportInterval,
+			Run: func(ctx context.Context) {
+				reservationRequestService.SyncCalendarImports(ctx)
+			}})
+	}
+	err = app.Start(ctx, lifecycle.Component{
+		Name: "background-jobs",
+		Start: func(ctx context.Context) error {
+			jobs.Start()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			err := jobs.Stop(ctx)
+
+			// publish what the stopped jobs and the drained requests left in the outbox
+			dispatcher.DispatchPending(ctx)
+			return err
+		}})
+	if err != nil {
+		return nil, err
+	}
+
+	monitor := &health.Monitor{
+		CacheTTL: cfg.Health.CacheTTL,
+		Timeout:  cfg.Health.CheckTimeout}
+	monitor.Register(health.MongoChecker(db))
+	monitor.Register(health.UpstreamChecker("user-service", cfg.Upstreams.UserServiceURL))
+	monitor.Register(health.UpstreamChecker("accommodation-service", cfg.Upstreams.AccommodationServiceURL))
+	monitor.Register(health.OutboxLagChecker(repo, cfg.Health.OutboxMaximumLag))
+	router := router.ConfigureRouter(&handler.Handler{
+		Tracer:  traceTracer,
+		Closer:  closer,
+		Logger:  logger,
+		Health:  monitor,
+		Service: reservationRequestService})
+
+	c := cors.New(cors.Options{
+		AllowedOrigins:   cfg.Server.CORSAllowedOrigins,
+		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowCredentials: true,
+		Debug:            false,
+		AllowedHeaders:   []string{"Accept", "Content-Type", "Content-Length", "Accept-Encoding", "X-CSRF-Token", "Authorization"},
+	})
+	srv := &http.Server{Addr: cfg.Server.Address, Handler: logging.Middleware(logger, c.Handler(router))}
+	serverErrors := make(chan error, 1)
+	err = app.Start(ctx, lifecycle.Component{
+		Name: "http-server",
+		Start: func(ctx context.Context) error {
+			// listening before serving makes a taken address fail the startup
+			listener, err := net.Listen("tcp", cfg.Server.Address)
+			if err != nil {
+				return err
+			}
+
+			logger.Info("server starting", "address", cfg.Server.Address)
+			go func() {
+				if err := srv.Serve(listener); err != http.ErrServerClosed {
+					serverErrors <- err
+				}
+			}()
+			return nil
+		},
+		// waits for the requests in flight to finish
+		Stop: srv.Shutdown})
+	if err != nil {
+		return nil, err
+	}
+
+	err = app.Start(ctx, lifecycle.Component{
+		Name: "readiness",
+		Start: func(ctx context.Context) error {
+			monitor.SetReady(true)
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			monitor.SetReady(false)
+
+			// keep serving until the readiness probe had the chance to see the service is not ready
+			select {
+			case <-time.After(cfg.Server.ReadinessDrainDelay):
+			case <-ctx.Done():
+			}
+			return nil
+		}})
+
+	return serverErrors, err
+}
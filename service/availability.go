@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/windbnb/reservation-service/client"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"github.com/windbnb/reservation-service/util"
+)
+
+const maximumAvailabilityWindows = 50
+
+// GetAvailability returns the ranges of nights between from and to in which the accommodation can be reserved,
+// and the earliest windows, at most limit of them, in which a stay of the given number of nights fits. Windows
+// may overlap, as every bookable check-in date is a window of its own.
+func (s *ReservationRequestService) GetAvailability(accommodationID uint, from time.Time, to time.Time, nights uint, limit uint, ctx context.Context) (*model.AvailabilityDto, error) {
+	span := tracer.StartSpanFromContext(ctx, "getAvailabilityService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if err := validateCalendarPeriod(from, to); err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	if nights == 0 {
+		return nil, errors.New("Number of nights must be positive")
+	}
+
+	if limit == 0 || limit > maximumAvailabilityWindows {
+		return nil, errors.New("Number of windows must be between 1 and 50")
+	}
+
+	accommodationInfo, err := client.GetAccommodation(accommodationID, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	calendarNights := s.calendarNights(&accommodationInfo, from, to, ctx)
+
+	// reservations have to start in the future, so tonight cannot be booked anymore
+	today := util.CivilDate(time.Now(), util.LoadLocation(accommodationInfo.TimeZone)).Format(util.DateLayout)
+	bookable := make([]bool, len(calendarNights))
+	for i, night := range calendarNights {
+		bookable[i] = night.Date > today && (night.Status == model.AVAILABLE || night.Status == model.REQUESTED)
+	}
+
+	availability := &model.AvailabilityDto{
+		AccommodationID: accommodationID,
+		From:            from.Format(util.DateLayout),
+		To:              to.Format(util.DateLayout),
+		Nights:          nights,
+		AvailableRanges: []model.DateRangeDto{},
+		Windows:         []model.DateRangeDto{},
+	}
+
+	dates := util.Nights(from, to)
+	for start := 0; start < len(bookable); start++ {
+		if !bookable[start] {
+			continue
+		}
+
+		end := start
+		for end < len(bookable) && bookable[end] {
+			end++
+		}
+
+		availability.AvailableRanges = append(availability.AvailableRanges, dateRange(dates[start], end-start))
+		for windowStart := start; windowStart+int(nights) <= end && len(availability.Windows) < int(limit); windowStart++ {
+			availability.Windows = append(availability.Windows, dateRange(dates[windowStart], int(nights)))
+		}
+
+		start = end
+	}
+
+	return availability, nil
+}
+
+func dateRange(startDate time.Time, nights int) model.DateRangeDto {
+	return model.DateRangeDto{
+		StartDate: startDate.Format(util.DateLayout),
+		EndDate:   startDate.AddDate(0, 0, nights).Format(util.DateLayout),
+	}
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"github.com/windbnb/reservation-service/metrics"
+	"github.com/windbnb/reservation-service/tracer"
+)
+
+// RefreshSubmittedBacklog recounts the SUBMITTED reservation requests every host has to respond to and
+// publishes them as metrics. The previous counts are kept when the repository cannot be read.
+func (s *ReservationRequestService) RefreshSubmittedBacklog(ctx context.Context) {
+	span := tracer.StartSpanFromContext(ctx, "refreshSubmittedBacklogService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	backlog := s.Repo.CountSubmittedReservationRequestsByOwner(ctx)
+	if backlog == nil {
+		return
+	}
+
+	metrics.SetSubmittedBacklog(*backlog)
+}
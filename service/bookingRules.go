@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"github.com/windbnb/reservation-service/util"
+)
+
+// BookingRuleViolationsError is returned when a stay breaks booking rules of the accommodation and lists all
+// the rules it breaks.
+type BookingRuleViolationsError struct {
+	Violations []model.RuleViolation
+}
+
+func (e *BookingRuleViolationsError) Error() string {
+	messages := []string{}
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Message)
+	}
+
+	return "Booking rules are violated: " + strings.Join(messages, "; ")
+}
+
+var weekdays = map[string]time.Weekday{
+	"SUNDAY":    time.Sunday,
+	"MONDAY":    time.Monday,
+	"TUESDAY":   time.Tuesday,
+	"WEDNESDAY": time.Wednesday,
+	"THURSDAY":  time.Thursday,
+	"FRIDAY":    time.Friday,
+	"SATURDAY":  time.Saturday,
+}
+
+// EvaluateBookingRules checks a stay of numberOfDays nights starting at startDate, requested at now, against the
+// booking rules and returns every rule it violates. The check-in weekday is taken in the location of startDate,
+// which should be the time zone of the accommodation. Reservation requests holding dates of the accommodation are
+// needed for the buffer between stays; the ones overlapping with the stay itself are not reported here.
+func EvaluateBookingRules(bookingRules *model.BookingRules, startDate time.Time, numberOfDays uint, now time.Time, reservationRequests []model.ReservationRequest) []model.RuleViolation {
+	violations := []model.RuleViolation{}
+	if bookingRules == nil {
+		return violations
+	}
+
+	if bookingRules.MinimumNights > 0 && numberOfDays < bookingRules.MinimumNights {
+		violations = append(violations, model.RuleViolation{
+			Code:    model.MINIMUM_NIGHTS,
+			Message: fmt.Sprintf("Stay must be at least %d nights long", bookingRules.MinimumNights),
+		})
+	}
+
+	if bookingRules.MaximumNights > 0 && numberOfDays > bookingRules.MaximumNights {
+		violations = append(violations, model.RuleViolation{
+			Code:    model.MAXIMUM_NIGHTS,
+			Message: fmt.Sprintf("Stay can be at most %d nights long", bookingRules.MaximumNights),
+		})
+	}
+
+	if bookingRules.AdvanceNoticeHours > 0 && startDate.Sub(now) < time.Duration(bookingRules.AdvanceNoticeHours)*time.Hour {
+		violations = append(violations, model.RuleViolation{
+			Code:    model.ADVANCE_NOTICE,
+			Message: fmt.Sprintf("Stay must be requested at least %d hours before check-in", bookingRules.AdvanceNoticeHours),
+		})
+	}
+
+	if bookingRules.BookingHorizonMonths > 0 && startDate.After(now.AddDate(0, int(bookingRules.BookingHorizonMonths), 0)) {
+		violations = append(violations, model.RuleViolation{
+			Code:    model.BOOKING_HORIZON,
+			Message: fmt.Sprintf("Check-in can be at most %d months ahead", bookingRules.BookingHorizonMonths),
+		})
+	}
+
+	if len(bookingRules.CheckInWeekdays) > 0 && !isAllowedCheckInWeekday(bookingRules.CheckInWeekdays, startDate.Weekday()) {
+		violations = append(violations, model.RuleViolation{
+			Code:    model.CHECK_IN_WEEKDAY,
+			Message: "Check-in is only possible on " + strings.Join(bookingRules.CheckInWeekdays, ", "),
+		})
+	}
+
+	if bookingRules.BufferDays > 0 {
+		checkIn := util.CivilDate(startDate, startDate.Location())
+		checkOut := checkIn.AddDate(0, 0, int(numberOfDays))
+		bufferStart := checkIn.AddDate(0, 0, -int(bookingRules.BufferDays))
+		bufferEnd := checkOut.AddDate(0, 0, int(bookingRules.BufferDays))
+		for _, reservationRequest := range reservationRequests {
+			otherCheckIn, otherCheckOut := reservationPeriod(&reservationRequest)
+			overlaps := otherCheckIn.Before(checkOut) && checkIn.Before(otherCheckOut)
+			if !overlaps && otherCheckIn.Before(bufferEnd) && bufferStart.Before(otherCheckOut) {
+				violations = append(violations, model.RuleViolation{
+					Code:    model.BUFFER_DAYS,
+					Message: fmt.Sprintf("Stays must be at least %d nights apart", bookingRules.BufferDays),
+				})
+				break
+			}
+		}
+	}
+
+	return violations
+}
+
+func isAllowedCheckInWeekday(checkInWeekdays []string, weekday time.Weekday) bool {
+	for _, checkInWeekday := range checkInWeekdays {
+		if allowed, found := weekdays[checkInWeekday]; found && allowed == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetBookingRules returns the booking rules of the accommodation. They are public so guests can be guided
+// to stays they are allowed to request.
+func (s *ReservationRequestService) GetBookingRules(accommodationID uint, ctx context.Context) (*model.BookingRules, error) {
+	span := tracer.StartSpanFromContext(ctx, "getBookingRulesService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	bookingRules := s.Repo.FindBookingRules(accommodationID, ctx)
+	if bookingRules == nil {
+		return nil, errors.New("Booking rules could not be loaded")
+	}
+
+	return bookingRules, nil
+}
+
+func (s *ReservationRequestService) UpdateBookingRules(bookingRules *model.BookingRules, hostID uint, ctx context.Context) (*model.BookingRules, error) {
+	span := tracer.StartSpanFromContext(ctx, "updateBookingRulesService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if _, err := s.findHostsAccommodation(bookingRules.AccommodationID, hostID, ctx); err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	if bookingRules.MaximumNights > 0 && bookingRules.MinimumNights > bookingRules.MaximumNights {
+		return nil, errors.New("Minimum nights cannot be greater than maximum nights")
+	}
+
+	for _, checkInWeekday := range bookingRules.CheckInWeekdays {
+		if _, found := weekdays[checkInWeekday]; !found {
+			return nil, errors.New("Check-in weekday " + checkInWeekday + " is not valid")
+		}
+	}
+
+	bookingRules.OwnerID = hostID
+	bookingRules.UpdatedAt = time.Now()
+	if s.Repo.SaveBookingRules(bookingRules, ctx) == nil {
+		return nil, errors.New("Booking rules could not be saved")
+	}
+
+	return bookingRules, nil
+}
+
+// checkBookingRules returns a BookingRuleViolationsError when the stay violates booking rules of the accommodation.
+func (s *ReservationRequestService) checkBookingRules(accommodationID uint, startDate time.Time, numberOfDays uint, ctx context.Context) error {
+	bookingRules := s.Repo.FindBookingRules(accommodationID, ctx)
+	if bookingRules == nil {
+		return errors.New("Booking rules could not be loaded")
+	}
+
+	reservationRequests := []model.ReservationRequest{}
+	if bookingRules.BufferDays > 0 {
+		if acceptedReservationRequests := s.Repo.FindAcceptedReservationRequests(accommodationID, ctx); acceptedReservationRequests != nil {
+			reservationRequests = *acceptedReservationRequests
+		}
+	}
+
+	violations := EvaluateBookingRules(bookingRules, startDate, numberOfDays, time.Now(), reservationRequests)
+	if len(violations) > 0 {
+		return &BookingRuleViolationsError{Violations: violations}
+	}
+
+	return nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"github.com/windbnb/reservation-service/util"
+)
+
+// MaximumBulkAvailabilityIDs is the most accommodations a single bulk availability check may ask about. A full
+// batch is answered by one indexed query, which BenchmarkCheckBulkAvailability_Integration holds to a 99th
+// percentile of 200ms.
+const MaximumBulkAvailabilityIDs = 5000
+
+// CheckBulkAvailability splits the accommodations into the ones free for the whole period and the ones holding
+// a date blocking reservation or an imported blocked period in it, keeping the order they were given in. Unlike
+// the calendar, it does not consult the accommodation service for available terms, which the search service
+// already filters by.
+func (s *ReservationRequestService) CheckBulkAvailability(bulkAvailabilityRequest *model.BulkAvailabilityRequest, ctx context.Context) (*model.BulkAvailabilityDto, error) {
+	span := tracer.StartSpanFromContext(ctx, "checkBulkAvailabilityService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	from, err := time.Parse(util.DateLayout, bulkAvailabilityRequest.From)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, errors.New("Start of the period must be a date")
+	}
+
+	to, err := time.Parse(util.DateLayout, bulkAvailabilityRequest.To)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, errors.New("End of the period must be a date")
+	}
+
+	if err := validateCalendarPeriod(from, to); err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	if len(bulkAvailabilityRequest.AccommodationIDs) == 0 {
+		return nil, errors.New("At least one accommodation must be given")
+	}
+
+	if len(bulkAvailabilityRequest.AccommodationIDs) > MaximumBulkAvailabilityIDs {
+		return nil, errors.New("At most " + strconv.Itoa(MaximumBulkAvailabilityIDs) + " accommodations can be checked at once")
+	}
+
+	accommodationIDs := []uint{}
+	requested := map[uint]bool{}
+	for _, accommodationID := range bulkAvailabilityRequest.AccommodationIDs {
+		if !requested[accommodationID] {
+			requested[accommodationID] = true
+			accommodationIDs = append(accommodationIDs, accommodationID)
+		}
+	}
+
+	unavailableAccommodations := s.Repo.FindUnavailableAccommodations(accommodationIDs, from, to, ctx)
+	if unavailableAccommodations == nil {
+		return nil, errors.New("Availability could not be checked")
+	}
+
+	unavailable := map[uint]bool{}
+	for _, accommodationID := range *unavailableAccommodations {
+		unavailable[accommodationID] = true
+	}
+
+	bulkAvailability := &model.BulkAvailabilityDto{
+		From:        bulkAvailabilityRequest.From,
+		To:          bulkAvailabilityRequest.To,
+		Available:   []uint{},
+		Unavailable: []uint{},
+	}
+	for _, accommodationID := range accommodationIDs {
+		if unavailable[accommodationID] {
+			bulkAvailability.Unavailable = append(bulkAvailability.Unavailable, accommodationID)
+		} else {
+			bulkAvailability.Available = append(bulkAvailability.Available, accommodationID)
+		}
+	}
+
+	return bulkAvailability, nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/windbnb/reservation-service/client"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"github.com/windbnb/reservation-service/util"
+)
+
+const maximumCalendarNights = 366
+
+// GetAccommodationCalendar returns the status of every night between from and to for the host owning the accommodation.
+func (s *ReservationRequestService) GetAccommodationCalendar(accommodationID uint, hostID uint, from time.Time, to time.Time, ctx context.Context) (*model.CalendarDto, error) {
+	span := tracer.StartSpanFromContext(ctx, "getAccommodationCalendarService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if err := validateCalendarPeriod(from, to); err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	accommodationInfo, err := client.GetAccommodation(accommodationID, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	if accommodationInfo.UserID != hostID {
+		tracer.LogError(span, errors.New("You can not access to this entity."))
+		return nil, errors.New("You can not access to this entity.")
+	}
+
+	return &model.CalendarDto{
+		AccommodationID: accommodationID,
+		From:            from.Format(util.DateLayout),
+		To:              to.Format(util.DateLayout),
+		Nights:          s.calendarNights(&accommodationInfo, from, to, ctx),
+	}, nil
+}
+
+// calendarNights merges the available terms of the accommodation with the periods blocked by imported
+// calendars and its reservation requests into the status of every night between from and to.
+func (s *ReservationRequestService) calendarNights(accommodationInfo *model.AccommodationInfo, from time.Time, to time.Time, ctx context.Context) []model.CalendarNightDto {
+	nights := []model.CalendarNightDto{}
+	nightIndexes := map[string]int{}
+	for _, night := range util.Nights(from, to) {
+		status := model.AVAILABLE
+		if !s.isDateInAvailableTerms(night, accommodationInfo.AvailableTerms, ctx) {
+			status = model.UNAVAILABLE
+		}
+
+		nightIndexes[night.Format(util.DateLayout)] = len(nights)
+		nights = append(nights, model.CalendarNightDto{Date: night.Format(util.DateLayout), Status: status})
+	}
+
+	blockedPeriods := s.Repo.FindBlockedPeriods(accommodationInfo.Id, from, to, ctx)
+	if blockedPeriods != nil {
+		for _, blockedPeriod := range *blockedPeriods {
+			for _, night := range util.Nights(blockedPeriod.StartDate, blockedPeriod.EndDate) {
+				if index, found := nightIndexes[night.Format(util.DateLayout)]; found {
+					nights[index].Status = model.BLOCKED
+				}
+			}
+		}
+	}
+
+	statuses := append([]model.ReservationRequestStatus{model.SUBMITTED}, model.DateBlockingStatuses...)
+	reservationRequests := s.Repo.FindReservationRequestsInPeriod(accommodationInfo.Id, from, to, statuses, ctx)
+	if reservationRequests == nil {
+		return nights
+	}
+
+	for _, reservationRequest := range *reservationRequests {
+		for _, night := range stayNights(&reservationRequest) {
+			index, found := nightIndexes[night.Format(util.DateLayout)]
+			if !found {
+				continue
+			}
+
+			calendarNight := &nights[index]
+			if reservationRequest.Status == model.SUBMITTED {
+				calendarNight.RequestedReservationRequestIDs = append(calendarNight.RequestedReservationRequestIDs, reservationRequest.ID.Hex())
+				if calendarNight.Status == model.AVAILABLE {
+					calendarNight.Status = model.REQUESTED
+				}
+				continue
+			}
+
+			calendarNight.Status = model.BOOKED
+			calendarNight.ReservationRequestID = reservationRequest.ID.Hex()
+		}
+	}
+
+	return nights
+}
+
+func validateCalendarPeriod(from time.Time, to time.Time) error {
+	if !from.Before(to) {
+		return errors.New("Start of the period must be before its end")
+	}
+
+	if to.Sub(from) > maximumCalendarNights*24*time.Hour {
+		return errors.New("Period cannot be longer than a year")
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const defaultReviewWindow = 14 * 24 * time.Hour
+
+// MaximumRatingEligibilityRequests is the most guest and host or accommodation pairs a single eligibility check may
+// ask about.
+const MaximumRatingEligibilityRequests = 100
+
+// GetRatingEligibility returns the completed stays that allow the guest to review the host, or the accommodation
+// when the request names one.
+func (s *ReservationRequestService) GetRatingEligibility(eligibilityRequest model.RatingEligibilityRequest, ctx context.Context) *model.RatingEligibilityDto {
+	span := tracer.StartSpanFromContext(ctx, "getRatingEligibilityService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	eligibilities, _ := s.GetRatingEligibilities([]model.RatingEligibilityRequest{eligibilityRequest}, ctx)
+	return &eligibilities[0]
+}
+
+// GetRatingEligibilities answers many eligibility requests with a single repository query. The results are
+// returned in the order of the requests.
+func (s *ReservationRequestService) GetRatingEligibilities(eligibilityRequests []model.RatingEligibilityRequest, ctx context.Context) ([]model.RatingEligibilityDto, error) {
+	span := tracer.StartSpanFromContext(ctx, "getRatingEligibilitiesService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if len(eligibilityRequests) > MaximumRatingEligibilityRequests {
+		err := errors.New("At most " + strconv.Itoa(MaximumRatingEligibilityRequests) + " eligibility requests can be checked at once")
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	stays := s.Repo.FindCompletedStays(eligibilityRequests, ctx)
+	if stays == nil {
+		stays = &[]model.ReservationRequest{}
+	}
+
+	now := time.Now()
+	eligibilities := []model.RatingEligibilityDto{}
+	for _, eligibilityRequest := range eligibilityRequests {
+		eligibility := model.RatingEligibilityDto{
+			GuestID:         eligibilityRequest.GuestID,
+			HostID:          eligibilityRequest.HostID,
+			AccommodationID: eligibilityRequest.AccommodationID,
+			Stays:           []model.EligibleStayDto{},
+		}
+
+		for _, stay := range *stays {
+			if !isStayForEligibilityRequest(&stay, eligibilityRequest) {
+				continue
+			}
+
+			var reviewedAt *time.Time
+			if eligibilityRequest.AccommodationID != 0 {
+				reviewedAt = stay.AccommodationReviewedAt
+			} else {
+				reviewedAt = stay.HostReviewedAt
+			}
+
+			eligibleStay := model.EligibleStayDto{
+				ReservationRequestID: stay.ID.Hex(),
+				AccommodationID:      stay.AccommodationID,
+				AccommodationName:    stay.AccommodationName,
+				StartDate:            stay.StartDate,
+				EndDate:              stay.EndDate,
+				ReviewWindowEndsAt:   s.reviewWindowEnd(&stay),
+				ReviewSubmitted:      reviewedAt != nil,
+			}
+			eligibleStay.CanReview = !eligibleStay.ReviewSubmitted && now.Before(eligibleStay.ReviewWindowEndsAt)
+			eligibility.Eligible = eligibility.Eligible || eligibleStay.CanReview
+
+			eligibility.Stays = append(eligibility.Stays, eligibleStay)
+		}
+
+		eligibilities = append(eligibilities, eligibility)
+	}
+
+	return eligibilities, nil
+}
+
+// MarkReviewSubmitted records that the guest reviewed the host or the accommodation of a completed stay.
+func (s *ReservationRequestService) MarkReviewSubmitted(reservationRequestId primitive.ObjectID, guestId uint, target model.ReviewTarget, ctx context.Context) (*model.ReservationRequest, error) {
+	span := tracer.StartSpanFromContext(ctx, "markReviewSubmittedService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	reservationRequest := s.Repo.FindReservationRequest(reservationRequestId, ctx)
+	if reservationRequest == nil {
+		tracer.LogError(span, errors.New("Given reservation request does not exist"))
+		return nil, errors.New("Given reservation request does not exist")
+	}
+
+	if reservationRequest.GuestID != guestId {
+		tracer.LogError(span, errors.New("You can not access to this entity"))
+		return nil, errors.New("You can not access to this entity")
+	}
+
+	if reservationRequest.Status != model.COMPLETED {
+		tracer.LogError(span, errors.New("Only completed stays can be reviewed"))
+		return nil, errors.New("Only completed stays can be reviewed")
+	}
+
+	if time.Now().After(s.reviewWindowEnd(reservationRequest)) {
+		tracer.LogError(span, errors.New("Review window for given stay has passed"))
+		return nil, errors.New("Review window for given stay has passed")
+	}
+
+	now := time.Now()
+	switch target {
+	case model.HOST_REVIEW:
+		reservationRequest.HostReviewedAt = &now
+	case model.ACCOMMODATION_REVIEW:
+		reservationRequest.AccommodationReviewedAt = &now
+	default:
+		return nil, errors.New("Review target must be HOST or ACCOMMODATION")
+	}
+
+	s.Repo.UpdateReservationRequestReview(reservationRequest, ctx)
+
+	return reservationRequest, nil
+}
+
+func (s *ReservationRequestService) reviewWindowEnd(stay *model.ReservationRequest) time.Time {
+	reviewWindow := s.ReviewWindow
+	if reviewWindow <= 0 {
+		reviewWindow = defaultReviewWindow
+	}
+
+	if stay.CheckedOutAt != nil {
+		return stay.CheckedOutAt.Add(reviewWindow)
+	}
+
+	return stay.EndDate.Add(reviewWindow)
+}
+
+func isStayForEligibilityRequest(stay *model.ReservationRequest, eligibilityRequest model.RatingEligibilityRequest) bool {
+	if stay.GuestID != eligibilityRequest.GuestID {
+		return false
+	}
+
+	// a host naming an accommodation only sees the stays in it that were with them
+	if eligibilityRequest.AccommodationID != 0 && stay.AccommodationID != eligibilityRequest.AccommodationID {
+		return false
+	}
+
+	return stay.OwnerID == eligibilityRequest.HostID || (eligibilityRequest.HostID == 0 && eligibilityRequest.AccommodationID != 0)
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/windbnb/reservation-service/metrics"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+)
+
+const defaultResponseWindow = 48 * time.Hour
+
+// ExpireSubmittedReservationRequests expires the SUBMITTED reservation requests the host did not respond to
+// within the response window, as well as the ones whose stay already started, and notifies both parties.
+// It returns the number of expired reservation requests.
+func (s *ReservationRequestService) ExpireSubmittedReservationRequests(ctx context.Context) int {
+	span := tracer.StartSpanFromContext(ctx, "expireSubmittedReservationRequestsService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	responseWindow := s.ResponseWindow
+	if responseWindow <= 0 {
+		responseWindow = defaultResponseWindow
+	}
+
+	now := time.Now()
+	reservationRequests := s.Repo.FindExpiredSubmittedReservationRequests(now.Add(-responseWindow), now, ctx)
+	if reservationRequests == nil {
+		return 0
+	}
+
+	expired := 0
+	for _, reservationRequest := range *reservationRequests {
+		if !s.Repo.ExpireReservationRequest(&reservationRequest, ctx) {
+			continue
+		}
+
+		reason := model.RESPONSE_WINDOW_ELAPSED
+		if reservationRequest.StartDate.Before(now) {
+			reason = model.START_DATE_PASSED
+		}
+
+		s.saveEvent(model.RESERVATION_EXPIRED, model.ReservationExpiredEvent{
+			ReservationRequestID: reservationRequest.ID.Hex(),
+			GuestID:              reservationRequest.GuestID,
+			OwnerID:              reservationRequest.OwnerID,
+			AccommodationID:      reservationRequest.AccommodationID,
+			StartDate:            reservationRequest.StartDate,
+			EndDate:              reservationRequest.EndDate,
+			Reason:               reason,
+		}, ctx)
+
+		metrics.ReservationRequestExpired(reason)
+		s.notifyWaitlist(&reservationRequest, ctx)
+		expired++
+	}
+
+	return expired
+}
@@ -0,0 +1,441 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/windbnb/reservation-service/metrics"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/payment"
+	"github.com/windbnb/reservation-service/tracer"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaximumGroupSize is the largest number of accommodations a single booking group may reserve.
+const MaximumGroupSize = 10
+
+// SaveReservationGroup creates a reservation request for every unit of the group, or none of them when any
+// unit cannot be booked. Groups of accommodations that are all accepted automatically are paid for right away,
+// otherwise the host accepts or declines the group as a whole.
+func (s *ReservationRequestService) SaveReservationGroup(createReservationGroupRequest *model.CreateReservationGroupRequest, ctx context.Context) (*model.ReservationGroup, error) {
+	span := tracer.StartSpanFromContext(ctx, "saveReservationGroupService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if len(createReservationGroupRequest.Units) < 2 {
+		return nil, errors.New("Group must contain at least two accommodations")
+	}
+
+	if len(createReservationGroupRequest.Units) > MaximumGroupSize {
+		return nil, errors.New("Group cannot contain more than " + strconv.Itoa(MaximumGroupSize) + " accommodations")
+	}
+
+	groupID := primitive.NewObjectID()
+	reservationRequests := []*model.ReservationRequest{}
+	automatically := true
+	seen := map[uint]bool{}
+	for _, unit := range createReservationGroupRequest.Units {
+		if seen[unit.AccommodationID] {
+			return nil, errors.New("Accommodation can be booked only once in a group")
+		}
+		seen[unit.AccommodationID] = true
+
+		reservationRequest, accommodationInfo, err := s.newReservationRequest(&model.CreateReservationRequest{
+			StartDate:       createReservationGroupRequest.StartDate,
+			CheckInDate:     createReservationGroupRequest.CheckInDate,
+			NumberOfDays:    createReservationGroupRequest.NumberOfDays,
+			AccommodationID: unit.AccommodationID,
+			GuestID:         createReservationGroupRequest.GuestID,
+			GuestNumber:     unit.GuestNumber,
+		}, ctx)
+		if err != nil {
+			tracer.LogError(span, err)
+			return nil, err
+		}
+
+		if len(reservationRequests) > 0 && reservationRequests[0].OwnerID != reservationRequest.OwnerID {
+			return nil, errors.New("Accommodations of a group must belong to the same host")
+		}
+
+		if accommodationInfo.AcceptReservationType != model.AUTOMATICALLY {
+			automatically = false
+		}
+
+		reservationRequest.GroupID = &groupID
+		reservationRequests = append(reservationRequests, reservationRequest)
+	}
+
+	ctx, cancel, err := beginStateChange(ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+	defer cancel()
+
+	if !s.Repo.SaveReservationRequests(reservationRequests, ctx) {
+		tracer.LogError(span, errors.New("It's not possible to save reservation group"))
+		return nil, errors.New("It's not possible to save reservation group")
+	}
+
+	for _, reservationRequest := range reservationRequests {
+		metrics.ReservationRequestCreated(reservationRequest, len(stayNights(reservationRequest)))
+	}
+
+	if automatically {
+		if err := s.processGroupPayment(groupID, reservationRequests, ctx); err != nil {
+			tracer.LogError(span, err)
+			return nil, err
+		}
+	}
+
+	return newReservationGroup(groupID, reservationRequests), nil
+}
+
+// GetReservationGroup returns the group to its guest or its host.
+func (s *ReservationRequestService) GetReservationGroup(groupID primitive.ObjectID, userID uint, ctx context.Context) (*model.ReservationGroup, error) {
+	span := tracer.StartSpanFromContext(ctx, "getReservationGroupService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	reservationRequests, err := s.findReservationGroup(groupID, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	if reservationRequests[0].GuestID != userID && reservationRequests[0].OwnerID != userID {
+		tracer.LogError(span, errors.New("You can not access to this entity"))
+		return nil, errors.New("You can not access to this entity")
+	}
+
+	return newReservationGroup(groupID, reservationRequests), nil
+}
+
+func (s *ReservationRequestService) GetGuestsReservationGroups(guestID uint, ctx context.Context) *[]model.ReservationGroup {
+	span := tracer.StartSpanFromContext(ctx, "getGuestsReservationGroupsService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	return groupReservationRequests(s.Repo.FindGuestsGroupedReservationRequests(guestID, ctx))
+}
+
+func (s *ReservationRequestService) GetOwnersReservationGroups(ownerID uint, ctx context.Context) *[]model.ReservationGroup {
+	span := tracer.StartSpanFromContext(ctx, "getOwnersReservationGroupsService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	return groupReservationRequests(s.Repo.FindOwnersGroupedReservationRequests(ownerID, ctx))
+}
+
+// AcceptReservationGroup accepts every reservation request of the group with a single payment, provided that
+// all of them are still SUBMITTED and none of their accommodations got reserved in the meantime.
+func (s *ReservationRequestService) AcceptReservationGroup(groupID primitive.ObjectID, hostID uint, ctx context.Context) (*model.ReservationGroup, error) {
+	span := tracer.StartSpanFromContext(ctx, "acceptReservationGroupService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	reservationRequests, err := s.findSubmittedReservationGroup(groupID, hostID, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	for _, reservationRequest := range reservationRequests {
+		checkInDate, checkOutDate := reservationPeriod(reservationRequest)
+		if s.isReservedAlready(reservationRequest.AccommodationID, checkInDate, checkOutDate, ctx) {
+			tracer.LogError(span, errors.New("Accomodation is reserved already"))
+			return nil, errors.New("Accomodation is reserved already")
+		}
+	}
+
+	ctx, cancel, err := beginStateChange(ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+	defer cancel()
+
+	if err := s.processGroupPayment(groupID, reservationRequests, ctx); err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	return newReservationGroup(groupID, reservationRequests), nil
+}
+
+// DeclineReservationGroup declines every reservation request of the group that is still SUBMITTED, recording an
+// event for each of them.
+func (s *ReservationRequestService) DeclineReservationGroup(groupID primitive.ObjectID, hostID uint, ctx context.Context) (*model.ReservationGroup, error) {
+	span := tracer.StartSpanFromContext(ctx, "declineReservationGroupService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	reservationRequests, err := s.findSubmittedReservationGroup(groupID, hostID, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	ctx, cancel, err := beginStateChange(ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+	defer cancel()
+
+	for _, reservationRequest := range reservationRequests {
+		if s.declineReservationRequest(reservationRequest, model.DECLINED_BY_HOST, ctx) {
+			metrics.ReservationRequestsDeclined(reservationRequest, metrics.DECLINED_BY_HOST, 1)
+		}
+	}
+
+	return newReservationGroup(groupID, reservationRequests), nil
+}
+
+// CancelReservationGroup cancels every reservation request of the group, each refunded by its own cancellation
+// policy out of the shared payment.
+func (s *ReservationRequestService) CancelReservationGroup(groupID primitive.ObjectID, guestID uint, ctx context.Context) (*model.ReservationGroup, error) {
+	span := tracer.StartSpanFromContext(ctx, "cancelReservationGroupService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	reservationRequests, err := s.findReservationGroup(groupID, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	// reservation requests the guest already cancelled one by one are left as they are
+	cancellable := []*model.ReservationRequest{}
+	for _, reservationRequest := range reservationRequests {
+		if reservationRequest.Status == model.CANCELLED && reservationRequest.GuestID == guestID {
+			continue
+		}
+
+		if err := checkCancellable(reservationRequest, guestID); err != nil {
+			tracer.LogError(span, err)
+			return nil, err
+		}
+		cancellable = append(cancellable, reservationRequest)
+	}
+
+	if len(cancellable) == 0 {
+		tracer.LogError(span, errors.New("You cannot cancel given reservation group - wrong status"))
+		return nil, errors.New("You cannot cancel given reservation group - wrong status")
+	}
+
+	ctx, cancel, err := beginStateChange(ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+	defer cancel()
+
+	for _, reservationRequest := range cancellable {
+		s.cancelReservationRequest(reservationRequest, ctx)
+	}
+
+	return newReservationGroup(groupID, reservationRequests), nil
+}
+
+func (s *ReservationRequestService) findReservationGroup(groupID primitive.ObjectID, ctx context.Context) ([]*model.ReservationRequest, error) {
+	found := s.Repo.FindGroupReservationRequests(groupID, ctx)
+	if found == nil || len(*found) == 0 {
+		return nil, errors.New("Given reservation group does not exist")
+	}
+
+	reservationRequests := []*model.ReservationRequest{}
+	for i := range *found {
+		reservationRequests = append(reservationRequests, &(*found)[i])
+	}
+
+	return reservationRequests, nil
+}
+
+func (s *ReservationRequestService) findSubmittedReservationGroup(groupID primitive.ObjectID, hostID uint, ctx context.Context) ([]*model.ReservationRequest, error) {
+	reservationRequests, err := s.findReservationGroup(groupID, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, reservationRequest := range reservationRequests {
+		if reservationRequest.OwnerID != hostID {
+			return nil, errors.New("You can not access to this entity.")
+		}
+
+		if reservationRequest.Status != model.SUBMITTED {
+			return nil, errors.New("You cannot update given reservation group - wrong status.")
+		}
+	}
+
+	return reservationRequests, nil
+}
+
+// processGroupPayment takes one authorization for the total of the group, so the guest is charged once, and
+// captures the share of every reservation request from it. When a member is no longer SUBMITTED, the members
+// already held are submitted again and nothing is charged.
+func (s *ReservationRequestService) processGroupPayment(groupID primitive.ObjectID, reservationRequests []*model.ReservationRequest, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "processGroupPaymentService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	for i, reservationRequest := range reservationRequests {
+		if s.Repo.TransitionReservationRequestStatus(reservationRequest, model.SUBMITTED, model.PAYMENT_PENDING, ctx) {
+			continue
+		}
+
+		for _, held := range reservationRequests[:i] {
+			s.Repo.TransitionReservationRequestStatus(held, model.PAYMENT_PENDING, model.SUBMITTED, ctx)
+		}
+		tracer.LogError(span, errors.New("You cannot update given reservation group - wrong status."))
+		return errors.New("You cannot update given reservation group - wrong status.")
+	}
+
+	if s.PaymentProvider == nil {
+		for _, reservationRequest := range reservationRequests {
+			s.confirmReservationRequest(reservationRequest, ctx)
+		}
+		return nil
+	}
+
+	total := 0.0
+	for _, reservationRequest := range reservationRequests {
+		if reservationRequest.Price != nil {
+			total += reservationRequest.Price.Total
+		}
+	}
+
+	authorization, err := s.PaymentProvider.Authorize(payment.AuthorizationRequest{
+		Reference: groupID.Hex(),
+		GuestID:   reservationRequests[0].GuestID,
+		Amount:    total,
+	}, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		for _, reservationRequest := range reservationRequests {
+			s.failPayment(reservationRequest, err.Error(), ctx)
+		}
+		return errors.New("Payment authorization failed: " + err.Error())
+	}
+
+	for _, reservationRequest := range reservationRequests {
+		amount := 0.0
+		if reservationRequest.Price != nil {
+			amount = reservationRequest.Price.Total
+		}
+
+		reservationRequest.Payment = &model.Payment{
+			AuthorizationID: authorization.ID,
+			Status:          authorization.Status,
+			Amount:          amount,
+			ExpiresAt:       authorization.ExpiresAt,
+		}
+		s.Repo.UpdateReservationRequestPayment(reservationRequest, ctx)
+	}
+
+	switch authorization.Status {
+	case model.AUTHORIZATION_FAILED:
+		for _, reservationRequest := range reservationRequests {
+			s.failPayment(reservationRequest, authorization.DeclineReason, ctx)
+		}
+		return errors.New("Payment authorization failed: " + authorization.DeclineReason)
+	case model.AUTHORIZATION_PENDING:
+		return nil
+	}
+
+	return s.captureGroupPayment(reservationRequests, ctx)
+}
+
+// captureGroupPayment captures the total of the group from its single authorization and accepts every reservation
+// request of the group only once that succeeded. When the capture fails, the authorization is voided and all of them
+// fail, so the group is accepted or rejected as a unit.
+func (s *ReservationRequestService) captureGroupPayment(reservationRequests []*model.ReservationRequest, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "captureGroupPaymentService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	ctx, cancel := detach(ctx)
+	defer cancel()
+
+	authorizationID := reservationRequests[0].Payment.AuthorizationID
+	expiresAt := reservationRequests[0].Payment.ExpiresAt
+	total := 0.0
+	for _, reservationRequest := range reservationRequests {
+		total += reservationRequest.Payment.Amount
+	}
+
+	err := s.PaymentProvider.Capture(authorizationID, total, ctx)
+	if errors.Is(err, payment.ErrAuthorizationPending) && time.Now().Before(expiresAt) {
+		return nil
+	}
+
+	if errors.Is(err, payment.ErrAuthorizationPending) {
+		err = payment.ErrAuthorizationExpired
+	}
+
+	if err != nil {
+		tracer.LogError(span, err)
+		_ = s.PaymentProvider.Void(authorizationID, ctx)
+		for _, reservationRequest := range reservationRequests {
+			s.failPayment(reservationRequest, err.Error(), ctx)
+		}
+		return errors.New("Payment authorization failed: " + err.Error())
+	}
+
+	capturedAt := time.Now()
+	for _, reservationRequest := range reservationRequests {
+		reservationRequest.Payment.Status = model.CAPTURED
+		reservationRequest.Payment.CapturedAt = &capturedAt
+		s.Repo.UpdateReservationRequestPayment(reservationRequest, ctx)
+	}
+
+	for _, reservationRequest := range reservationRequests {
+		s.confirmReservationRequest(reservationRequest, ctx)
+	}
+
+	return nil
+}
+
+func newReservationGroup(groupID primitive.ObjectID, reservationRequests []*model.ReservationRequest) *model.ReservationGroup {
+	reservationGroup := model.ReservationGroup{ID: groupID}
+	for _, reservationRequest := range reservationRequests {
+		reservationGroup.ReservationRequests = append(reservationGroup.ReservationRequests, *reservationRequest)
+	}
+
+	return &reservationGroup
+}
+
+// groupReservationRequests collects reservation requests ordered by group into their groups.
+func groupReservationRequests(reservationRequests *[]model.ReservationRequest) *[]model.ReservationGroup {
+	if reservationRequests == nil {
+		return nil
+	}
+
+	reservationGroups := []model.ReservationGroup{}
+	for _, reservationRequest := range *reservationRequests {
+		if reservationRequest.GroupID == nil {
+			continue
+		}
+
+		last := len(reservationGroups) - 1
+		if last < 0 || reservationGroups[last].ID != *reservationRequest.GroupID {
+			reservationGroups = append(reservationGroups, model.ReservationGroup{ID: *reservationRequest.GroupID})
+			last++
+		}
+
+		reservationGroups[last].ReservationRequests = append(reservationGroups[last].ReservationRequests, reservationRequest)
+	}
+
+	return &reservationGroups
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/windbnb/reservation-service/client"
+	"github.com/windbnb/reservation-service/ical"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CalendarFeedToken returns the token authorizing access to the iCalendar feed of the accommodation.
+func (s *ReservationRequestService) CalendarFeedToken(accommodationID uint) (string, error) {
+	if s.CalendarFeedSecret == "" {
+		return "", errors.New("Calendar feeds are disabled")
+	}
+
+	mac := hmac.New(sha256.New, []byte(s.CalendarFeedSecret))
+	mac.Write([]byte(strconv.FormatUint(uint64(accommodationID), 10)))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
+
+// GetCalendarFeedToken returns the feed token to the host owning the accommodation.
+func (s *ReservationRequestService) GetCalendarFeedToken(accommodationID uint, hostID uint, ctx context.Context) (string, error) {
+	span := tracer.StartSpanFromContext(ctx, "getCalendarFeedTokenService")
+	defer span.End()
+
+	if _, err := s.findHostsAccommodation(accommodationID, hostID, ctx); err != nil {
+		tracer.LogError(span, err)
+		return "", err
+	}
+
+	token, err := s.CalendarFeedToken(accommodationID)
+	if err != nil {
+		tracer.LogError(span, err)
+		return "", err
+	}
+
+	return token, nil
+}
+
+// ExportCalendar writes the accepted reservations of the accommodation as an iCalendar document.
+func (s *ReservationRequestService) ExportCalendar(accommodationID uint, token string, ctx context.Context) ([]byte, error) {
+	span := tracer.StartSpanFromContext(ctx, "exportCalendarService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	expectedToken, err := s.CalendarFeedToken(accommodationID)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	if !hmac.Equal([]byte(token), []byte(expectedToken)) {
+		tracer.LogError(span, errors.New("Calendar feed token is not valid"))
+		return nil, errors.New("Calendar feed token is not valid")
+	}
+
+	today := util.StartOfDay(time.Now())
+	statuses := []model.ReservationRequestStatus{model.ACCEPTED, model.CHECKED_IN}
+	reservationRequests := s.Repo.FindReservationRequestsInPeriod(accommodationID, today.AddDate(0, 0, -30), today.AddDate(2, 0, 0), statuses, ctx)
+	if reservationRequests == nil {
+		return nil, errors.New("Reservations could not be loaded")
+	}
+
+	events := []ical.Event{}
+	for _, reservationRequest := range *reservationRequests {
+		checkInDate, checkOutDate := reservationPeriod(&reservationRequest)
+		events = append(events, ical.Event{
+			UID:     reservationRequest.ID.Hex() + "@windbnb",
+			Summary: "Reserved",
+			Start:   checkInDate,
+			End:     checkOutDate,
+			AllDay:  true,
+		})
+	}
+
+	var calendar bytes.Buffer
+	err = ical.Encode(&calendar, "Windbnb accommodation "+strconv.FormatUint(uint64(accommodationID), 10), events)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	return calendar.Bytes(), nil
+}
+
+func (s *ReservationRequestService) AddCalendarImport(accommodationID uint, hostID uint, url string, ctx context.Context) (*model.CalendarImport, error) {
+	span := tracer.StartSpanFromContext(ctx, "addCalendarImportService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	accommodationInfo, err := s.findHostsAccommodation(accommodationID, hostID, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	if err := client.ValidateCalendarURL(url, s.CalendarImportDir, s.CalendarImportHosts); err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	calendarImport := s.Repo.SaveCalendarImport(&model.CalendarImport{
+		AccommodationID: accommodationID,
+		OwnerID:         hostID,
+		URL:             url,
+		TimeZone:        accommodationInfo.TimeZone,
+		CreatedAt:       time.Now(),
+	}, ctx)
+	if calendarImport == nil {
+		return nil, errors.New("Calendar import could not be saved")
+	}
+
+	s.syncCalendarImport(calendarImport, ctx)
+	return calendarImport, nil
+}
+
+func (s *ReservationRequestService) GetCalendarImports(accommodationID uint, hostID uint, ctx context.Context) (*[]model.CalendarImport, error) {
+	span := tracer.StartSpanFromContext(ctx, "getCalendarImportsService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if _, err := s.findHostsAccommodation(accommodationID, hostID, ctx); err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	calendarImports := s.Repo.FindCalendarImports(accommodationID, ctx)
+	if calendarImports == nil {
+		return nil, errors.New("Calendar imports could not be loaded")
+	}
+
+	return calendarImports, nil
+}
+
+func (s *ReservationRequestService) DeleteCalendarImport(calendarImportID primitive.ObjectID, hostID uint, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "deleteCalendarImportService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	calendarImport := s.Repo.FindCalendarImport(calendarImportID, ctx)
+	if calendarImport == nil {
+		tracer.LogError(span, errors.New("Calendar import with given id does not exist."))
+		return errors.New("Calendar import with given id does not exist.")
+	}
+
+	if calendarImport.OwnerID != hostID {
+		tracer.LogError(span, errors.New("You can not access to this entity."))
+		return errors.New("You can not access to this entity.")
+	}
+
+	if !s.Repo.DeleteCalendarImport(calendarImportID, ctx) {
+		return errors.New("Calendar import could not be deleted")
+	}
+
+	return nil
+}
+
+// SyncCalendarImports pulls every imported calendar and replaces the periods it blocks. It returns the
+// number of calendars synchronised successfully.
+func (s *ReservationRequestService) SyncCalendarImports(ctx context.Context) int {
+	span := tracer.StartSpanFromContext(ctx, "syncCalendarImportsService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	calendarImports := s.Repo.FindCalendarImports(0, ctx)
+	if calendarImports == nil {
+		return 0
+	}
+
+	synced := 0
+	for i := range *calendarImports {
+		if s.syncCalendarImport(&(*calendarImports)[i], ctx) {
+			synced++
+		}
+	}
+
+	return synced
+}
+
+func (s *ReservationRequestService) syncCalendarImport(calendarImport *model.CalendarImport, ctx context.Context) bool {
+	span := tracer.StartSpanFromContext(ctx, "syncCalendarImportService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	now := time.Now()
+	calendarImport.LastSyncedAt = &now
+	calendarImport.LastError = ""
+
+	blockedPeriods, err := s.fetchBlockedPeriods(calendarImport, ctx)
+	if err == nil && !s.Repo.ReplaceBlockedPeriods(calendarImport, blockedPeriods, ctx) {
+		err = errors.New("Blocked periods could not be saved")
+	}
+
+	if err != nil {
+		tracer.LogError(span, err)
+		s.logger().WarnContext(ctx, "syncing imported calendar failed", "calendarImportID", calendarImport.ID.Hex(), "error", err)
+		calendarImport.LastError = err.Error()
+	}
+
+	s.Repo.UpdateCalendarImportSync(calendarImport, ctx)
+	return err == nil
+}
+
+func (s *ReservationRequestService) fetchBlockedPeriods(calendarImport *model.CalendarImport, ctx context.Context) ([]model.BlockedPeriod, error) {
+	calendar, err := client.FetchCalendar(calendarImport.URL, s.CalendarImportDir, s.CalendarImportHosts, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := ical.Parse(bytes.NewReader(calendar))
+	if err != nil {
+		return nil, err
+	}
+
+	location := util.LoadLocation(calendarImport.TimeZone)
+	blockedPeriods := []model.BlockedPeriod{}
+	for _, event := range events {
+		if event.End.Before(event.Start) {
+			continue
+		}
+
+		// all-day events are dates already, timed events block the local nights of the accommodation they span,
+		// and at least the night they start on
+		startDate, endDate := event.Start, event.End
+		if !event.AllDay {
+			startDate, endDate = util.CivilDate(event.Start, location), util.CivilDate(event.End, location)
+		}
+
+		if !startDate.Before(endDate) {
+			endDate = startDate.AddDate(0, 0, 1)
+		}
+
+		blockedPeriods = append(blockedPeriods, model.BlockedPeriod{
+			UID:       event.UID,
+			Summary:   event.Summary,
+			StartDate: startDate,
+			EndDate:   endDate,
+		})
+	}
+
+	return blockedPeriods, nil
+}
+
+// isBlockedByImportedCalendar reports whether any night between the local dates checkInDate and checkOutDate is
+// blocked by an imported calendar.
+func (s *ReservationRequestService) isBlockedByImportedCalendar(accommodationID uint, checkInDate time.Time, checkOutDate time.Time, ctx context.Context) bool {
+	blockedPeriods := s.Repo.FindBlockedPeriods(accommodationID, checkInDate, checkOutDate, ctx)
+	return blockedPeriods != nil && len(*blockedPeriods) > 0
+}
+
+func (s *ReservationRequestService) findHostsAccommodation(accommodationID uint, hostID uint, ctx context.Context) (*model.AccommodationInfo, error) {
+	accommodationInfo, err := client.GetAccommodation(accommodationID, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if accommodationInfo.UserID != hostID {
+		return nil, errors.New("You can not access to this entity.")
+	}
+
+	return &accommodationInfo, nil
+}
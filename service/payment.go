@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/windbnb/reservation-service/client"
+	"github.com/windbnb/reservation-service/metrics"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/payment"
+	"github.com/windbnb/reservation-service/tracer"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// processPayment holds the dates of the SUBMITTED reservation request while its payment is authorized and accepts
+// it once the payment is captured. Reservation requests are left PAYMENT_PENDING while the provider waits on
+// the guest, and end up PAYMENT_FAILED when the authorization is declined. A reservation request that was
+// cancelled, deleted or expired since it was read is left as it is.
+func (s *ReservationRequestService) processPayment(reservationRequest *model.ReservationRequest, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "processPaymentService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if !s.Repo.TransitionReservationRequestStatus(reservationRequest, model.SUBMITTED, model.PAYMENT_PENDING, ctx) {
+		tracer.LogError(span, errors.New("You cannot update given reservation request - wrong status."))
+		return errors.New("You cannot update given reservation request - wrong status.")
+	}
+
+	if s.PaymentProvider == nil {
+		s.confirmReservationRequest(reservationRequest, ctx)
+		return nil
+	}
+
+	amount := 0.0
+	if reservationRequest.Price != nil {
+		amount = reservationRequest.Price.Total
+	}
+
+	authorization, err := s.PaymentProvider.Authorize(payment.AuthorizationRequest{
+		Reference: reservationRequest.ID.Hex(),
+		GuestID:   reservationRequest.GuestID,
+		Amount:    amount,
+	}, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		s.failPayment(reservationRequest, err.Error(), ctx)
+		return errors.New("Payment authorization failed: " + err.Error())
+	}
+
+	reservationRequest.Payment = &model.Payment{
+		AuthorizationID: authorization.ID,
+		Status:          authorization.Status,
+		Amount:          amount,
+		ExpiresAt:       authorization.ExpiresAt,
+	}
+	s.Repo.UpdateReservationRequestPayment(reservationRequest, ctx)
+
+	switch authorization.Status {
+	case model.AUTHORIZATION_FAILED:
+		s.failPayment(reservationRequest, authorization.DeclineReason, ctx)
+		return errors.New("Payment authorization failed: " + authorization.DeclineReason)
+	case model.AUTHORIZATION_PENDING:
+		return nil
+	}
+
+	return s.capturePayment(reservationRequest, ctx)
+}
+
+// capturePayment captures the authorized payment of a PAYMENT_PENDING reservation request and accepts it.
+// Authorizations that are still pending are kept until they expire.
+func (s *ReservationRequestService) capturePayment(reservationRequest *model.ReservationRequest, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "capturePaymentService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	ctx, cancel := detach(ctx)
+	defer cancel()
+
+	err := s.PaymentProvider.Capture(reservationRequest.Payment.AuthorizationID, reservationRequest.Payment.Amount, ctx)
+	if errors.Is(err, payment.ErrAuthorizationPending) && time.Now().Before(reservationRequest.Payment.ExpiresAt) {
+		return nil
+	}
+
+	if errors.Is(err, payment.ErrAuthorizationPending) {
+		err = payment.ErrAuthorizationExpired
+	}
+
+	if err != nil {
+		tracer.LogError(span, err)
+		_ = s.PaymentProvider.Void(reservationRequest.Payment.AuthorizationID, ctx)
+		s.failPayment(reservationRequest, err.Error(), ctx)
+		return errors.New("Payment authorization failed: " + err.Error())
+	}
+
+	capturedAt := time.Now()
+	reservationRequest.Payment.Status = model.CAPTURED
+	reservationRequest.Payment.CapturedAt = &capturedAt
+	s.Repo.UpdateReservationRequestPayment(reservationRequest, ctx)
+
+	s.confirmReservationRequest(reservationRequest, ctx)
+	return nil
+}
+
+func (s *ReservationRequestService) failPayment(reservationRequest *model.ReservationRequest, reason string, ctx context.Context) {
+	span := tracer.StartSpanFromContext(ctx, "failPaymentService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	ctx, cancel := detach(ctx)
+	defer cancel()
+
+	if reservationRequest.Payment != nil {
+		reservationRequest.Payment.Status = model.AUTHORIZATION_FAILED
+		reservationRequest.Payment.FailureReason = reason
+		s.Repo.UpdateReservationRequestPayment(reservationRequest, ctx)
+	}
+
+	reservationRequest.Status = model.PAYMENT_FAILED
+	s.Repo.UpdateReservationRequestStatus(reservationRequest, ctx)
+
+	s.notifyWaitlist(reservationRequest, ctx)
+}
+
+// voidPayment releases the authorization of a reservation request cancelled before its payment was captured. The
+// reservation requests of a group share their authorization, so it having been voided already is not a failure.
+func (s *ReservationRequestService) voidPayment(reservationRequest *model.ReservationRequest, ctx context.Context) {
+	span := tracer.StartSpanFromContext(ctx, "voidPaymentService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if s.PaymentProvider == nil || reservationRequest.Payment == nil || reservationRequest.Payment.AuthorizationID == "" {
+		return
+	}
+
+	err := s.PaymentProvider.Void(reservationRequest.Payment.AuthorizationID, ctx)
+	if err != nil && !errors.Is(err, payment.ErrAuthorizationVoided) {
+		tracer.LogError(span, err)
+		s.logger().WarnContext(ctx, "voiding payment of cancelled reservation request failed", "reservationRequestID", reservationRequest.ID.Hex(), "error", err)
+		return
+	}
+
+	reservationRequest.Payment.Status = model.VOIDED
+	s.Repo.UpdateReservationRequestPayment(reservationRequest, ctx)
+}
+
+func (s *ReservationRequestService) confirmReservationRequest(reservationRequest *model.ReservationRequest, ctx context.Context) {
+	span := tracer.StartSpanFromContext(ctx, "confirmReservationRequestService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	ctx, cancel := detach(ctx)
+	defer cancel()
+
+	declined := s.declineCompetingReservationRequests(reservationRequest, ctx)
+	reservationRequest.Status = model.ACCEPTED
+	s.Repo.AcceptReservationRequest(reservationRequest, ctx)
+
+	metrics.ReservationRequestAccepted(reservationRequest, time.Now())
+	if declined > 0 {
+		metrics.ReservationRequestsDeclined(reservationRequest, metrics.DECLINED_COMPETING, declined)
+	}
+
+	resp, err := client.CreateReservedTerm(*reservationRequest, ctx)
+	if err == nil {
+		reservationRequest.ReservedTermId = resp
+		s.Repo.UpdateReservationRequestReservedTerm(reservationRequest, ctx)
+	} else {
+		tracer.LogError(span, err)
+		s.logger().WarnContext(ctx, "reserving term of accepted reservation request failed", "reservationRequestID", reservationRequest.ID.Hex(), "error", err)
+	}
+}
+
+// declineCompetingReservationRequests declines the SUBMITTED reservation requests competing for any of the local
+// nights of the accepted reservation request and returns how many were declined. A group is only accepted as a
+// whole, so the other members of a competing group are declined with it, while the occurrences of a series are
+// stays of their own and only the competing ones are declined.
+func (s *ReservationRequestService) declineCompetingReservationRequests(reservationRequest *model.ReservationRequest, ctx context.Context) int {
+	competing := s.Repo.FindCompetingReservationRequests(reservationRequest, ctx)
+	if competing == nil {
+		return 0
+	}
+
+	declined := 0
+	declinedGroups := map[primitive.ObjectID]bool{}
+	for i := range *competing {
+		members := []*model.ReservationRequest{&(*competing)[i]}
+		if groupID := (*competing)[i].GroupID; groupID != nil {
+			if declinedGroups[*groupID] {
+				continue
+			}
+			declinedGroups[*groupID] = true
+
+			if group, err := s.findReservationGroup(*groupID, ctx); err == nil {
+				members = group
+			}
+		}
+
+		for _, member := range members {
+			if s.declineReservationRequest(member, model.COMPETING_REQUEST_ACCEPTED, ctx) {
+				declined++
+			}
+		}
+	}
+
+	return declined
+}
+
+// ProcessPendingPayments retries capturing the payments of PAYMENT_PENDING reservation requests whose
+// authorization was pending, and fails the ones whose authorization expired in the meantime.
+func (s *ReservationRequestService) ProcessPendingPayments(ctx context.Context) {
+	span := tracer.StartSpanFromContext(ctx, "processPendingPaymentsService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if s.PaymentProvider == nil {
+		return
+	}
+
+	pendingReservationRequests := s.Repo.FindReservationRequestsByStatus(model.PAYMENT_PENDING, ctx)
+	if pendingReservationRequests == nil {
+		return
+	}
+
+	// the reservation requests of a group share one authorization and are captured together
+	groups := map[primitive.ObjectID][]*model.ReservationRequest{}
+	for i := range *pendingReservationRequests {
+		reservationRequest := &(*pendingReservationRequests)[i]
+		if reservationRequest.Payment == nil {
+			continue
+		}
+
+		if reservationRequest.GroupID != nil {
+			groups[*reservationRequest.GroupID] = append(groups[*reservationRequest.GroupID], reservationRequest)
+			continue
+		}
+
+		if err := s.capturePayment(reservationRequest, ctx); err != nil {
+			tracer.LogError(span, err)
+			s.logger().InfoContext(ctx, "capturing pending payment failed", "reservationRequestID", reservationRequest.ID.Hex(), "error", err)
+		}
+	}
+
+	for groupID, reservationRequests := range groups {
+		if err := s.captureGroupPayment(reservationRequests, ctx); err != nil {
+			tracer.LogError(span, err)
+			s.logger().InfoContext(ctx, "capturing pending group payment failed", "groupID", groupID.Hex(), "error", err)
+		}
+	}
+}
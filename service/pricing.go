@@ -0,0 +1,89 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+)
+
+// CalculatePrice computes the price of a stay starting at startDate and lasting numberOfDays nights,
+// based on the pricing data of the accommodation.
+//
+// Every night starts from the base price of the accommodation, or from the seasonal rate covering it.
+// Friday and Saturday nights are increased by the weekend uplift, and per-guest pricing multiplies the
+// nightly price by the number of guests. The highest length-of-stay discount the stay qualifies for is
+// applied to the subtotal.
+func CalculatePrice(accommodationInfo *model.AccommodationInfo, startDate time.Time, numberOfDays uint, guestNumber uint) (*model.PriceBreakdown, error) {
+	if numberOfDays <= 0 {
+		return nil, errors.New("Number of days must be positive")
+	}
+
+	if guestNumber <= 0 {
+		return nil, errors.New("Number of guests must be positive")
+	}
+
+	if accommodationInfo.MaximumGuests > 0 && (guestNumber < accommodationInfo.MinimimGuests || guestNumber > accommodationInfo.MaximumGuests) {
+		return nil, errors.New("Number of guests is not allowed for given accommodation")
+	}
+
+	pricingType := accommodationInfo.PricingType
+	if pricingType == "" {
+		pricingType = model.PER_NIGHT
+	}
+
+	priceBreakdown := model.PriceBreakdown{
+		PricingType:  pricingType,
+		GuestNumber:  guestNumber,
+		Nights:       []model.NightlyPrice{},
+		CalculatedAt: time.Now(),
+	}
+
+	for i := 0; uint(i) < numberOfDays; i++ {
+		date := startDate.AddDate(0, 0, i)
+
+		nightlyPrice := model.NightlyPrice{Date: date, BasePrice: accommodationInfo.Price}
+		for _, seasonalRate := range accommodationInfo.SeasonalRates {
+			if !date.Before(seasonalRate.StartDate) && date.Before(seasonalRate.EndDate) {
+				nightlyPrice.BasePrice = seasonalRate.Price
+				nightlyPrice.Seasonal = true
+				break
+			}
+		}
+
+		price := nightlyPrice.BasePrice
+		if isWeekendNight(date) && accommodationInfo.WeekendUplift > 0 {
+			nightlyPrice.WeekendUplift = roundPrice(price * accommodationInfo.WeekendUplift / 100)
+			price += nightlyPrice.WeekendUplift
+		}
+
+		if pricingType == model.PER_GUEST {
+			price *= float64(guestNumber)
+		}
+
+		nightlyPrice.Price = roundPrice(price)
+		priceBreakdown.Subtotal += nightlyPrice.Price
+		priceBreakdown.Nights = append(priceBreakdown.Nights, nightlyPrice)
+	}
+
+	for _, discount := range accommodationInfo.LengthOfStayDiscounts {
+		if numberOfDays >= discount.MinimumNights && discount.Percentage > priceBreakdown.DiscountPercentage {
+			priceBreakdown.DiscountPercentage = discount.Percentage
+		}
+	}
+
+	priceBreakdown.Subtotal = roundPrice(priceBreakdown.Subtotal)
+	priceBreakdown.Discount = roundPrice(priceBreakdown.Subtotal * priceBreakdown.DiscountPercentage / 100)
+	priceBreakdown.Total = roundPrice(priceBreakdown.Subtotal - priceBreakdown.Discount)
+
+	return &priceBreakdown, nil
+}
+
+func isWeekendNight(date time.Time) bool {
+	return date.Weekday() == time.Friday || date.Weekday() == time.Saturday
+}
+
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/windbnb/reservation-service/client"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+)
+
+// ReconcileWithAccommodationService compares the confirmed stays with what the accommodation service knows about
+// them: the accommodation has to exist and belong to the host of the stay, and the stay has to have recorded the
+// reserved term holding its dates. The term itself is not looked up in the accommodation service. With repair set
+// the terms that were never recorded are created; the other issues need a person to decide.
+func (s *ReservationRequestService) ReconcileWithAccommodationService(repair bool, ctx context.Context) model.ReconciliationReport {
+	span := tracer.StartSpanFromContext(ctx, "reconcileWithAccommodationServiceService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	report := model.ReconciliationReport{Issues: []model.ReconciliationIssue{}}
+	reservationRequests := s.Repo.FindReservationRequests(model.ReservationRequestFilter{Statuses: model.ActiveStatuses}, ctx)
+	if reservationRequests == nil {
+		return report
+	}
+
+	accommodations := map[uint]*model.AccommodationInfo{}
+	for _, reservationRequest := range *reservationRequests {
+		report.Checked++
+		issue := model.ReconciliationIssue{
+			ReservationRequestID: reservationRequest.ID.Hex(),
+			AccommodationID:      reservationRequest.AccommodationID}
+
+		accommodationInfo, found := accommodations[reservationRequest.AccommodationID]
+		if !found {
+			fetched, err := client.GetAccommodation(reservationRequest.AccommodationID, ctx)
+			if err != nil {
+				// not cached, the next stay in the accommodation tries again
+				issue.Type = model.ACCOMMODATION_UNREACHABLE
+				issue.Detail = err.Error()
+				report.Issues = append(report.Issues, issue)
+				continue
+			}
+
+			accommodationInfo = &fetched
+			accommodations[reservationRequest.AccommodationID] = accommodationInfo
+		}
+
+		switch {
+		case accommodationInfo.Id != reservationRequest.AccommodationID:
+			issue.Type = model.ACCOMMODATION_NOT_FOUND
+		case accommodationInfo.UserID != reservationRequest.OwnerID:
+			issue.Type = model.OWNER_MISMATCH
+			issue.Detail = "accommodation is owned by host " + strconv.FormatUint(uint64(accommodationInfo.UserID), 10)
+		case reservationRequest.ReservedTermId == 0:
+			issue.Type = model.RESERVED_TERM_NOT_RECORDED
+			if repair {
+				issue.Repaired = s.reserveTerm(&reservationRequest, ctx)
+			}
+		default:
+			continue
+		}
+
+		if issue.Repaired {
+			report.Repaired++
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+
+	s.logger().InfoContext(ctx, "reconciled with accommodation service", "checked", report.Checked, "issues", len(report.Issues), "repaired", report.Repaired)
+	return report
+}
+
+// reserveTerm creates the reserved term holding the dates of the reservation request and records it.
+func (s *ReservationRequestService) reserveTerm(reservationRequest *model.ReservationRequest, ctx context.Context) bool {
+	reservedTermId, err := client.CreateReservedTerm(*reservationRequest, ctx)
+	if err != nil || reservedTermId == 0 {
+		s.logger().WarnContext(ctx, "reserving term failed", "reservationRequestID", reservationRequest.ID.Hex(), "error", err)
+		return false
+	}
+
+	reservationRequest.ReservedTermId = reservedTermId
+	return s.Repo.UpdateReservationRequestReservedTerm(reservationRequest, ctx) != nil
+}
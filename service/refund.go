@@ -0,0 +1,48 @@
+package service
+
+import (
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+)
+
+type refundTier struct {
+	minimumDaysBefore int
+	percentage        float64
+}
+
+// refundTiers lists, per cancellation policy, the share of the total that is refunded when the guest
+// cancels at least minimumDaysBefore days before the start of the stay. Tiers are ordered from the
+// earliest cancellation to the latest; cancelling after the last tier refunds nothing.
+var refundTiers = map[model.CancellationPolicy][]refundTier{
+	model.FLEXIBLE: {{minimumDaysBefore: 1, percentage: 100}},
+	model.MODERATE: {{minimumDaysBefore: 5, percentage: 100}, {minimumDaysBefore: 1, percentage: 50}},
+	model.STRICT:   {{minimumDaysBefore: 14, percentage: 100}, {minimumDaysBefore: 7, percentage: 50}},
+}
+
+// CalculateRefund computes how much of the stored price of the reservation request is refunded
+// when it is cancelled at cancelledAt. Reservation requests without a price snapshot refund nothing.
+func CalculateRefund(reservationRequest *model.ReservationRequest, cancelledAt time.Time) *model.Refund {
+	policy := reservationRequest.CancellationPolicy
+	if _, found := refundTiers[policy]; !found {
+		policy = model.FLEXIBLE
+	}
+
+	refund := model.Refund{Policy: policy, CalculatedAt: cancelledAt}
+	if reservationRequest.Price == nil {
+		return &refund
+	}
+
+	percentage := 0.0
+	for _, tier := range refundTiers[policy] {
+		if !cancelledAt.After(reservationRequest.StartDate.AddDate(0, 0, -tier.minimumDaysBefore)) {
+			percentage = tier.percentage
+			break
+		}
+	}
+
+	refund.Amount = roundPrice(reservationRequest.Price.Total * percentage / 100)
+	refund.Penalty = roundPrice(reservationRequest.Price.Total - refund.Amount)
+
+	return &refund
+}
@@ -0,0 +1,224 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"github.com/windbnb/reservation-service/util"
+)
+
+const seedBatchSize = 500
+
+// SeedOptions describes the reservation requests generated for load tests. Accommodations are numbered from 1 and
+// every host owns three of them; guests are numbered after the hosts. Stays check in during the Days days starting
+// at From, and the ones in the past have the statuses a finished stay ends up with. Every reservation request is
+// tagged with Run, so the ones of a run can be removed again.
+type SeedOptions struct {
+	Run            string
+	Count          int
+	Accommodations int
+	Guests         int
+	From           time.Time
+	Days           int
+	TimeZone       string
+	NightlyPrice   float64
+}
+
+// GenerateReservationRequests generates reservation requests the way guests and hosts would have created them, with
+// prices, local dates and statuses consistent with each other. Date blocking stays of the same accommodation never
+// overlap; a stay that would is declined instead. The same random source generates the same reservation requests.
+func GenerateReservationRequests(options SeedOptions, random *rand.Rand, now time.Time) ([]*model.ReservationRequest, error) {
+	if options.Count < 0 || options.Accommodations <= 0 || options.Guests <= 0 || options.Days <= 0 || options.NightlyPrice <= 0 {
+		return nil, errors.New("Seed options must be positive")
+	}
+
+	if options.Run == "" {
+		return nil, errors.New("Seed run must be named")
+	}
+
+	hosts := (options.Accommodations + 2) / 3
+	occupiedNights := map[uint]map[time.Time]bool{}
+	reservationRequests := []*model.ReservationRequest{}
+
+	for i := 0; i < options.Count; i++ {
+		accommodationID := uint(1 + random.Intn(options.Accommodations))
+		accommodationInfo := &model.AccommodationInfo{
+			Id:            accommodationID,
+			MinimimGuests: 1,
+			MaximumGuests: 4,
+			UserID:        uint(1 + (int(accommodationID)-1)/3),
+			Name:          "Seeded accommodation " + strconv.FormatUint(uint64(accommodationID), 10),
+			Price:         options.NightlyPrice,
+			PricingType:   model.PER_NIGHT,
+			WeekendUplift: 15,
+			LengthOfStayDiscounts: []model.LengthOfStayDiscount{
+				{MinimumNights: 7, Percentage: 10}},
+			TimeZone: options.TimeZone}
+
+		numberOfDays := uint(1 + random.Intn(7))
+		if random.Intn(10) == 0 {
+			numberOfDays += 7
+		}
+		checkInDate := options.From.AddDate(0, 0, random.Intn(options.Days))
+		stay, err := newStayDates(accommodationInfo, checkInDate, numberOfDays)
+		if err != nil {
+			return nil, err
+		}
+
+		guestNumber := uint(1 + random.Intn(4))
+		price, err := CalculatePrice(accommodationInfo, stay.CheckInDate, numberOfDays, guestNumber)
+		if err != nil {
+			return nil, err
+		}
+
+		createdAt := stay.CheckIn.AddDate(0, 0, -1-random.Intn(60))
+		if createdAt.After(now) {
+			createdAt = now
+		}
+
+		reservationRequest := &model.ReservationRequest{
+			StartDate:             stay.CheckIn,
+			EndDate:               stay.CheckOut,
+			TimeZone:              stay.TimeZone,
+			CheckInDate:           stay.CheckInDate.Format(util.DateLayout),
+			CheckOutDate:          stay.CheckOutDate.Format(util.DateLayout),
+			AccommodationID:       accommodationID,
+			GuestID:               uint(hosts + 1 + random.Intn(options.Guests)),
+			GuestNumber:           guestNumber,
+			OwnerID:               accommodationInfo.UserID,
+			AcceptReservationType: model.MANUAL,
+			AccommodationName:     accommodationInfo.Name,
+			Price:                 price,
+			CancellationPolicy:    []model.CancellationPolicy{model.FLEXIBLE, model.MODERATE, model.STRICT}[random.Intn(3)],
+			CreatedAt:             createdAt,
+			SeedRun:               options.Run}
+		reservationRequest.Status = seedStatus(reservationRequest, random, now)
+
+		nights := util.Nights(stay.CheckInDate, stay.CheckOutDate)
+		if holdsNights(reservationRequest.Status) {
+			if occupiedNights[accommodationID] == nil {
+				occupiedNights[accommodationID] = map[time.Time]bool{}
+			}
+
+			if anyOccupied(occupiedNights[accommodationID], nights) {
+				reservationRequest.Status = model.DECLINED
+			} else {
+				for _, night := range nights {
+					occupiedNights[accommodationID][night] = true
+				}
+			}
+		}
+
+		switch reservationRequest.Status {
+		case model.CHECKED_IN:
+			reservationRequest.CheckedInAt = &reservationRequest.StartDate
+		case model.COMPLETED:
+			reservationRequest.CheckedInAt = &reservationRequest.StartDate
+			reservationRequest.CheckedOutAt = &reservationRequest.EndDate
+		}
+
+		reservationRequests = append(reservationRequests, reservationRequest)
+	}
+
+	return reservationRequests, nil
+}
+
+// SeedReservationRequests generates the reservation requests and saves them in batches. It returns how many were
+// saved before a batch failed, if one did.
+func (s *ReservationRequestService) SeedReservationRequests(options SeedOptions, random *rand.Rand, ctx context.Context) (int, error) {
+	span := tracer.StartSpanFromContext(ctx, "seedReservationRequestsService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	reservationRequests, err := GenerateReservationRequests(options, random, time.Now())
+	if err != nil {
+		tracer.LogError(span, err)
+		return 0, err
+	}
+
+	saved := 0
+	for start := 0; start < len(reservationRequests); start += seedBatchSize {
+		end := min(start+seedBatchSize, len(reservationRequests))
+		if !s.Repo.SaveReservationRequests(reservationRequests[start:end], ctx) {
+			err := errors.New("Saving seeded reservation requests failed")
+			tracer.LogError(span, err)
+			return saved, err
+		}
+
+		saved = end
+	}
+
+	return saved, nil
+}
+
+// RemoveSeededReservationRequests removes the reservation requests generated by the seed run, or by every seed run
+// when seedRun is empty. Reservation requests guests and hosts created are never tagged, so they are left as they are.
+func (s *ReservationRequestService) RemoveSeededReservationRequests(seedRun string, ctx context.Context) (int, error) {
+	span := tracer.StartSpanFromContext(ctx, "removeSeededReservationRequestsService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	removed, err := s.Repo.DeleteSeededReservationRequests(seedRun, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return 0, errors.New("Removing seeded reservation requests failed")
+	}
+
+	return removed, nil
+}
+
+// seedStatus picks the status a stay with these dates would realistically have by now.
+func seedStatus(reservationRequest *model.ReservationRequest, random *rand.Rand, now time.Time) model.ReservationRequestStatus {
+	roll := random.Intn(100)
+
+	switch {
+	case reservationRequest.EndDate.Before(now):
+		switch {
+		case roll < 70:
+			return model.COMPLETED
+		case roll < 82:
+			return model.CANCELLED
+		case roll < 92:
+			return model.DECLINED
+		case roll < 97:
+			return model.EXPIRED
+		}
+		return model.NO_SHOW
+	case reservationRequest.StartDate.Before(now):
+		if roll < 90 {
+			return model.CHECKED_IN
+		}
+		return model.CANCELLED
+	}
+
+	switch {
+	case roll < 50:
+		return model.ACCEPTED
+	case roll < 75:
+		return model.SUBMITTED
+	case roll < 88:
+		return model.CANCELLED
+	}
+	return model.DECLINED
+}
+
+func holdsNights(status model.ReservationRequestStatus) bool {
+	return status == model.ACCEPTED || status == model.CHECKED_IN || status == model.COMPLETED || status == model.NO_SHOW
+}
+
+func anyOccupied(occupied map[time.Time]bool, nights []time.Time) bool {
+	for _, night := range nights {
+		if occupied[night] {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/windbnb/reservation-service/client"
+	"github.com/windbnb/reservation-service/metrics"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaximumOccurrences is the largest number of stays a single series may expand into.
+const MaximumOccurrences = 52
+
+// SeriesConflictsError is returned when occurrences of a series cannot be booked and the guest did not ask to
+// skip them, and lists why each of them cannot be booked.
+type SeriesConflictsError struct {
+	Conflicts []model.OccurrenceConflict
+}
+
+func (e *SeriesConflictsError) Error() string {
+	return strconv.Itoa(len(e.Conflicts)) + " occurrences of the series cannot be booked"
+}
+
+// SaveReservationSeries expands the recurring stay into its occurrences, validates each of them like a single
+// reservation request and saves the bookable ones together. Occurrences are only skipped when SkipConflicts is set.
+func (s *ReservationRequestService) SaveReservationSeries(createReservationRequest *model.CreateReservationRequest, ctx context.Context) (*model.ReservationSeries, error) {
+	span := tracer.StartSpanFromContext(ctx, "saveReservationSeriesService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if createReservationRequest.NumberOfDays <= 0 {
+		return nil, errors.New("Number of days must be positive")
+	}
+
+	checkInDate, err := requestedCheckInDate(createReservationRequest.CheckInDate, createReservationRequest.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	checkInDates, err := expandRecurrence(checkInDate, createReservationRequest.NumberOfDays, createReservationRequest.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	accommodationInfo, err := client.GetAccommodation(createReservationRequest.AccommodationID, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	seriesID := primitive.NewObjectID()
+	reservationRequests := []*model.ReservationRequest{}
+	conflicts := []model.OccurrenceConflict{}
+	for _, occurrenceDate := range checkInDates {
+		reservationRequest, err := s.newReservationRequestForStay(&accommodationInfo, createReservationRequest, occurrenceDate, ctx)
+		if err != nil {
+			conflict := model.OccurrenceConflict{CheckInDate: occurrenceDate.Format(util.DateLayout), Message: err.Error()}
+			var violationsError *BookingRuleViolationsError
+			if errors.As(err, &violationsError) {
+				conflict.Violations = violationsError.Violations
+			}
+			conflicts = append(conflicts, conflict)
+			continue
+		}
+
+		reservationRequest.SeriesID = &seriesID
+		reservationRequests = append(reservationRequests, reservationRequest)
+	}
+
+	if len(reservationRequests) == 0 || (len(conflicts) > 0 && !createReservationRequest.SkipConflicts) {
+		return nil, &SeriesConflictsError{Conflicts: conflicts}
+	}
+
+	if !s.Repo.SaveReservationRequests(reservationRequests, ctx) {
+		tracer.LogError(span, errors.New("It's not possible to save reservation series"))
+		return nil, errors.New("It's not possible to save reservation series")
+	}
+
+	for _, reservationRequest := range reservationRequests {
+		metrics.ReservationRequestCreated(reservationRequest, len(stayNights(reservationRequest)))
+	}
+
+	// every occurrence is a stay of its own and is paid for separately
+	if accommodationInfo.AcceptReservationType == model.AUTOMATICALLY {
+		for _, reservationRequest := range reservationRequests {
+			if err := s.processPayment(reservationRequest, ctx); err != nil {
+				tracer.LogError(span, err)
+				s.logger().InfoContext(ctx, "payment of series occurrence failed", "reservationRequestID", reservationRequest.ID.Hex(), "error", err)
+			}
+		}
+	}
+
+	reservationSeries := newReservationSeries(seriesID, reservationRequests)
+	reservationSeries.Conflicts = conflicts
+	return reservationSeries, nil
+}
+
+// GetReservationSeries returns the series to its guest or its host.
+func (s *ReservationRequestService) GetReservationSeries(seriesID primitive.ObjectID, userID uint, ctx context.Context) (*model.ReservationSeries, error) {
+	span := tracer.StartSpanFromContext(ctx, "getReservationSeriesService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	reservationRequests, err := s.findReservationSeries(seriesID, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	if reservationRequests[0].GuestID != userID && reservationRequests[0].OwnerID != userID {
+		tracer.LogError(span, errors.New("You can not access to this entity"))
+		return nil, errors.New("You can not access to this entity")
+	}
+
+	return newReservationSeries(seriesID, reservationRequests), nil
+}
+
+// CancelReservationSeries cancels the accepted occurrences of the series that did not start yet and withdraws
+// the ones the host did not respond to. Past and already cancelled occurrences are left as they are.
+func (s *ReservationRequestService) CancelReservationSeries(seriesID primitive.ObjectID, guestID uint, ctx context.Context) (*model.ReservationSeries, error) {
+	span := tracer.StartSpanFromContext(ctx, "cancelReservationSeriesService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	reservationRequests, err := s.findReservationSeries(seriesID, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	if reservationRequests[0].GuestID != guestID {
+		tracer.LogError(span, errors.New("You can not access to this entity"))
+		return nil, errors.New("You can not access to this entity")
+	}
+
+	ctx, cancel, err := beginStateChange(ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+	defer cancel()
+
+	remaining := []*model.ReservationRequest{}
+	changed := 0
+	for _, reservationRequest := range reservationRequests {
+		switch {
+		case reservationRequest.Status == model.ACCEPTED && reservationRequest.StartDate.After(time.Now()):
+			s.cancelReservationRequest(reservationRequest, ctx)
+			changed++
+		case reservationRequest.Status == model.SUBMITTED:
+			if s.Repo.DeleteReservationRequest(reservationRequest.ID, ctx) {
+				metrics.ReservationRequestDeleted(reservationRequest)
+				changed++
+				continue
+			}
+			tracer.LogError(span, errors.New("It's not possible to delete reservation request - repo error."))
+			s.logger().WarnContext(ctx, "withdrawing series occurrence failed", "reservationRequestID", reservationRequest.ID.Hex())
+		}
+
+		remaining = append(remaining, reservationRequest)
+	}
+
+	if changed == 0 {
+		tracer.LogError(span, errors.New("You cannot cancel given reservation series - wrong status"))
+		return nil, errors.New("You cannot cancel given reservation series - wrong status")
+	}
+
+	return newReservationSeries(seriesID, remaining), nil
+}
+
+func (s *ReservationRequestService) findReservationSeries(seriesID primitive.ObjectID, ctx context.Context) ([]*model.ReservationRequest, error) {
+	found := s.Repo.FindSeriesReservationRequests(seriesID, ctx)
+	if found == nil || len(*found) == 0 {
+		return nil, errors.New("Given reservation series does not exist")
+	}
+
+	reservationRequests := []*model.ReservationRequest{}
+	for i := range *found {
+		reservationRequests = append(reservationRequests, &(*found)[i])
+	}
+
+	return reservationRequests, nil
+}
+
+// expandRecurrence returns the local check-in dates of the occurrences of the recurring stay starting on checkInDate.
+func expandRecurrence(checkInDate time.Time, numberOfDays uint, recurrence *model.RecurrenceSpec) ([]time.Time, error) {
+	if recurrence.Frequency != model.WEEKLY && recurrence.Frequency != model.MONTHLY {
+		return nil, errors.New("Recurrence frequency must be WEEKLY or MONTHLY")
+	}
+
+	if recurrence.Count == 0 && recurrence.Until == "" {
+		return nil, errors.New("Recurrence needs a count or an end date")
+	}
+
+	if recurrence.Count > MaximumOccurrences {
+		return nil, errors.New("Series cannot have more than " + strconv.Itoa(MaximumOccurrences) + " occurrences")
+	}
+
+	interval := int(recurrence.Interval)
+	if interval == 0 {
+		interval = 1
+	}
+
+	until := time.Time{}
+	if recurrence.Until != "" {
+		parsed, err := time.Parse(util.DateLayout, recurrence.Until)
+		if err != nil {
+			return nil, errors.New("Recurrence end date must be a date")
+		}
+		until = parsed
+	}
+
+	checkInDates := []time.Time{}
+	for i := 0; recurrence.Count == 0 || i < int(recurrence.Count); i++ {
+		occurrenceDate := checkInDate.AddDate(0, 0, 7*interval*i)
+		if recurrence.Frequency == model.MONTHLY {
+			occurrenceDate = addMonths(checkInDate, interval*i)
+		}
+
+		if !until.IsZero() && occurrenceDate.After(until) {
+			break
+		}
+
+		if len(checkInDates) == MaximumOccurrences {
+			return nil, errors.New("Series cannot have more than " + strconv.Itoa(MaximumOccurrences) + " occurrences")
+		}
+
+		if len(checkInDates) > 0 && checkInDates[len(checkInDates)-1].AddDate(0, 0, int(numberOfDays)).After(occurrenceDate) {
+			return nil, errors.New("Occurrences of a series cannot overlap")
+		}
+
+		checkInDates = append(checkInDates, occurrenceDate)
+	}
+
+	return checkInDates, nil
+}
+
+// addMonths moves the date by months, keeping its day of the month unless the target month is shorter.
+func addMonths(date time.Time, months int) time.Time {
+	firstOfMonth := time.Date(date.Year(), date.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	day := date.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(firstOfMonth.Year(), firstOfMonth.Month(), day, 0, 0, 0, 0, time.UTC)
+}
+
+func newReservationSeries(seriesID primitive.ObjectID, reservationRequests []*model.ReservationRequest) *model.ReservationSeries {
+	reservationSeries := model.ReservationSeries{ID: seriesID}
+	for _, reservationRequest := range reservationRequests {
+		reservationSeries.ReservationRequests = append(reservationSeries.ReservationRequests, *reservationRequest)
+	}
+
+	return &reservationSeries
+}
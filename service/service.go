@@ -2,92 +2,245 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
-	"os"
+	"log/slog"
 	"time"
 
 	"github.com/windbnb/reservation-service/client"
+	"github.com/windbnb/reservation-service/logging"
+	"github.com/windbnb/reservation-service/metrics"
 	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/payment"
 	"github.com/windbnb/reservation-service/repository"
 	"github.com/windbnb/reservation-service/tracer"
+	"github.com/windbnb/reservation-service/util"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-var accommodationServiceUrl = os.Getenv("accommodationServiceUrl") + "/api/accomodation/"
-
 type ReservationRequestService struct {
-	Repo repository.IRepository
+	Repo            repository.IRepository
+	PaymentProvider payment.PaymentProvider
+	// ResponseWindow is how long hosts have to respond to SUBMITTED reservation requests before they expire.
+	ResponseWindow time.Duration
+	// ReviewWindow is how long after a completed stay the guest may still review it.
+	ReviewWindow time.Duration
+	// CalendarFeedSecret signs the tokens of the iCalendar feed URLs; feeds are disabled when it is empty.
+	CalendarFeedSecret string
+	// CalendarImportDir is the only directory calendars may be imported from with file URLs.
+	CalendarImportDir string
+	// CalendarImportHosts are the only hosts calendars may be imported from with http(s) URLs.
+	CalendarImportHosts []string
+	Logger              *slog.Logger
+}
+
+func (s *ReservationRequestService) logger() *slog.Logger {
+	return logging.OrDefault(s.Logger)
+}
+
+// stateChangeTimeout bounds the writes of a state change once its first one was written.
+const stateChangeTimeout = 30 * time.Second
+
+// beginStateChange honors the cancellation of ctx while nothing was written yet. Otherwise it returns the context
+// the whole state change is written with, which the caller giving up no longer cancels, so a cancellation is not
+// left without its refund or outbox event, nor a captured payment without its acceptance.
+func beginStateChange(ctx context.Context) (context.Context, context.CancelFunc, error) {
+	if err := ctx.Err(); err != nil {
+		return ctx, func() {}, err
+	}
+
+	ctx, cancel := detach(ctx)
+	return ctx, cancel, nil
+}
+
+// detach keeps the values of ctx, the span among them, but not its cancellation.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), stateChangeTimeout)
 }
 
 func (s *ReservationRequestService) SaveReservationRequest(createReservationRequest *model.CreateReservationRequest, ctx context.Context) (*model.ReservationRequest, error) {
 	span := tracer.StartSpanFromContext(ctx, "saveReservationRequestService")
-	defer span.Finish()
+	defer span.End()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
-	if createReservationRequest.StartDate.Before(time.Now()) {
-		return nil, errors.New("Start date cannot be in past")
+	reservationRequest, accommodationInfo, err := s.newReservationRequest(createReservationRequest, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	ctx, cancel, err := beginStateChange(ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
 	}
+	defer cancel()
+
+	s.Repo.SaveReservationRequest(reservationRequest, ctx)
+	metrics.ReservationRequestCreated(reservationRequest, len(stayNights(reservationRequest)))
+
+	if accommodationInfo.AcceptReservationType == model.AUTOMATICALLY {
+		err = s.processPayment(reservationRequest, ctx)
+		if err != nil {
+			tracer.LogError(span, err)
+			return nil, err
+		}
+	}
+
+	return reservationRequest, nil
+}
+
+// newReservationRequest validates the reservation request against the accommodation and builds it as SUBMITTED
+// without saving it.
+func (s *ReservationRequestService) newReservationRequest(createReservationRequest *model.CreateReservationRequest, ctx context.Context) (*model.ReservationRequest, *model.AccommodationInfo, error) {
+	span := tracer.StartSpanFromContext(ctx, "newReservationRequestService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	if createReservationRequest.NumberOfDays <= 0 {
-		return nil, errors.New("Number of days must be positive")
+		return nil, nil, errors.New("Number of days must be positive")
 	}
 
-	accommodationInfo, err := client.GetAccommodation(createReservationRequest.AccommodationID)
+	checkInDate, err := requestedCheckInDate(createReservationRequest.CheckInDate, createReservationRequest.StartDate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	accommodationInfo, err := client.GetAccommodation(createReservationRequest.AccommodationID, ctx)
 	if err != nil {
 		tracer.LogError(span, err)
+		return nil, nil, err
+	}
+
+	reservationRequest, err := s.newReservationRequestForStay(&accommodationInfo, createReservationRequest, checkInDate, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return reservationRequest, &accommodationInfo, nil
+}
+
+// newReservationRequestForStay validates the stay checking in on the local date checkInDate against the
+// accommodation and builds it as SUBMITTED without saving it.
+func (s *ReservationRequestService) newReservationRequestForStay(accommodationInfo *model.AccommodationInfo, createReservationRequest *model.CreateReservationRequest, checkInDate time.Time, ctx context.Context) (*model.ReservationRequest, error) {
+	span := tracer.StartSpanFromContext(ctx, "newReservationRequestForStayService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	stay, err := newStayDates(accommodationInfo, checkInDate, createReservationRequest.NumberOfDays)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	if stay.CheckIn.Before(time.Now()) {
+		return nil, errors.New("Start date cannot be in past")
+	}
+
+	if err := s.checkBookingRules(createReservationRequest.AccommodationID, stay.CheckIn, createReservationRequest.NumberOfDays, ctx); err != nil {
 		return nil, err
 	}
 
-	for i := 0; uint(i) < createReservationRequest.NumberOfDays; i++ {
-		if !s.isDateInAvailableTerms(createReservationRequest.StartDate.AddDate(0, 0, i), accommodationInfo.AvailableTerms, ctx) {
+	for _, night := range util.Nights(stay.CheckInDate, stay.CheckOutDate) {
+		if !s.isDateInAvailableTerms(night, accommodationInfo.AvailableTerms, ctx) {
 			return nil, errors.New("Accommodation is not available")
 		}
 	}
 
-	var endDate = createReservationRequest.StartDate.AddDate(0, 0, int(createReservationRequest.NumberOfDays))
+	if s.isBlockedByImportedCalendar(createReservationRequest.AccommodationID, stay.CheckInDate, stay.CheckOutDate, ctx) {
+		return nil, errors.New("Accommodation is not available")
+	}
 
-	acceptedReservationRequests := s.Repo.FindAcceptedReservationRequests(createReservationRequest.AccommodationID, ctx)
-	for _, acceptedReservationRequest := range *acceptedReservationRequests {
-		if createReservationRequest.StartDate.Before(acceptedReservationRequest.EndDate) && acceptedReservationRequest.StartDate.Before(endDate) {
-			return nil, errors.New("Accomodation is reserved already")
-		}
+	if s.isReservedAlready(createReservationRequest.AccommodationID, stay.CheckInDate, stay.CheckOutDate, ctx) {
+		return nil, errors.New("Accomodation is reserved already")
 	}
 
-	var status = model.SUBMITTED
-	if accommodationInfo.AcceptReservationType == model.AUTOMATICALLY {
-		status = model.ACCEPTED
+	price, err := CalculatePrice(accommodationInfo, stay.CheckInDate, createReservationRequest.NumberOfDays, createReservationRequest.GuestNumber)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
 	}
 
 	var reservationRequest = model.ReservationRequest{
-		StartDate:         createReservationRequest.StartDate,
-		EndDate:           createReservationRequest.StartDate.AddDate(0, 0, int(createReservationRequest.NumberOfDays)),
-		GuestID:           createReservationRequest.GuestID,
-		GuestNumber:       createReservationRequest.GuestNumber,
-		Status:            status,
-		AccommodationID:   createReservationRequest.AccommodationID,
-		OwnerID:           accommodationInfo.UserID,
-		AccommodationName: accommodationInfo.Name}
-
-	s.Repo.SaveReservationRequest(&reservationRequest, ctx)
-
-	if status == model.ACCEPTED {
-		resp, err := client.CreateReservedTerm(reservationRequest)
-		if err == nil {
-			reservationRequest.ReservedTermId = resp
-			s.Repo.UpdateReservationRequestReservedTerm(&reservationRequest, ctx)
+		StartDate:             stay.CheckIn,
+		EndDate:               stay.CheckOut,
+		TimeZone:              stay.TimeZone,
+		CheckInDate:           stay.CheckInDate.Format(util.DateLayout),
+		CheckOutDate:          stay.CheckOutDate.Format(util.DateLayout),
+		GuestID:               createReservationRequest.GuestID,
+		GuestNumber:           createReservationRequest.GuestNumber,
+		Status:                model.SUBMITTED,
+		AccommodationID:       createReservationRequest.AccommodationID,
+		OwnerID:               accommodationInfo.UserID,
+		AcceptReservationType: accommodationInfo.AcceptReservationType,
+		AccommodationName:     accommodationInfo.Name,
+		Price:                 price,
+		CancellationPolicy:    accommodationInfo.CancellationPolicy,
+		CreatedAt:             time.Now()}
+
+	return &reservationRequest, nil
+}
+
+// isReservedAlready reports whether a date blocking reservation request of the accommodation holds any night
+// between the local dates checkInDate and checkOutDate.
+func (s *ReservationRequestService) isReservedAlready(accommodationID uint, checkInDate time.Time, checkOutDate time.Time, ctx context.Context) bool {
+	acceptedReservationRequests := s.Repo.FindAcceptedReservationRequests(accommodationID, ctx)
+	if acceptedReservationRequests == nil {
+		return false
+	}
+
+	for _, acceptedReservationRequest := range *acceptedReservationRequests {
+		acceptedCheckInDate, acceptedCheckOutDate := reservationPeriod(&acceptedReservationRequest)
+		if checkInDate.Before(acceptedCheckOutDate) && acceptedCheckInDate.Before(checkOutDate) {
+			return true
 		}
 	}
 
-	return &reservationRequest, nil
+	return false
+}
+
+func (s *ReservationRequestService) QuoteReservationRequest(createReservationRequest *model.CreateReservationRequest, ctx context.Context) (*model.PriceBreakdown, error) {
+	span := tracer.StartSpanFromContext(ctx, "quoteReservationRequestService")
+	defer span.End()
+
+	checkInDate, err := requestedCheckInDate(createReservationRequest.CheckInDate, createReservationRequest.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	accommodationInfo, err := client.GetAccommodation(createReservationRequest.AccommodationID, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
 
+	stay, err := newStayDates(&accommodationInfo, checkInDate, createReservationRequest.NumberOfDays)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	if stay.CheckIn.Before(time.Now()) {
+		return nil, errors.New("Start date cannot be in past")
+	}
+
+	price, err := CalculatePrice(&accommodationInfo, stay.CheckInDate, createReservationRequest.NumberOfDays, createReservationRequest.GuestNumber)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	return price, nil
 }
 
 func (s *ReservationRequestService) isDateInAvailableTerms(date time.Time, availableTerms []model.AvailableTerm, ctx context.Context) bool {
 	span := tracer.StartSpanFromContext(ctx, "isDateInAvailableTermsService")
-	defer span.Finish()
+	defer span.End()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	for _, availableTerm := range availableTerms {
 		if (availableTerm.StartDate.Before(date) || availableTerm.StartDate.Equal(date)) && availableTerm.EndDate.After(date) {
@@ -100,65 +253,63 @@ func (s *ReservationRequestService) isDateInAvailableTerms(date time.Time, avail
 
 func (s *ReservationRequestService) GetWheatherGuestWasWithHost(guestID uint, ownerID uint, ctx context.Context) bool {
 	span := tracer.StartSpanFromContext(ctx, "getWheatherGuestWasWithHost")
-	defer span.Finish()
+	defer span.End()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	return s.Repo.FindGuestWithHost(guestID, ownerID, ctx)
 }
 
 func (s *ReservationRequestService) GetWheatherGuestWasInAccomodation(guestID uint, accomodationId uint, ctx context.Context) bool {
 	span := tracer.StartSpanFromContext(ctx, "getWheatherGuestWasInAccomodation")
-	defer span.Finish()
+	defer span.End()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	return s.Repo.FindGuestInAccomodation(guestID, accomodationId, ctx)
 }
 
-
 func (s *ReservationRequestService) GetGuestActiveReservations(guestID uint, ctx context.Context) *[]model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "getGuestActiveReservationsService")
-	defer span.Finish()
+	defer span.End()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	return s.Repo.FindGuestsActive(guestID, ctx)
 }
 
 func (s *ReservationRequestService) GetGuestAllReservations(guestID uint, ctx context.Context) *[]model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "getGuestAllReservationsService")
-	defer span.Finish()
+	defer span.End()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	return s.Repo.FindGuestsAllReservations(guestID, ctx)
 }
 
-
 func (s *ReservationRequestService) GetOwnersActiveReservations(ownerID uint, ctx context.Context) *[]model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "getOwnersActiveReservationsService")
-	defer span.Finish()
+	defer span.End()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	return s.Repo.FindOwnersActive(ownerID, ctx)
 }
 
 func (s *ReservationRequestService) GetOwnersAllReservations(ownerID uint, ctx context.Context, status []model.ReservationRequestStatus) *[]model.ReservationRequest {
 	span := tracer.StartSpanFromContext(ctx, "getOwnersAllReservationsService")
-	defer span.Finish()
+	defer span.End()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	return s.Repo.FindOwnersReservations(ownerID, ctx, status)
 }
 
 func (s *ReservationRequestService) DeleteReservationRequest(reservationRequestID primitive.ObjectID, userID uint, ctx context.Context) error {
 	span := tracer.StartSpanFromContext(ctx, "deleteReservationRequestService")
-	defer span.Finish()
+	defer span.End()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	reservationRequest := s.Repo.FindReservationRequest(reservationRequestID, ctx)
 
@@ -182,15 +333,16 @@ func (s *ReservationRequestService) DeleteReservationRequest(reservationRequestI
 		tracer.LogError(span, errors.New("It's not possible to delete reservation request - repo error."))
 		return errors.New("It's not possible to delete reservation request")
 	}
+	metrics.ReservationRequestDeleted(reservationRequest)
 
 	return nil
 }
 
 func (s *ReservationRequestService) AcceptReservationRequest(reservationRequestId primitive.ObjectID, hostId uint, ctx context.Context) (*model.ReservationRequest, error) {
 	span := tracer.StartSpanFromContext(ctx, "acceptReservationRequestService")
-	defer span.Finish()
+	defer span.End()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	reservationRequest := s.Repo.FindReservationRequest(reservationRequestId, ctx)
 	if reservationRequest == nil {
@@ -208,15 +360,28 @@ func (s *ReservationRequestService) AcceptReservationRequest(reservationRequestI
 		return nil, errors.New("You cannot update given reservation request - wrong status.")
 	}
 
-	reservationRequest.Status = model.ACCEPTED
-	s.Repo.AcceptReservationRequest(reservationRequest, ctx)
+	if reservationRequest.GroupID != nil {
+		tracer.LogError(span, errors.New("Reservation request belongs to a group - accept the whole group."))
+		return nil, errors.New("Reservation request belongs to a group - accept the whole group.")
+	}
 
-	resp, err := client.CreateReservedTerm(*reservationRequest)
-	if err == nil {
-		reservationRequest.ReservedTermId = resp
-		s.Repo.UpdateReservationRequestReservedTerm(reservationRequest, ctx)
-	} else {
+	checkInDate, checkOutDate := reservationPeriod(reservationRequest)
+	if s.isReservedAlready(reservationRequest.AccommodationID, checkInDate, checkOutDate, ctx) {
+		tracer.LogError(span, errors.New("Accomodation is reserved already"))
+		return nil, errors.New("Accomodation is reserved already")
+	}
+
+	ctx, cancel, err := beginStateChange(ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+	defer cancel()
+
+	err = s.processPayment(reservationRequest, ctx)
+	if err != nil {
 		tracer.LogError(span, err)
+		return nil, err
 	}
 
 	return reservationRequest, nil
@@ -224,9 +389,9 @@ func (s *ReservationRequestService) AcceptReservationRequest(reservationRequestI
 
 func (s *ReservationRequestService) CancelReservationRequest(reservationRequestId primitive.ObjectID, guestId uint, ctx context.Context) (*model.ReservationRequest, error) {
 	span := tracer.StartSpanFromContext(ctx, "cancelReservationRequestService")
-	defer span.Finish()
+	defer span.End()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	reservationRequest := s.Repo.FindReservationRequest(reservationRequestId, ctx)
 	if reservationRequest == nil {
@@ -234,42 +399,152 @@ func (s *ReservationRequestService) CancelReservationRequest(reservationRequestI
 		return nil, errors.New("Given reservation request does not exist")
 	}
 
-	if reservationRequest.GuestID != guestId {
-		tracer.LogError(span, errors.New("You can not access to this entity"))
-		return nil, errors.New("You can not access to this entity")
+	if err := checkCancellable(reservationRequest, guestId); err != nil {
+		tracer.LogError(span, err)
+		return nil, err
 	}
 
-	if reservationRequest.Status != model.ACCEPTED {
-		tracer.LogError(span, errors.New("You cannot cancel given reservation request - wrong status"))
-		return nil, errors.New("You cannot cancel given reservation request - wrong status")
+	// the reservation requests of a group share one authorization, voiding it would fail the payment of the others
+	if reservationRequest.Status == model.PAYMENT_PENDING && reservationRequest.GroupID != nil {
+		tracer.LogError(span, errors.New("Reservation request belongs to a group with a pending payment - cancel the whole group."))
+		return nil, errors.New("Reservation request belongs to a group with a pending payment - cancel the whole group.")
 	}
 
-	if reservationRequest.StartDate.Before(time.Now().AddDate(0, -1, 0)) {
-		tracer.LogError(span, errors.New("It's not possible to cancel reservation"))
-		return nil, errors.New("It is not possible to cancel reservation.")
+	ctx, cancel, err := beginStateChange(ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
 	}
+	defer cancel()
+
+	s.cancelReservationRequest(reservationRequest, ctx)
+
+	return reservationRequest, nil
+}
+
+// cancelReservationRequest cancels the reservation request, refunds it by its cancellation policy and frees its dates.
+// Nothing was charged for a reservation request whose payment is pending, so its authorization is voided instead.
+func (s *ReservationRequestService) cancelReservationRequest(reservationRequest *model.ReservationRequest, ctx context.Context) {
+	span := tracer.StartSpanFromContext(ctx, "cancelReservationRequestService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	ctx, cancel := detach(ctx)
+	defer cancel()
+
+	paymentPending := reservationRequest.Status == model.PAYMENT_PENDING
 
 	reservationRequest.Status = model.CANCELLED
 	s.Repo.UpdateReservationRequestStatus(reservationRequest, ctx)
+	metrics.ReservationRequestCancelled(reservationRequest)
+
+	reservationRequest.Refund = CalculateRefund(reservationRequest, time.Now())
+	if paymentPending {
+		s.voidPayment(reservationRequest, ctx)
+		reservationRequest.Refund.Amount, reservationRequest.Refund.Penalty = 0, 0
+	}
+	s.Repo.UpdateReservationRequestRefund(reservationRequest, ctx)
+
+	authorizationID := ""
+	if reservationRequest.Payment != nil {
+		authorizationID = reservationRequest.Payment.AuthorizationID
+	}
 
-	resp, err := client.CreateReservedTerm(*reservationRequest)
+	s.saveEvent(model.RESERVATION_CANCELLED, model.ReservationCancelledEvent{
+		ReservationRequestID: reservationRequest.ID.Hex(),
+		GuestID:              reservationRequest.GuestID,
+		OwnerID:              reservationRequest.OwnerID,
+		AccommodationID:      reservationRequest.AccommodationID,
+		RefundAmount:         reservationRequest.Refund.Amount,
+		Penalty:              reservationRequest.Refund.Penalty,
+		CancellationPolicy:   reservationRequest.Refund.Policy,
+		AuthorizationID:      authorizationID,
+	}, ctx)
+
+	resp, err := client.CreateReservedTerm(*reservationRequest, ctx)
 	if err == nil {
 		reservationRequest.ReservedTermId = resp
 		s.Repo.UpdateReservationRequestReservedTerm(reservationRequest, ctx)
 	} else {
 		tracer.LogError(span, err)
+		s.logger().WarnContext(ctx, "reserving term of cancelled reservation request failed", "reservationRequestID", reservationRequest.ID.Hex(), "error", err)
 	}
 
-	client.DeleteReservedTerm(reservationRequest.ReservedTermId)
+	client.DeleteReservedTerm(reservationRequest.ReservedTermId, ctx)
 
-	return reservationRequest, nil
+	s.notifyWaitlist(reservationRequest, ctx)
+}
+
+// checkCancellable reports why the guest may not cancel the reservation request, if they may not.
+func checkCancellable(reservationRequest *model.ReservationRequest, guestID uint) error {
+	if reservationRequest.GuestID != guestID {
+		return errors.New("You can not access to this entity")
+	}
+
+	if reservationRequest.Status != model.ACCEPTED && reservationRequest.Status != model.PAYMENT_PENDING {
+		return errors.New("You cannot cancel given reservation request - wrong status")
+	}
+
+	if reservationRequest.StartDate.Before(time.Now().AddDate(0, -1, 0)) {
+		return errors.New("It is not possible to cancel reservation.")
+	}
+
+	return nil
 }
 
 func (s *ReservationRequestService) CountCancelledReservations(guestId uint, ctx context.Context) int {
 	span := tracer.StartSpanFromContext(ctx, "countCancelledReservationsService")
-	defer span.Finish()
+	defer span.End()
 
-	ctx = tracer.ContextWithSpan(context.Background(), span)
+	ctx = tracer.ContextWithSpan(ctx, span)
 
 	return s.Repo.CountGuestsCancelled(guestId, ctx)
 }
+
+// declineReservationRequest declines the reservation request while it is still SUBMITTED and tells its guest, and
+// reports whether it did.
+func (s *ReservationRequestService) declineReservationRequest(reservationRequest *model.ReservationRequest, reason model.DeclineReason, ctx context.Context) bool {
+	if !s.Repo.TransitionReservationRequestStatus(reservationRequest, model.SUBMITTED, model.DECLINED, ctx) {
+		return false
+	}
+
+	declinedEvent := model.ReservationDeclinedEvent{
+		ReservationRequestID: reservationRequest.ID.Hex(),
+		GuestID:              reservationRequest.GuestID,
+		OwnerID:              reservationRequest.OwnerID,
+		AccommodationID:      reservationRequest.AccommodationID,
+		StartDate:            reservationRequest.StartDate,
+		EndDate:              reservationRequest.EndDate,
+		Reason:               reason,
+	}
+	if reservationRequest.GroupID != nil {
+		declinedEvent.GroupID = reservationRequest.GroupID.Hex()
+	}
+	if reservationRequest.SeriesID != nil {
+		declinedEvent.SeriesID = reservationRequest.SeriesID.Hex()
+	}
+	s.saveEvent(model.RESERVATION_DECLINED, declinedEvent, ctx)
+
+	return true
+}
+
+func (s *ReservationRequestService) saveEvent(eventType model.EventType, payload interface{}, ctx context.Context) {
+	span := tracer.StartSpanFromContext(ctx, "saveEventService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	marshalled, err := json.Marshal(payload)
+	if err != nil {
+		tracer.LogError(span, err)
+		s.logger().ErrorContext(ctx, "encoding event failed, it will not be published", "eventType", eventType, "error", err)
+		return
+	}
+
+	event := s.Repo.SaveEvent(&model.Event{Type: eventType, Payload: string(marshalled), CreatedAt: time.Now()}, ctx)
+	if event == nil {
+		tracer.LogError(span, errors.New("It's not possible to save event "+string(eventType)))
+		s.logger().ErrorContext(ctx, "saving event failed, it will not be published", "eventType", eventType)
+	}
+}
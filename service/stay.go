@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func (s *ReservationRequestService) CheckIn(reservationRequestId primitive.ObjectID, hostId uint, ctx context.Context) (*model.ReservationRequest, error) {
+	span := tracer.StartSpanFromContext(ctx, "checkInService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	reservationRequest, err := s.findHostsReservationRequest(reservationRequestId, hostId, []model.ReservationRequestStatus{model.ACCEPTED}, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if now.Before(util.StartOfDay(reservationRequest.StartDate.In(util.LoadLocation(reservationRequest.TimeZone)))) {
+		tracer.LogError(span, errors.New("Guest cannot check in before the stay starts"))
+		return nil, errors.New("Guest cannot check in before the stay starts")
+	}
+
+	if now.After(reservationRequest.EndDate) {
+		tracer.LogError(span, errors.New("Guest cannot check in after the stay ended"))
+		return nil, errors.New("Guest cannot check in after the stay ended")
+	}
+
+	reservationRequest.Status = model.CHECKED_IN
+	reservationRequest.CheckedInAt = &now
+	s.Repo.UpdateReservationRequestStay(reservationRequest, ctx)
+
+	return reservationRequest, nil
+}
+
+// CheckOut completes a checked in stay. A stay that ended without the host checking the guest in can be checked out
+// as well, before CompleteFinishedStays completes it.
+func (s *ReservationRequestService) CheckOut(reservationRequestId primitive.ObjectID, hostId uint, ctx context.Context) (*model.ReservationRequest, error) {
+	span := tracer.StartSpanFromContext(ctx, "checkOutService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	reservationRequest, err := s.findHostsReservationRequest(reservationRequestId, hostId, []model.ReservationRequestStatus{model.CHECKED_IN, model.ACCEPTED}, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	now := time.Now()
+	if reservationRequest.Status == model.ACCEPTED && now.Before(reservationRequest.EndDate) {
+		tracer.LogError(span, errors.New("Guest has to be checked in before the stay ends"))
+		return nil, errors.New("Guest has to be checked in before the stay ends")
+	}
+
+	reservationRequest.Status = model.COMPLETED
+	reservationRequest.CheckedOutAt = &now
+	s.Repo.UpdateReservationRequestStay(reservationRequest, ctx)
+
+	return reservationRequest, nil
+}
+
+func (s *ReservationRequestService) MarkNoShow(reservationRequestId primitive.ObjectID, hostId uint, ctx context.Context) (*model.ReservationRequest, error) {
+	span := tracer.StartSpanFromContext(ctx, "markNoShowService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	reservationRequest, err := s.findHostsReservationRequest(reservationRequestId, hostId, []model.ReservationRequestStatus{model.ACCEPTED}, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	if time.Now().Before(reservationRequest.StartDate) {
+		tracer.LogError(span, errors.New("Guest cannot be marked as no-show before the stay starts"))
+		return nil, errors.New("Guest cannot be marked as no-show before the stay starts")
+	}
+
+	reservationRequest.Status = model.NO_SHOW
+	s.Repo.UpdateReservationRequestStay(reservationRequest, ctx)
+
+	return reservationRequest, nil
+}
+
+// CompleteFinishedStays completes the accepted and checked in stays whose end date passed, so the ones the host never
+// checked in or out are not left active. It returns the number of completed stays.
+func (s *ReservationRequestService) CompleteFinishedStays(ctx context.Context) int {
+	span := tracer.StartSpanFromContext(ctx, "completeFinishedStaysService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	return s.Repo.CompleteFinishedStays(time.Now(), ctx)
+}
+
+func (s *ReservationRequestService) findHostsReservationRequest(reservationRequestId primitive.ObjectID, hostId uint, statuses []model.ReservationRequestStatus, ctx context.Context) (*model.ReservationRequest, error) {
+	reservationRequest := s.Repo.FindReservationRequest(reservationRequestId, ctx)
+	if reservationRequest == nil {
+		return nil, errors.New("Given reservation request does not exist")
+	}
+
+	if reservationRequest.OwnerID != hostId {
+		return nil, errors.New("You can not access to this entity.")
+	}
+
+	if !slices.Contains(statuses, reservationRequest.Status) {
+		return nil, errors.New("You cannot update given reservation request - wrong status.")
+	}
+
+	return reservationRequest, nil
+}
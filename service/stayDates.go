@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/util"
+)
+
+const (
+	defaultCheckInTime  = "15:00"
+	defaultCheckOutTime = "10:00"
+)
+
+// stayDates places a stay on the local calendar of the accommodation. CheckIn and CheckOut are the instants the
+// stay starts and ends at, while CheckInDate and CheckOutDate are their local dates as midnight UTC, which is how
+// available terms, seasonal rates and blocked periods store dates.
+type stayDates struct {
+	CheckIn      time.Time
+	CheckOut     time.Time
+	CheckInDate  time.Time
+	CheckOutDate time.Time
+	TimeZone     string
+}
+
+func newStayDates(accommodationInfo *model.AccommodationInfo, checkInDate time.Time, numberOfDays uint) (*stayDates, error) {
+	location := util.LoadLocation(accommodationInfo.TimeZone)
+
+	checkInTime := accommodationInfo.CheckInTime
+	if checkInTime == "" {
+		checkInTime = defaultCheckInTime
+	}
+	checkInHour, checkInMinute, err := util.ParseClock(checkInTime)
+	if err != nil {
+		return nil, err
+	}
+
+	checkOutTime := accommodationInfo.CheckOutTime
+	if checkOutTime == "" {
+		checkOutTime = defaultCheckOutTime
+	}
+	checkOutHour, checkOutMinute, err := util.ParseClock(checkOutTime)
+	if err != nil {
+		return nil, err
+	}
+
+	checkInDate = time.Date(checkInDate.Year(), checkInDate.Month(), checkInDate.Day(), 0, 0, 0, 0, time.UTC)
+	checkOutDate := checkInDate.AddDate(0, 0, int(numberOfDays))
+
+	return &stayDates{
+		CheckIn:      time.Date(checkInDate.Year(), checkInDate.Month(), checkInDate.Day(), checkInHour, checkInMinute, 0, 0, location),
+		CheckOut:     time.Date(checkOutDate.Year(), checkOutDate.Month(), checkOutDate.Day(), checkOutHour, checkOutMinute, 0, 0, location),
+		CheckInDate:  checkInDate,
+		CheckOutDate: checkOutDate,
+		TimeZone:     location.String(),
+	}, nil
+}
+
+// requestedCheckInDate returns the check-in date the guest asked for. Without an explicit date it is the
+// date of startDate in the offset the client sent it with, not in the time zone of the server.
+func requestedCheckInDate(checkInDate string, startDate time.Time) (time.Time, error) {
+	if checkInDate == "" {
+		return time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC), nil
+	}
+
+	parsed, err := time.Parse(util.DateLayout, checkInDate)
+	if err != nil {
+		return time.Time{}, errors.New("Check-in date must be a date")
+	}
+
+	return parsed, nil
+}
+
+// reservationPeriod returns the local check-in and check-out dates of the reservation request as midnight UTC.
+// Reservation requests created before stays were placed in time zones fall back to the dates of their instants.
+func reservationPeriod(reservationRequest *model.ReservationRequest) (time.Time, time.Time) {
+	checkInDate, checkInErr := time.Parse(util.DateLayout, reservationRequest.CheckInDate)
+	checkOutDate, checkOutErr := time.Parse(util.DateLayout, reservationRequest.CheckOutDate)
+	if checkInErr != nil || checkOutErr != nil {
+		return localPeriod(reservationRequest.StartDate, reservationRequest.EndDate, reservationRequest.TimeZone)
+	}
+
+	return checkInDate, checkOutDate
+}
+
+// stayNights returns the local dates of the nights of the reservation request.
+func stayNights(reservationRequest *model.ReservationRequest) []time.Time {
+	return util.Nights(reservationPeriod(reservationRequest))
+}
+
+// localPeriod returns the local dates, as midnight UTC, of the period between the instants startDate and endDate.
+func localPeriod(startDate time.Time, endDate time.Time, timeZone string) (time.Time, time.Time) {
+	location := util.LoadLocation(timeZone)
+	return util.CivilDate(startDate, location), util.CivilDate(endDate, location)
+}
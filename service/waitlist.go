@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/windbnb/reservation-service/client"
+	"github.com/windbnb/reservation-service/metrics"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// JoinWaitlist puts the guest on the waitlist for dates of the accommodation that are taken. Dates that can be
+// reserved right away are rejected, as are the ones the accommodation is not offered on at all.
+func (s *ReservationRequestService) JoinWaitlist(joinWaitlistRequest *model.JoinWaitlistRequest, ctx context.Context) (*model.WaitlistEntry, error) {
+	span := tracer.StartSpanFromContext(ctx, "joinWaitlistService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	if joinWaitlistRequest.NumberOfDays <= 0 {
+		return nil, errors.New("Number of days must be positive")
+	}
+
+	checkInDate, err := requestedCheckInDate(joinWaitlistRequest.CheckInDate, joinWaitlistRequest.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	accommodationInfo, err := client.GetAccommodation(joinWaitlistRequest.AccommodationID, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	stay, err := newStayDates(&accommodationInfo, checkInDate, joinWaitlistRequest.NumberOfDays)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	if stay.CheckIn.Before(time.Now()) {
+		return nil, errors.New("Start date cannot be in past")
+	}
+
+	_, err = CalculatePrice(&accommodationInfo, stay.CheckInDate, joinWaitlistRequest.NumberOfDays, joinWaitlistRequest.GuestNumber)
+	if err != nil {
+		tracer.LogError(span, err)
+		return nil, err
+	}
+
+	for _, night := range util.Nights(stay.CheckInDate, stay.CheckOutDate) {
+		if !s.isDateInAvailableTerms(night, accommodationInfo.AvailableTerms, ctx) {
+			return nil, errors.New("Accommodation is not available")
+		}
+	}
+
+	if !s.isDateRangeTaken(joinWaitlistRequest.AccommodationID, stay.CheckInDate, stay.CheckOutDate, ctx) {
+		return nil, errors.New("Accommodation is available for given dates - reserve it instead")
+	}
+
+	waitlistEntries := s.Repo.FindGuestsWaitlistEntries(joinWaitlistRequest.GuestID, ctx)
+	if waitlistEntries != nil {
+		for _, waitlistEntry := range *waitlistEntries {
+			if waitlistEntry.Status == model.WAITING &&
+				waitlistEntry.AccommodationID == joinWaitlistRequest.AccommodationID &&
+				waitlistEntry.StartDate.Equal(stay.CheckIn) &&
+				waitlistEntry.EndDate.Equal(stay.CheckOut) {
+				return nil, errors.New("You are already on the waitlist for given dates")
+			}
+		}
+	}
+
+	waitlistEntry := s.Repo.SaveWaitlistEntry(&model.WaitlistEntry{
+		GuestID:         joinWaitlistRequest.GuestID,
+		AccommodationID: joinWaitlistRequest.AccommodationID,
+		StartDate:       stay.CheckIn,
+		EndDate:         stay.CheckOut,
+		TimeZone:        stay.TimeZone,
+		GuestNumber:     joinWaitlistRequest.GuestNumber,
+		AutoSubmit:      joinWaitlistRequest.AutoSubmit,
+		Status:          model.WAITING,
+		CreatedAt:       time.Now(),
+	}, ctx)
+	if waitlistEntry == nil {
+		return nil, errors.New("Waitlist entry could not be saved")
+	}
+
+	return waitlistEntry, nil
+}
+
+func (s *ReservationRequestService) LeaveWaitlist(waitlistEntryID primitive.ObjectID, guestID uint, ctx context.Context) error {
+	span := tracer.StartSpanFromContext(ctx, "leaveWaitlistService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	waitlistEntry := s.Repo.FindWaitlistEntry(waitlistEntryID, ctx)
+	if waitlistEntry == nil {
+		tracer.LogError(span, errors.New("Waitlist entry with given id does not exist."))
+		return errors.New("Waitlist entry with given id does not exist.")
+	}
+
+	if waitlistEntry.GuestID != guestID {
+		tracer.LogError(span, errors.New("You can not access to this entity."))
+		return errors.New("You can not access to this entity.")
+	}
+
+	if !s.Repo.DeleteWaitlistEntry(waitlistEntryID, ctx) {
+		return errors.New("Waitlist entry could not be deleted")
+	}
+
+	return nil
+}
+
+func (s *ReservationRequestService) GetGuestsWaitlistEntries(guestID uint, ctx context.Context) *[]model.WaitlistEntry {
+	span := tracer.StartSpanFromContext(ctx, "getGuestsWaitlistEntriesService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	return s.Repo.FindGuestsWaitlistEntries(guestID, ctx)
+}
+
+// notifyWaitlist is called once the reservation request stopped holding its dates. Waiting guests whose dates
+// are now free are notified in the order they joined the waitlist, and the entries with AutoSubmit set are
+// converted into SUBMITTED reservation requests. It returns the number of notified entries.
+func (s *ReservationRequestService) notifyWaitlist(reservationRequest *model.ReservationRequest, ctx context.Context) int {
+	span := tracer.StartSpanFromContext(ctx, "notifyWaitlistService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	ctx, cancel := detach(ctx)
+	defer cancel()
+
+	waitlistEntries := s.Repo.FindWaitingWaitlistEntries(reservationRequest.AccommodationID, reservationRequest.StartDate, reservationRequest.EndDate, ctx)
+	if waitlistEntries == nil {
+		return 0
+	}
+
+	notified := 0
+	for _, waitlistEntry := range *waitlistEntries {
+		checkInDate, checkOutDate := localPeriod(waitlistEntry.StartDate, waitlistEntry.EndDate, waitlistEntry.TimeZone)
+		if s.isDateRangeTaken(waitlistEntry.AccommodationID, checkInDate, checkOutDate, ctx) {
+			continue
+		}
+
+		now := time.Now()
+		waitlistEntry.Status = model.NOTIFIED
+		waitlistEntry.NotifiedAt = &now
+		if waitlistEntry.AutoSubmit {
+			s.convertWaitlistEntry(&waitlistEntry, ctx)
+		}
+		s.Repo.UpdateWaitlistEntry(&waitlistEntry, ctx)
+
+		reservationRequestID := ""
+		if waitlistEntry.ReservationRequestID != nil {
+			reservationRequestID = waitlistEntry.ReservationRequestID.Hex()
+		}
+
+		s.saveEvent(model.WAITLIST_NOTIFIED, model.WaitlistNotifiedEvent{
+			WaitlistEntryID:      waitlistEntry.ID.Hex(),
+			GuestID:              waitlistEntry.GuestID,
+			AccommodationID:      waitlistEntry.AccommodationID,
+			StartDate:            waitlistEntry.StartDate,
+			EndDate:              waitlistEntry.EndDate,
+			ReservationRequestID: reservationRequestID,
+		}, ctx)
+		notified++
+	}
+
+	return notified
+}
+
+// convertWaitlistEntry submits a reservation request for the waitlist entry. When the request is no longer valid,
+// for example because the guest number is not allowed anymore, the entry is left as merely notified.
+func (s *ReservationRequestService) convertWaitlistEntry(waitlistEntry *model.WaitlistEntry, ctx context.Context) {
+	span := tracer.StartSpanFromContext(ctx, "convertWaitlistEntryService")
+	defer span.End()
+
+	ctx = tracer.ContextWithSpan(ctx, span)
+
+	checkInDate, checkOutDate := localPeriod(waitlistEntry.StartDate, waitlistEntry.EndDate, waitlistEntry.TimeZone)
+	reservationRequest, _, err := s.newReservationRequest(&model.CreateReservationRequest{
+		StartDate:       waitlistEntry.StartDate,
+		CheckInDate:     checkInDate.Format(util.DateLayout),
+		NumberOfDays:    uint(len(util.Nights(checkInDate, checkOutDate))),
+		AccommodationID: waitlistEntry.AccommodationID,
+		GuestID:         waitlistEntry.GuestID,
+		GuestNumber:     waitlistEntry.GuestNumber,
+	}, ctx)
+	if err != nil {
+		tracer.LogError(span, err)
+		return
+	}
+
+	if s.Repo.SaveReservationRequest(reservationRequest, ctx) == nil {
+		return
+	}
+	metrics.ReservationRequestCreated(reservationRequest, len(stayNights(reservationRequest)))
+
+	waitlistEntry.Status = model.CONVERTED
+	waitlistEntry.ReservationRequestID = &reservationRequest.ID
+}
+
+// isDateRangeTaken reports whether any night between the local dates checkInDate and checkOutDate is held by a
+// reservation request or blocked by an imported calendar.
+func (s *ReservationRequestService) isDateRangeTaken(accommodationID uint, checkInDate time.Time, checkOutDate time.Time, ctx context.Context) bool {
+	return s.isReservedAlready(accommodationID, checkInDate, checkOutDate, ctx) ||
+		s.isBlockedByImportedCalendar(accommodationID, checkInDate, checkOutDate, ctx)
+}
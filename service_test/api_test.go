@@ -0,0 +1,297 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/windbnb/reservation-service/api"
+	"github.com/windbnb/reservation-service/handler"
+	"github.com/windbnb/reservation-service/health"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/router"
+	"github.com/windbnb/reservation-service/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestOpenAPISpec_DescribesEveryRoute(t *testing.T) {
+	// Given
+	spec, err := api.LoadSpec()
+	require.NoError(t, err)
+
+	routes := []string{}
+	err = router.ConfigureRouter(&handler.Handler{}).Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			// the probes and the metrics answer every method, they are described as GET
+			methods = []string{http.MethodGet}
+		}
+		for _, method := range methods {
+			routes = append(routes, method+" "+template)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	// When
+	operations := []string{}
+	for path, pathItem := range spec.Paths {
+		for method := range pathItem.Operations() {
+			operations = append(operations, method+" "+path)
+		}
+	}
+
+	// Then
+	sort.Strings(routes)
+	sort.Strings(operations)
+	assert.Equal(t, routes, operations)
+}
+
+func TestOpenAPISpec_SchemasMatchModels(t *testing.T) {
+	// Given
+	spec, err := api.LoadSpec()
+	require.NoError(t, err)
+
+	models := []any{
+		model.CreateReservationRequest{}, model.RecurrenceSpec{}, model.JoinWaitlistRequest{},
+		model.CreateReservationGroupRequest{}, model.ReservationGroupUnit{}, model.RatingEligibilityRequest{},
+		model.BulkAvailabilityRequest{}, model.BookingRules{}, model.CalendarImportRequest{},
+		model.ErrorResponse{}, model.RuleViolation{}, model.OccurrenceConflict{}, model.ReservationRequestDto{},
+		model.PriceBreakdown{}, model.NightlyPrice{}, model.Refund{}, model.Payment{}, model.ReservationGroupDto{},
+		model.ReservationSeriesDto{}, model.CancelledReservations{}, model.RatingEligibilityDto{},
+		model.EligibleStayDto{}, model.WaitlistEntry{}, model.CalendarDto{}, model.CalendarNightDto{},
+		model.CalendarFeedDto{}, model.CalendarImport{}, model.AvailabilityDto{}, model.DateRangeDto{},
+		model.BulkAvailabilityDto{}, health.Report{}, health.CheckResult{}}
+
+	for _, value := range models {
+		modelType := reflect.TypeOf(value)
+
+		// When
+		fields := []string{}
+		for i := 0; i < modelType.NumField(); i++ {
+			name := modelType.Field(i).Name
+			if tag, found := modelType.Field(i).Tag.Lookup("json"); found {
+				name = strings.Split(tag, ",")[0]
+			}
+			if name != "-" {
+				fields = append(fields, name)
+			}
+		}
+
+		schema := spec.Components.Schemas[modelType.Name()]
+		if !assert.NotNil(t, schema, "schema of %s", modelType.Name()) {
+			continue
+		}
+		properties := []string{}
+		for property := range schema.Value.Properties {
+			properties = append(properties, property)
+		}
+
+		// Then
+		sort.Strings(fields)
+		sort.Strings(properties)
+		assert.Equal(t, fields, properties, "properties of %s", modelType.Name())
+	}
+}
+
+func TestValidateRequests(t *testing.T) {
+	// Given
+	spec, err := api.LoadSpec()
+	require.NoError(t, err)
+
+	served := 0
+	var received []byte
+	validated := api.ValidateRequests(spec)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served++
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	cases := []struct {
+		name    string
+		method  string
+		target  string
+		body    string
+		status  int
+		message string
+	}{
+		{"camel case body", http.MethodPost, "/api/reservationRequest/quote", `{"checkInDate":"2024-07-01","numberOfDays":3,"accommodationID":1,"guestNumber":2}`, http.StatusOK, ""},
+		{"empty body", http.MethodPost, "/api/reservationRequest/quote", `{}`, http.StatusBadRequest, "request body"},
+		{"accommodation missing", http.MethodPost, "/api/reservationRequest/new", `{"checkInDate":"2024-07-01","numberOfDays":3,"guestNumber":2}`, http.StatusBadRequest, `property "accommodationID" is missing`},
+		{"check-in date missing", http.MethodPost, "/api/waitlist", `{"numberOfDays":3,"accommodationID":1,"guestNumber":2}`, http.StatusBadRequest, "request body"},
+		{"check-in as start date", http.MethodPost, "/api/waitlist", `{"startDate":"2024-07-01T14:00:00Z","numberOfDays":3,"accommodationID":1,"guestNumber":2}`, http.StatusOK, ""},
+		{"group unit guests missing", http.MethodPost, "/api/reservationGroup/new", `{"checkInDate":"2024-07-01","numberOfDays":3,"units":[{"accommodationID":1},{"accommodationID":2,"guestNumber":2}]}`, http.StatusBadRequest, `property "guestNumber" is missing`},
+		{"number of days as text", http.MethodPost, "/api/reservationRequest/quote", `{"checkInDate":"2024-07-01","numberOfDays":"three"}`, http.StatusBadRequest, "request body.numberOfDays"},
+		{"check-in date not a date", http.MethodPost, "/api/reservationRequest/new", `{"checkInDate":"01.07.2024","numberOfDays":3}`, http.StatusBadRequest, "request body.checkInDate"},
+		{"unknown recurrence frequency", http.MethodPost, "/api/reservationRequest/new", `{"checkInDate":"2024-07-01","recurrence":{"frequency":"DAILY"}}`, http.StatusBadRequest, "request body.recurrence.frequency"},
+		{"missing body", http.MethodPost, "/api/waitlist", ``, http.StatusBadRequest, "request body"},
+		{"reservation request ID not an ObjectID", http.MethodPut, "/api/reservationRequest/42/accept", ``, http.StatusBadRequest, "path parameter id"},
+		{"accommodation ID not a number", http.MethodGet, "/api/accommodations/first/availability", ``, http.StatusBadRequest, "path parameter id"},
+		{"unknown review target", http.MethodPut, "/api/reservationRequest/64b7f0c2a1e4d3b2c1a09f8e/review?target=GUEST", ``, http.StatusBadRequest, "query parameter target"},
+		{"route not described", http.MethodGet, "/api/unknown", ``, http.StatusOK, ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			served = 0
+			request := httptest.NewRequest(c.method, c.target, strings.NewReader(c.body))
+			if c.body != "" {
+				request.Header.Set("Content-Type", "application/json")
+			}
+			recorder := httptest.NewRecorder()
+
+			// When
+			validated.ServeHTTP(recorder, request)
+
+			// Then
+			assert.Equal(t, c.status, recorder.Code)
+			if c.status == http.StatusOK {
+				// the handler still reads the body the middleware validated
+				assert.Equal(t, 1, served)
+				assert.Equal(t, c.body, string(received))
+				return
+			}
+
+			var errorResponse model.ErrorResponse
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&errorResponse))
+			assert.Equal(t, 0, served)
+			assert.Equal(t, http.StatusBadRequest, errorResponse.StatusCode)
+			assert.Contains(t, errorResponse.Message, c.message)
+		})
+	}
+}
+
+func TestValidateRequests_PropertyNamesOfOlderClients(t *testing.T) {
+	// Given
+	spec, err := api.LoadSpec()
+	require.NoError(t, err)
+
+	var received []byte
+	validated := api.ValidateRequests(spec)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	send := func(body string) *httptest.ResponseRecorder {
+		received = nil
+		request := httptest.NewRequest(http.MethodPost, "/api/reservationGroup/new", strings.NewReader(body))
+		request.Header.Set("Content-Type", "application/json")
+		recorder := httptest.NewRecorder()
+		validated.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// When
+	valid := send(`{"CheckInDate":"2024-07-01","NumberOfDays":3,"Units":[{"AccommodationID":1,"GuestNumber":2},{"accommodationid":2,"GUESTNUMBER":1}]}`)
+	validBody := string(received)
+	wrongType := send(`{"CheckInDate":"2024-07-01","NumberOfDays":"x","Units":[]}`)
+	missing := send(`{"CheckInDate":"2024-07-01","Units":[]}`)
+	duplicate := send(`{"checkInDate":"2024-07-01","numberOfDays":3,"NumberOfDays":"x","units":[]}`)
+
+	// Then
+	assert.Equal(t, http.StatusOK, valid.Code)
+	assert.JSONEq(t, `{"checkInDate":"2024-07-01","numberOfDays":3,"units":[{"accommodationID":1,"guestNumber":2},{"accommodationID":2,"guestNumber":1}]}`, validBody)
+
+	for recorder, message := range map[*httptest.ResponseRecorder]string{
+		wrongType: "request body.numberOfDays",
+		missing:   `property "numberOfDays" is missing`,
+		duplicate: "request body.numberOfDays: property is given more than once",
+	} {
+		var errorResponse model.ErrorResponse
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&errorResponse))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Contains(t, errorResponse.Message, message)
+	}
+}
+
+func TestConfigureRouter_ServesOpenAPISpec(t *testing.T) {
+	// Given
+	configuredRouter := router.ConfigureRouter(&handler.Handler{})
+	recorder := httptest.NewRecorder()
+
+	// When
+	configuredRouter.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, api.SpecPath, nil))
+
+	// Then
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var document struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&document))
+	assert.Equal(t, "3.0.3", document.OpenAPI)
+	assert.Contains(t, document.Paths, "/api/reservationRequest/new")
+	assert.Contains(t, document.Paths, api.SpecPath)
+}
+
+func TestConfigureRouter_ResponsesMatchSpec(t *testing.T) {
+	// Given
+	spec, err := api.LoadSpec()
+	require.NoError(t, err)
+	specRouter, err := gorillamux.NewRouter(spec)
+	require.NoError(t, err)
+
+	serveUser(t, model.UserResponseDTO{Id: 3, Role: model.GUEST})
+	notifiedAt := time.Now()
+	reservationRequestID := primitive.NewObjectID()
+	configuredRouter := router.ConfigureRouter(&handler.Handler{Service: &service.ReservationRequestService{Repo: &MockRepo{
+		FindGuestsWaitlistEntriesFn: func(guestID uint, ctx context.Context) *[]model.WaitlistEntry {
+			return &[]model.WaitlistEntry{{
+				ID: primitive.NewObjectID(), GuestID: guestID, AccommodationID: 7, StartDate: notifiedAt, EndDate: notifiedAt.AddDate(0, 0, 2),
+				TimeZone: "UTC", GuestNumber: 2, Status: model.CONVERTED, CreatedAt: notifiedAt, NotifiedAt: &notifiedAt, ReservationRequestID: &reservationRequestID,
+			}}
+		},
+	}}})
+
+	cases := []struct {
+		name   string
+		method string
+		target string
+		status int
+	}{
+		{"liveness", http.MethodGet, "/probe/liveness", http.StatusOK},
+		{"waitlist", http.MethodGet, "/api/waitlist", http.StatusOK},
+		{"rejected request", http.MethodPut, "/api/reservationRequest/42/accept", http.StatusBadRequest},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			request := httptest.NewRequest(c.method, c.target, nil)
+			recorder := httptest.NewRecorder()
+
+			// When
+			configuredRouter.ServeHTTP(recorder, request)
+
+			// Then
+			assert.Equal(t, c.status, recorder.Code)
+			route, pathParams, err := specRouter.FindRoute(request)
+			require.NoError(t, err)
+			err = openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{Request: request, PathParams: pathParams, Route: route},
+				Status:                 recorder.Code,
+				Header:                 recorder.Header(),
+				Body:                   io.NopCloser(recorder.Body),
+				Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+			})
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/service"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestGetAvailability(t *testing.T) {
+	// Given
+	from := util.StartOfDay(time.Now().UTC()).AddDate(0, 0, 10)
+	serveAccommodation(t, model.AccommodationInfo{
+		Id: 7,
+		AvailableTerms: []model.AvailableTerm{
+			{StartDate: from, EndDate: from.AddDate(0, 0, 12)},
+		},
+	})
+
+	mockRepo := &MockRepo{
+		FindReservationRequestsInPeriodFn: func(accommodationID uint, from time.Time, to time.Time, statuses []model.ReservationRequestStatus, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{ID: primitive.NewObjectID(), Status: model.ACCEPTED, StartDate: from.AddDate(0, 0, 2), EndDate: from.AddDate(0, 0, 4)},
+				{ID: primitive.NewObjectID(), Status: model.SUBMITTED, StartDate: from.AddDate(0, 0, 4), EndDate: from.AddDate(0, 0, 6)},
+			}
+		},
+		FindBlockedPeriodsFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.BlockedPeriod {
+			return &[]model.BlockedPeriod{
+				{StartDate: from.AddDate(0, 0, 8), EndDate: from.AddDate(0, 0, 9)},
+			}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	availability, err := reservationService.GetAvailability(7, from, from.AddDate(0, 0, 14), 3, 4, context.Background())
+
+	// Then
+	date := func(days int) string {
+		return from.AddDate(0, 0, days).Format(util.DateLayout)
+	}
+	assert.Nil(t, err)
+	assert.Equal(t, []model.DateRangeDto{
+		{StartDate: date(0), EndDate: date(2)},
+		{StartDate: date(4), EndDate: date(8)},
+		{StartDate: date(9), EndDate: date(12)},
+	}, availability.AvailableRanges)
+	assert.Equal(t, []model.DateRangeDto{
+		{StartDate: date(4), EndDate: date(7)},
+		{StartDate: date(5), EndDate: date(8)},
+		{StartDate: date(9), EndDate: date(12)},
+	}, availability.Windows)
+}
+
+func TestGetAvailability_ExcludesPast(t *testing.T) {
+	// Given
+	today := util.StartOfDay(time.Now().UTC())
+	serveAccommodation(t, model.AccommodationInfo{
+		Id: 7,
+		AvailableTerms: []model.AvailableTerm{
+			{StartDate: today.AddDate(0, 0, -5), EndDate: today.AddDate(0, 0, 5)},
+		},
+	})
+
+	mockRepo := &MockRepo{
+		FindReservationRequestsInPeriodFn: func(accommodationID uint, from time.Time, to time.Time, statuses []model.ReservationRequestStatus, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+		FindBlockedPeriodsFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.BlockedPeriod {
+			return &[]model.BlockedPeriod{}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	availability, err := reservationService.GetAvailability(7, today.AddDate(0, 0, -5), today.AddDate(0, 0, 10), 1, 1, context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []model.DateRangeDto{
+		{StartDate: today.AddDate(0, 0, 1).Format(util.DateLayout), EndDate: today.AddDate(0, 0, 5).Format(util.DateLayout)},
+	}, availability.AvailableRanges)
+	assert.Equal(t, 1, len(availability.Windows))
+}
+
+func TestGetAvailability_InvalidNights(t *testing.T) {
+	// Given
+	reservationService := service.ReservationRequestService{
+		Repo: &MockRepo{},
+	}
+
+	// When
+	from := time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err := reservationService.GetAvailability(7, from, from.AddDate(0, 0, 7), 0, 5, context.Background())
+
+	// Then
+	assert.EqualError(t, err, "Number of nights must be positive")
+}
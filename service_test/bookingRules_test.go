@@ -0,0 +1,117 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/service"
+	"github.com/windbnb/reservation-service/util"
+)
+
+func violationCodes(violations []model.RuleViolation) []model.BookingRuleCode {
+	codes := []model.BookingRuleCode{}
+	for _, violation := range violations {
+		codes = append(codes, violation.Code)
+	}
+
+	return codes
+}
+
+func TestEvaluateBookingRules_ReportsAllViolations(t *testing.T) {
+	// Given
+	now := time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC)
+	bookingRules := &model.BookingRules{
+		MinimumNights:        3,
+		MaximumNights:        14,
+		AdvanceNoticeHours:   48,
+		BookingHorizonMonths: 12,
+		CheckInWeekdays:      []string{"SATURDAY"},
+		BufferDays:           1,
+	}
+	reservationRequests := []model.ReservationRequest{
+		{StartDate: time.Date(2030, 3, 3, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2030, 3, 4, 0, 0, 0, 0, time.UTC)},
+	}
+
+	// When
+	// a 2 night stay checking in on Monday right when another stay checks out
+	violations := service.EvaluateBookingRules(bookingRules, time.Date(2030, 3, 4, 0, 0, 0, 0, time.UTC), 2, now, reservationRequests)
+
+	// Then
+	assert.Equal(t, []model.BookingRuleCode{model.MINIMUM_NIGHTS, model.CHECK_IN_WEEKDAY, model.BUFFER_DAYS}, violationCodes(violations))
+}
+
+func TestEvaluateBookingRules_NoticeAndHorizon(t *testing.T) {
+	// Given
+	now := time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC)
+	bookingRules := &model.BookingRules{
+		MaximumNights:        7,
+		AdvanceNoticeHours:   48,
+		BookingHorizonMonths: 6,
+	}
+
+	// When
+	tooSoon := service.EvaluateBookingRules(bookingRules, now.Add(24*time.Hour), 10, now, nil)
+	tooFar := service.EvaluateBookingRules(bookingRules, now.AddDate(0, 7, 0), 7, now, nil)
+
+	// Then
+	assert.Equal(t, []model.BookingRuleCode{model.MAXIMUM_NIGHTS, model.ADVANCE_NOTICE}, violationCodes(tooSoon))
+	assert.Equal(t, []model.BookingRuleCode{model.BOOKING_HORIZON}, violationCodes(tooFar))
+}
+
+func TestEvaluateBookingRules_Satisfied(t *testing.T) {
+	// Given
+	now := time.Date(2030, 3, 1, 12, 0, 0, 0, time.UTC)
+	bookingRules := &model.BookingRules{
+		MinimumNights:   2,
+		CheckInWeekdays: []string{"SATURDAY", "SUNDAY"},
+		BufferDays:      1,
+	}
+	reservationRequests := []model.ReservationRequest{
+		{StartDate: time.Date(2030, 3, 2, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2030, 3, 7, 0, 0, 0, 0, time.UTC)},
+	}
+
+	// When
+	violations := service.EvaluateBookingRules(bookingRules, time.Date(2030, 3, 9, 0, 0, 0, 0, time.UTC), 2, now, reservationRequests)
+
+	// Then
+	assert.Empty(t, violations)
+}
+
+func TestSaveReservationRequest_BookingRulesViolated(t *testing.T) {
+	// Given
+	start := util.StartOfDay(time.Now()).AddDate(0, 0, 10)
+	serveAccommodation(t, model.AccommodationInfo{
+		Id: 7,
+		AvailableTerms: []model.AvailableTerm{
+			{StartDate: start, EndDate: start.AddDate(0, 0, 10)},
+		},
+	})
+
+	mockRepo := &MockRepo{
+		FindBookingRulesFn: func(accommodationID uint, ctx context.Context) *model.BookingRules {
+			return &model.BookingRules{AccommodationID: accommodationID, MinimumNights: 3, MaximumNights: 1}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	_, err := reservationService.SaveReservationRequest(&model.CreateReservationRequest{
+		StartDate:       start,
+		NumberOfDays:    2,
+		AccommodationID: 7,
+		GuestID:         3,
+		GuestNumber:     2,
+	}, context.Background())
+
+	// Then
+	var violationsError *service.BookingRuleViolationsError
+	assert.True(t, errors.As(err, &violationsError))
+	assert.Equal(t, []model.BookingRuleCode{model.MINIMUM_NIGHTS, model.MAXIMUM_NIGHTS}, violationCodes(violationsError.Violations))
+}
@@ -0,0 +1,224 @@
+package service_test
+
+import (
+	"context"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/repository"
+	"github.com/windbnb/reservation-service/service"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// inMemoryReservations is a seeded stand-in for the reservation_request collection, indexed by accommodation
+// like the compound index the repository query relies on.
+type inMemoryReservations map[uint][]model.ReservationRequest
+
+func seedReservations(accommodations int, reservationsPerAccommodation int) inMemoryReservations {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	reservations := inMemoryReservations{}
+	for accommodationID := 1; accommodationID <= accommodations; accommodationID++ {
+		for i := 0; i < reservationsPerAccommodation; i++ {
+			startDate := start.AddDate(0, 0, i*7+accommodationID%7)
+			reservations[uint(accommodationID)] = append(reservations[uint(accommodationID)], model.ReservationRequest{
+				AccommodationID: uint(accommodationID),
+				Status:          model.ACCEPTED,
+				StartDate:       startDate,
+				EndDate:         startDate.AddDate(0, 0, 3),
+			})
+		}
+	}
+
+	return reservations
+}
+
+func (reservations inMemoryReservations) findUnavailableAccommodations(accommodationIDs []uint, from time.Time, to time.Time, ctx context.Context) *[]uint {
+	unavailable := []uint{}
+	for _, accommodationID := range accommodationIDs {
+		for _, reservationRequest := range reservations[accommodationID] {
+			if reservationRequest.StartDate.Before(to) && reservationRequest.EndDate.After(from) {
+				unavailable = append(unavailable, accommodationID)
+				break
+			}
+		}
+	}
+
+	return &unavailable
+}
+
+func TestCheckBulkAvailability(t *testing.T) {
+	// Given
+	mockRepo := &MockRepo{
+		FindUnavailableAccommodationsFn: seedReservations(14, 10).findUnavailableAccommodations,
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	bulkAvailability, err := reservationService.CheckBulkAvailability(&model.BulkAvailabilityRequest{
+		AccommodationIDs: []uint{7, 1, 2, 3, 1, 100},
+		From:             "2030-01-01",
+		To:               "2030-01-03",
+	}, context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, []uint{2, 3, 100}, bulkAvailability.Available)
+	assert.Equal(t, []uint{7, 1}, bulkAvailability.Unavailable)
+}
+
+func TestCheckBulkAvailability_TooManyAccommodations(t *testing.T) {
+	// Given
+	reservationService := service.ReservationRequestService{
+		Repo: &MockRepo{},
+	}
+
+	// When
+	_, err := reservationService.CheckBulkAvailability(&model.BulkAvailabilityRequest{
+		AccommodationIDs: make([]uint, service.MaximumBulkAvailabilityIDs+1),
+		From:             "2030-01-01",
+		To:               "2030-01-03",
+	}, context.Background())
+
+	// Then
+	assert.EqualError(t, err, "At most 5000 accommodations can be checked at once")
+}
+
+func TestCheckBulkAvailability_InvalidPeriod(t *testing.T) {
+	// Given
+	reservationService := service.ReservationRequestService{
+		Repo: &MockRepo{},
+	}
+
+	// When
+	_, err := reservationService.CheckBulkAvailability(&model.BulkAvailabilityRequest{
+		AccommodationIDs: []uint{1},
+		From:             "2030-01-03",
+		To:               "2030-01-01",
+	}, context.Background())
+
+	// Then
+	assert.EqualError(t, err, "Start of the period must be before its end")
+}
+
+// bulkAvailabilityP99Target is the 99th percentile a full batch of MaximumBulkAvailabilityIDs accommodations must
+// be answered within by the reservation_request collection seeded by BenchmarkCheckBulkAvailability_Integration.
+const bulkAvailabilityP99Target = 200 * time.Millisecond
+
+// fullBulkAvailabilityRequest asks about MaximumBulkAvailabilityIDs accommodations, every fourth one starting from
+// the given one, over a week the seeded stays overlap for some of them.
+func fullBulkAvailabilityRequest(firstAccommodationID int) *model.BulkAvailabilityRequest {
+	accommodationIDs := make([]uint, service.MaximumBulkAvailabilityIDs)
+	for i := range accommodationIDs {
+		accommodationIDs[i] = uint(firstAccommodationID + i*4)
+	}
+
+	return &model.BulkAvailabilityRequest{
+		AccommodationIDs: accommodationIDs,
+		From:             "2030-03-01",
+		To:               "2030-03-08",
+	}
+}
+
+// BenchmarkCheckBulkAvailability measures the aggregation of a full batch over 10 stays for each of 20000
+// accommodations held in memory, leaving the database out.
+func BenchmarkCheckBulkAvailability(b *testing.B) {
+	reservationService := service.ReservationRequestService{
+		Repo: &MockRepo{
+			FindUnavailableAccommodationsFn: seedReservations(20000, 10).findUnavailableAccommodations,
+		},
+	}
+	bulkAvailabilityRequest := fullBulkAvailabilityRequest(1)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := reservationService.CheckBulkAvailability(bulkAvailabilityRequest, context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkAccommodationOffset keeps the accommodations seeded by the benchmark apart from any other data of the
+// database, so they can be removed afterwards.
+const benchmarkAccommodationOffset = 1_000_000_000
+
+// BenchmarkCheckBulkAvailability_Integration checks a full batch against the reservation_request collection of the
+// configured database, seeded with 10 stays for each of 20000 accommodations, and fails when the 99th percentile
+// exceeds bulkAvailabilityP99Target. It writes to the database, so it only runs when BULK_AVAILABILITY_BENCHMARK_MONGO
+// is set.
+func BenchmarkCheckBulkAvailability_Integration(b *testing.B) {
+	if os.Getenv("BULK_AVAILABILITY_BENCHMARK_MONGO") == "" {
+		b.Skip("set BULK_AVAILABILITY_BENCHMARK_MONGO to benchmark against the configured database")
+	}
+
+	db := connectToDatabase()
+	repo := &repository.Repository{Db: db}
+	if err := repo.EnsureIndexes(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+
+	collection := db.Collection("reservation_request")
+	cleanup := func() {
+		_, err := collection.DeleteMany(context.Background(), bson.D{{"accommodationID", bson.D{{"$gte", benchmarkAccommodationOffset}}}})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	cleanup()
+	b.Cleanup(cleanup)
+
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	for accommodationID := 1; accommodationID <= 20000; accommodationID += 1000 {
+		documents := []interface{}{}
+		for offset := 0; offset < 1000; offset++ {
+			for i := 0; i < 10; i++ {
+				startDate := start.AddDate(0, 0, i*7+(accommodationID+offset)%7)
+				documents = append(documents, model.ReservationRequest{
+					ID:              primitive.NewObjectID(),
+					AccommodationID: uint(benchmarkAccommodationOffset + accommodationID + offset),
+					Status:          model.ACCEPTED,
+					StartDate:       startDate,
+					EndDate:         startDate.AddDate(0, 0, 3),
+					CheckInDate:     startDate.Format(util.DateLayout),
+					CheckOutDate:    startDate.AddDate(0, 0, 3).Format(util.DateLayout),
+				})
+			}
+		}
+		if _, err := collection.InsertMany(context.Background(), documents); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: repo,
+	}
+
+	bulkAvailabilityRequest := fullBulkAvailabilityRequest(benchmarkAccommodationOffset + 1)
+
+	durations := make([]time.Duration, 0, b.N)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		started := time.Now()
+		_, err := reservationService.CheckBulkAvailability(bulkAvailabilityRequest, context.Background())
+		if err != nil {
+			b.Fatal(err)
+		}
+		durations = append(durations, time.Since(started))
+	}
+	b.StopTimer()
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	p99 := durations[len(durations)*99/100]
+	b.ReportMetric(float64(p99.Milliseconds()), "p99-ms")
+	if p99 > bulkAvailabilityP99Target {
+		b.Fatalf("99th percentile of %v exceeds the target of %v", p99, bulkAvailabilityP99Target)
+	}
+}
@@ -0,0 +1,96 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/client"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// serveAccommodation starts a stand-in for the accommodation service answering with the given accommodation.
+func serveAccommodation(t *testing.T, accommodationInfo model.AccommodationInfo) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(accommodationInfo)
+	}))
+	t.Cleanup(server.Close)
+	useAccommodationService(t, server.URL)
+}
+
+// useAccommodationService points the client at the accommodation service at the URL for the duration of the test.
+func useAccommodationService(t *testing.T, url string) {
+	previous := client.AccommodationServiceURL
+	client.AccommodationServiceURL = url
+	t.Cleanup(func() { client.AccommodationServiceURL = previous })
+}
+
+func TestGetAccommodationCalendar(t *testing.T) {
+	// Given
+	from := time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC)
+	serveAccommodation(t, model.AccommodationInfo{
+		Id:     7,
+		UserID: 1,
+		AvailableTerms: []model.AvailableTerm{
+			{StartDate: from, EndDate: from.AddDate(0, 0, 6)},
+		},
+	})
+
+	accepted := primitive.NewObjectID()
+	firstRequest := primitive.NewObjectID()
+	secondRequest := primitive.NewObjectID()
+	mockRepo := &MockRepo{
+		FindReservationRequestsInPeriodFn: func(accommodationID uint, from time.Time, to time.Time, statuses []model.ReservationRequestStatus, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{ID: accepted, Status: model.ACCEPTED, StartDate: from.AddDate(0, 0, 1), EndDate: from.AddDate(0, 0, 3)},
+				{ID: firstRequest, Status: model.SUBMITTED, StartDate: from.AddDate(0, 0, 3), EndDate: from.AddDate(0, 0, 5)},
+				{ID: secondRequest, Status: model.SUBMITTED, StartDate: from.AddDate(0, 0, 4), EndDate: from.AddDate(0, 0, 5)},
+			}
+		},
+		FindBlockedPeriodsFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.BlockedPeriod {
+			return &[]model.BlockedPeriod{
+				{StartDate: from.AddDate(0, 0, 5), EndDate: from.AddDate(0, 0, 6)},
+			}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	calendar, err := reservationService.GetAccommodationCalendar(7, 1, from, from.AddDate(0, 0, 7), context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 7, len(calendar.Nights))
+	assert.Equal(t, model.AVAILABLE, calendar.Nights[0].Status)
+	assert.Equal(t, model.BOOKED, calendar.Nights[1].Status)
+	assert.Equal(t, accepted.Hex(), calendar.Nights[2].ReservationRequestID)
+	assert.Equal(t, model.REQUESTED, calendar.Nights[3].Status)
+	assert.Equal(t, []string{firstRequest.Hex(), secondRequest.Hex()}, calendar.Nights[4].RequestedReservationRequestIDs)
+	assert.Equal(t, model.BLOCKED, calendar.Nights[5].Status)
+	assert.Equal(t, model.UNAVAILABLE, calendar.Nights[6].Status)
+}
+
+func TestGetAccommodationCalendar_NotOwner(t *testing.T) {
+	// Given
+	serveAccommodation(t, model.AccommodationInfo{Id: 7, UserID: 1})
+
+	reservationService := service.ReservationRequestService{
+		Repo: &MockRepo{},
+	}
+
+	// When
+	from := time.Date(2030, 3, 1, 0, 0, 0, 0, time.UTC)
+	_, err := reservationService.GetAccommodationCalendar(7, 2, from, from.AddDate(0, 0, 7), context.Background())
+
+	// Then
+	assert.EqualError(t, err, "You can not access to this entity.")
+}
@@ -0,0 +1,91 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/events"
+	"github.com/windbnb/reservation-service/model"
+)
+
+func TestDispatchPending_RetriesOnlyFailedHandlers(t *testing.T) {
+	// Given
+	event := model.Event{Type: model.RESERVATION_CANCELLED}
+	recorded := []model.Event{}
+	published := []model.Event{}
+	mockRepo := &MockRepo{
+		FindUnpublishedEventsFn: func(limit int64, ctx context.Context) *[]model.Event {
+			return &[]model.Event{event}
+		},
+		RecordEventAttemptFn: func(e *model.Event, ctx context.Context) bool {
+			recorded = append(recorded, *e)
+			event = *e
+			return true
+		},
+		MarkEventPublishedFn: func(e *model.Event, ctx context.Context) bool {
+			published = append(published, *e)
+			return true
+		},
+	}
+
+	refunds, notifications := 0, 0
+	dispatcher := &events.Dispatcher{Repo: mockRepo}
+	dispatcher.Subscribe(model.RESERVATION_CANCELLED, "payment-service", func(event model.Event, ctx context.Context) error {
+		refunds++
+		return nil
+	})
+	dispatcher.Subscribe(model.RESERVATION_CANCELLED, "notification-service", func(event model.Event, ctx context.Context) error {
+		notifications++
+		if notifications == 1 {
+			return errors.New("Notification service is unavailable")
+		}
+		return nil
+	})
+
+	// When
+	dispatcher.DispatchPending(context.Background())
+	dispatcher.DispatchPending(context.Background())
+
+	// Then
+	assert.Equal(t, 1, refunds)
+	assert.Equal(t, 2, notifications)
+	assert.Len(t, recorded, 1)
+	assert.Equal(t, 1, recorded[0].Attempts)
+	assert.Equal(t, "Notification service is unavailable", recorded[0].LastError)
+	assert.Equal(t, []string{"payment-service"}, recorded[0].HandledBy)
+	assert.Len(t, published, 1)
+}
+
+func TestDispatchPending_ParksEventAfterMaximumAttempts(t *testing.T) {
+	// Given
+	recorded, parked := 0, []model.Event{}
+	mockRepo := &MockRepo{
+		FindUnpublishedEventsFn: func(limit int64, ctx context.Context) *[]model.Event {
+			return &[]model.Event{{Type: model.RESERVATION_CANCELLED, Attempts: 2}}
+		},
+		RecordEventAttemptFn: func(e *model.Event, ctx context.Context) bool {
+			recorded++
+			return true
+		},
+		ParkEventFn: func(e *model.Event, ctx context.Context) bool {
+			parked = append(parked, *e)
+			return true
+		},
+	}
+
+	dispatcher := &events.Dispatcher{Repo: mockRepo, MaximumAttempts: 3}
+	dispatcher.Subscribe(model.RESERVATION_CANCELLED, "payment-service", func(event model.Event, ctx context.Context) error {
+		return errors.New("Authorization does not exist")
+	})
+
+	// When
+	dispatcher.DispatchPending(context.Background())
+
+	// Then
+	assert.Equal(t, 0, recorded)
+	assert.Len(t, parked, 1)
+	assert.Equal(t, 3, parked[0].Attempts)
+	assert.Equal(t, "Authorization does not exist", parked[0].LastError)
+}
@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/client"
+	"github.com/windbnb/reservation-service/handler"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/router"
+	"github.com/windbnb/reservation-service/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// serveUser makes the user service authorize every token as the user.
+func serveUser(t *testing.T, user model.UserResponseDTO) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(user)
+	}))
+	t.Cleanup(server.Close)
+
+	previous := client.UserServiceURL
+	client.UserServiceURL = server.URL
+	t.Cleanup(func() { client.UserServiceURL = previous })
+}
+
+func eligibilityRouter(t *testing.T) http.Handler {
+	return router.ConfigureRouter(&handler.Handler{Service: &service.ReservationRequestService{Repo: &MockRepo{
+		FindCompletedStaysFn: func(eligibilityRequests []model.RatingEligibilityRequest, ctx context.Context) *[]model.ReservationRequest {
+			t.Error("stays must not be read")
+			return nil
+		},
+	}}})
+}
+
+func TestGetRatingEligibilities(t *testing.T) {
+	// Given
+	reviewedAt := time.Now().AddDate(0, 0, -1)
+	mockRepo := &MockRepo{
+		FindCompletedStaysFn: func(eligibilityRequests []model.RatingEligibilityRequest, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				// recent stay with host 10, host not reviewed yet
+				{ID: primitive.NewObjectID(), GuestID: 1, OwnerID: 10, AccommodationID: 100, EndDate: time.Now().AddDate(0, 0, -2), Status: model.COMPLETED},
+				// old stay with host 10, review window passed
+				{ID: primitive.NewObjectID(), GuestID: 1, OwnerID: 10, AccommodationID: 101, EndDate: time.Now().AddDate(0, -3, 0), Status: model.COMPLETED},
+				// recent stay of guest 2 in accommodation 200, already reviewed
+				{ID: primitive.NewObjectID(), GuestID: 2, OwnerID: 20, AccommodationID: 200, EndDate: time.Now().AddDate(0, 0, -2), Status: model.COMPLETED, AccommodationReviewedAt: &reviewedAt},
+			}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	eligibilities, err := reservationService.GetRatingEligibilities([]model.RatingEligibilityRequest{
+		{GuestID: 1, HostID: 10},
+		{GuestID: 2, AccommodationID: 200},
+		{GuestID: 3, HostID: 10},
+	}, context.Background())
+
+	// Then
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(eligibilities))
+	assert.True(t, eligibilities[0].Eligible)
+	assert.Equal(t, 2, len(eligibilities[0].Stays))
+	assert.True(t, eligibilities[0].Stays[0].CanReview)
+	assert.False(t, eligibilities[0].Stays[1].CanReview)
+	assert.False(t, eligibilities[1].Eligible)
+	assert.True(t, eligibilities[1].Stays[0].ReviewSubmitted)
+	assert.False(t, eligibilities[2].Eligible)
+	assert.Empty(t, eligibilities[2].Stays)
+}
+
+func TestGetRatingEligibility_OtherGuest(t *testing.T) {
+	// Given
+	serveUser(t, model.UserResponseDTO{Id: 1, Role: model.GUEST})
+	recorder := httptest.NewRecorder()
+
+	// When
+	eligibilityRouter(t).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/reservationRequest/guest/2/eligibility?hostId=10", nil))
+
+	// Then
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestGetRatingEligibilities_OtherUsersAndTooMany(t *testing.T) {
+	// Given
+	serveUser(t, model.UserResponseDTO{Id: 10, Role: model.HOST})
+	otherHost := `[{"guestID":1,"hostID":10},{"guestID":1,"hostID":11,"accommodationID":100}]`
+	tooMany := make([]model.RatingEligibilityRequest, service.MaximumRatingEligibilityRequests+1)
+	for i := range tooMany {
+		tooMany[i] = model.RatingEligibilityRequest{GuestID: uint(i + 1), HostID: 10}
+	}
+	tooManyBody, _ := json.Marshal(tooMany)
+
+	otherHostRecorder := httptest.NewRecorder()
+	tooManyRecorder := httptest.NewRecorder()
+
+	// When
+	eligibilityRouter(t).ServeHTTP(otherHostRecorder, newJSONRequest(http.MethodPost, "/api/reservationRequest/eligibility", otherHost))
+	eligibilityRouter(t).ServeHTTP(tooManyRecorder, newJSONRequest(http.MethodPost, "/api/reservationRequest/eligibility", string(tooManyBody)))
+
+	// Then
+	assert.Equal(t, http.StatusForbidden, otherHostRecorder.Code)
+	assert.Equal(t, http.StatusBadRequest, tooManyRecorder.Code)
+}
+
+func newJSONRequest(method string, target string, body string) *http.Request {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	return request
+}
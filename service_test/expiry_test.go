@@ -0,0 +1,53 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestExpireSubmittedReservationRequests(t *testing.T) {
+	// Given
+	events := []model.ReservationExpiredEvent{}
+	mockRepo := &MockRepo{
+		FindExpiredSubmittedFn: func(submittedBefore time.Time, startingBefore time.Time, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{ID: primitive.NewObjectID(), GuestID: 1, OwnerID: 2, Status: model.SUBMITTED, StartDate: time.Now().AddDate(0, 0, 3)},
+				{ID: primitive.NewObjectID(), GuestID: 3, OwnerID: 2, Status: model.SUBMITTED, StartDate: time.Now().AddDate(0, 0, -1)},
+				{ID: primitive.NewObjectID(), GuestID: 4, OwnerID: 2, Status: model.SUBMITTED, StartDate: time.Now().AddDate(0, 0, 5)},
+			}
+		},
+		ExpireReservationRequestFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) bool {
+			// the request of guest 4 was accepted in the meantime
+			return reservationRequest.GuestID != 4
+		},
+		SaveEventFn: func(event *model.Event, ctx context.Context) *model.Event {
+			var reservationExpired model.ReservationExpiredEvent
+			json.Unmarshal([]byte(event.Payload), &reservationExpired)
+			events = append(events, reservationExpired)
+			return event
+		},
+		FindWaitingWaitlistEntriesFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.WaitlistEntry {
+			return &[]model.WaitlistEntry{}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	expired := reservationService.ExpireSubmittedReservationRequests(context.Background())
+
+	// Then
+	assert.Equal(t, 2, expired)
+	assert.Equal(t, model.RESPONSE_WINDOW_ELAPSED, events[0].Reason)
+	assert.Equal(t, model.START_DATE_PASSED, events[1].Reason)
+	assert.Equal(t, uint(2), events[1].OwnerID)
+}
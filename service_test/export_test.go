@@ -0,0 +1,67 @@
+package service_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/export"
+	"github.com/windbnb/reservation-service/model"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func exportedReservationRequests() []model.ReservationRequest {
+	id, _ := primitive.ObjectIDFromHex("65a000000000000000000001")
+	return []model.ReservationRequest{{
+		ID:                 id,
+		Status:             model.CANCELLED,
+		AccommodationID:    7,
+		AccommodationName:  "Apartment, Old Town",
+		OwnerID:            1,
+		GuestID:            3,
+		GuestNumber:        2,
+		CheckInDate:        "2030-03-01",
+		CheckOutDate:       "2030-03-03",
+		StartDate:          time.Date(2030, 3, 1, 14, 0, 0, 0, time.UTC),
+		EndDate:            time.Date(2030, 3, 3, 9, 0, 0, 0, time.UTC),
+		TimeZone:           "Europe/Belgrade",
+		Price:              &model.PriceBreakdown{Total: 160},
+		CancellationPolicy: model.MODERATE,
+		Refund:             &model.Refund{Amount: 80},
+	}}
+}
+
+func TestExport_CSV(t *testing.T) {
+	// Given
+	output := &bytes.Buffer{}
+
+	// When
+	err := export.Write(output, export.CSV, exportedReservationRequests())
+
+	// Then
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "id,status,accommodationID,accommodationName,"))
+	assert.Equal(t, `65a000000000000000000001,CANCELLED,7,"Apartment, Old Town",1,3,2,2030-03-01,2030-03-03,2030-03-01T14:00:00Z,2030-03-03T09:00:00Z,Europe/Belgrade,160.00,MODERATE,,80.00,,,0,`, lines[1])
+}
+
+func TestExport_JSONL(t *testing.T) {
+	// Given
+	output := &bytes.Buffer{}
+
+	// When
+	err := export.Write(output, export.JSONL, append(exportedReservationRequests(), exportedReservationRequests()...))
+	unknownFormatErr := export.Write(output, "xml", nil)
+
+	// Then
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"id":"65a000000000000000000001","status":"CANCELLED"`)
+	assert.Contains(t, lines[0], `"total":160,`)
+	assert.Contains(t, lines[0], `"groupID":"",`)
+	assert.NotNil(t, unknownFormatErr)
+}
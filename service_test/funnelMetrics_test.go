@@ -0,0 +1,110 @@
+package service_test
+
+import (
+	"bufio"
+	"context"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/metrics"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// metricValue scrapes the metrics endpoint and returns the value of the series, or zero when it is not exported.
+func metricValue(series string) float64 {
+	recorder := httptest.NewRecorder()
+	metrics.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	scanner := bufio.NewScanner(recorder.Body)
+	for scanner.Scan() {
+		if value, found := strings.CutPrefix(scanner.Text(), series+" "); found {
+			parsed, _ := strconv.ParseFloat(value, 64)
+			return parsed
+		}
+	}
+
+	return 0
+}
+
+func TestAcceptReservationRequest_FunnelMetrics(t *testing.T) {
+	// Given
+	accepted := `reservation_requests_accepted_total{accept_type="MANUAL"}`
+	declined := `reservation_requests_declined_total{accept_type="MANUAL",reason="competing"}`
+	timeToAccept := `reservation_time_to_accept_seconds_count{accept_type="MANUAL"}`
+	acceptedBefore, declinedBefore, timeToAcceptBefore := metricValue(accepted), metricValue(declined), metricValue(timeToAccept)
+
+	mockRepo := &MockRepo{
+		TransitionStatusFn: transitionStatus,
+		FindReservationRequestFn: func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
+			return &model.ReservationRequest{
+				ID:                    reservationRequestID,
+				StartDate:             time.Now().AddDate(0, 0, 10),
+				EndDate:               time.Now().AddDate(0, 0, 12),
+				Status:                model.SUBMITTED,
+				OwnerID:               1,
+				AcceptReservationType: model.MANUAL,
+				CreatedAt:             time.Now().Add(-time.Hour),
+			}
+		},
+		FindAcceptedReservationRequestsFn: func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+		UpdateReservationRequestStatusFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+		FindCompetingFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{{ID: primitive.NewObjectID(), Status: model.SUBMITTED}, {ID: primitive.NewObjectID(), Status: model.SUBMITTED}}
+		},
+		SaveEventFn: func(event *model.Event, ctx context.Context) *model.Event {
+			return event
+		},
+		AcceptReservationRequestFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	_, err := reservationService.AcceptReservationRequest(primitive.NewObjectID(), 1, context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, acceptedBefore+1, metricValue(accepted))
+	assert.Equal(t, declinedBefore+2, metricValue(declined))
+	assert.Equal(t, timeToAcceptBefore+1, metricValue(timeToAccept))
+}
+
+func TestRefreshSubmittedBacklog(t *testing.T) {
+	// Given
+	backlogs := []map[uint]int{{5: 3}, {6: 1}}
+	mockRepo := &MockRepo{
+		CountSubmittedByOwnerFn: func(ctx context.Context) *map[uint]int {
+			backlog := backlogs[0]
+			backlogs = backlogs[1:]
+			return &backlog
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	reservationService.RefreshSubmittedBacklog(context.Background())
+	firstBacklog := metricValue(`reservation_requests_submitted_backlog{host_id="5"}`)
+	reservationService.RefreshSubmittedBacklog(context.Background())
+
+	// Then
+	assert.Equal(t, 3.0, firstBacklog)
+	assert.Equal(t, 0.0, metricValue(`reservation_requests_submitted_backlog{host_id="5"}`))
+	assert.Equal(t, 1.0, metricValue(`reservation_requests_submitted_backlog{host_id="6"}`))
+}
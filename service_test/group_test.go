@@ -0,0 +1,476 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/payment"
+	"github.com/windbnb/reservation-service/service"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func serveAccommodations(t *testing.T, accommodationInfos ...model.AccommodationInfo) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, accommodationInfo := range accommodationInfos {
+			if path.Base(r.URL.Path) == strconv.FormatUint(uint64(accommodationInfo.Id), 10) {
+				json.NewEncoder(w).Encode(accommodationInfo)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(server.Close)
+	useAccommodationService(t, server.URL)
+}
+
+func groupMockRepo(saved *bool) *MockRepo {
+	return &MockRepo{
+		FindBookingRulesFn: func(accommodationID uint, ctx context.Context) *model.BookingRules {
+			return &model.BookingRules{AccommodationID: accommodationID}
+		},
+		FindAcceptedReservationRequestsFn: func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+		FindBlockedPeriodsFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.BlockedPeriod {
+			return &[]model.BlockedPeriod{}
+		},
+		SaveReservationRequestsFn: func(reservationRequests []*model.ReservationRequest, ctx context.Context) bool {
+			*saved = true
+			return true
+		},
+	}
+}
+
+func TestSaveReservationGroup_OneUnitUnavailable(t *testing.T) {
+	// Given
+	start := util.StartOfDay(time.Now()).AddDate(0, 0, 10)
+	serveAccommodations(t,
+		model.AccommodationInfo{Id: 7, UserID: 1, AvailableTerms: []model.AvailableTerm{{StartDate: start, EndDate: start.AddDate(0, 0, 10)}}},
+		model.AccommodationInfo{Id: 8, UserID: 1, AvailableTerms: []model.AvailableTerm{{StartDate: start, EndDate: start.AddDate(0, 0, 1)}}},
+	)
+
+	saved := false
+	reservationService := service.ReservationRequestService{
+		Repo: groupMockRepo(&saved),
+	}
+
+	// When
+	reservationGroup, err := reservationService.SaveReservationGroup(&model.CreateReservationGroupRequest{
+		CheckInDate:  start.Format(util.DateLayout),
+		NumberOfDays: 3,
+		GuestID:      3,
+		Units:        []model.ReservationGroupUnit{{AccommodationID: 7, GuestNumber: 2}, {AccommodationID: 8, GuestNumber: 2}},
+	}, context.Background())
+
+	// Then
+	assert.Nil(t, reservationGroup)
+	assert.EqualError(t, err, "Accommodation is not available")
+	assert.False(t, saved)
+}
+
+func TestSaveReservationGroup_DifferentHosts(t *testing.T) {
+	// Given
+	start := util.StartOfDay(time.Now()).AddDate(0, 0, 10)
+	availableTerms := []model.AvailableTerm{{StartDate: start, EndDate: start.AddDate(0, 0, 10)}}
+	serveAccommodations(t,
+		model.AccommodationInfo{Id: 7, UserID: 1, AvailableTerms: availableTerms},
+		model.AccommodationInfo{Id: 8, UserID: 2, AvailableTerms: availableTerms},
+	)
+
+	saved := false
+	reservationService := service.ReservationRequestService{
+		Repo: groupMockRepo(&saved),
+	}
+
+	// When
+	_, err := reservationService.SaveReservationGroup(&model.CreateReservationGroupRequest{
+		CheckInDate:  start.Format(util.DateLayout),
+		NumberOfDays: 3,
+		GuestID:      3,
+		Units:        []model.ReservationGroupUnit{{AccommodationID: 7, GuestNumber: 2}, {AccommodationID: 8, GuestNumber: 2}},
+	}, context.Background())
+
+	// Then
+	assert.EqualError(t, err, "Accommodations of a group must belong to the same host")
+	assert.False(t, saved)
+}
+
+func TestAcceptReservationGroup_SinglePayment(t *testing.T) {
+	// Given
+	groupID := primitive.NewObjectID()
+	start := time.Now().AddDate(0, 0, 10)
+	accepted := []primitive.ObjectID{}
+	mockRepo := &MockRepo{
+		TransitionStatusFn: transitionStatus,
+		FindGroupReservationRequestsFn: func(groupID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{ID: primitive.NewObjectID(), GroupID: &groupID, AccommodationID: 7, GuestID: 3, OwnerID: 1, Status: model.SUBMITTED, StartDate: start, EndDate: start.AddDate(0, 0, 2), Price: &model.PriceBreakdown{Total: 300}},
+				{ID: primitive.NewObjectID(), GroupID: &groupID, AccommodationID: 8, GuestID: 3, OwnerID: 1, Status: model.SUBMITTED, StartDate: start, EndDate: start.AddDate(0, 0, 2), Price: &model.PriceBreakdown{Total: 200}},
+			}
+		},
+		FindAcceptedReservationRequestsFn: func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+		UpdateReservationRequestStatusFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+		UpdateReservationRequestPaymentFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+		AcceptReservationRequestFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			accepted = append(accepted, reservationRequest.ID)
+			return reservationRequest
+		},
+		FindCompetingFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo:            mockRepo,
+		PaymentProvider: payment.NewFakePaymentProvider(time.Hour),
+	}
+
+	// When
+	reservationGroup, err := reservationService.AcceptReservationGroup(groupID, 1, context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(accepted))
+	first, second := reservationGroup.ReservationRequests[0], reservationGroup.ReservationRequests[1]
+	assert.Equal(t, model.ACCEPTED, first.Status)
+	assert.Equal(t, model.ACCEPTED, second.Status)
+	assert.Equal(t, first.Payment.AuthorizationID, second.Payment.AuthorizationID)
+	assert.Equal(t, 300.0, first.Payment.Amount)
+	assert.Equal(t, 200.0, second.Payment.Amount)
+}
+
+func TestAcceptReservationRequest_GroupMember(t *testing.T) {
+	// Given
+	groupID := primitive.NewObjectID()
+	mockRepo := &MockRepo{
+		FindReservationRequestFn: func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
+			return &model.ReservationRequest{ID: reservationRequestID, GroupID: &groupID, OwnerID: 1, Status: model.SUBMITTED}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	_, err := reservationService.AcceptReservationRequest(primitive.NewObjectID(), 1, context.Background())
+
+	// Then
+	assert.EqualError(t, err, "Reservation request belongs to a group - accept the whole group.")
+}
+
+// failingCapturePaymentProvider authorizes like the fake provider but declines every capture.
+type failingCapturePaymentProvider struct {
+	*payment.FakePaymentProvider
+	captures int
+	voids    int
+}
+
+func (p *failingCapturePaymentProvider) Capture(authorizationID string, amount float64, ctx context.Context) error {
+	p.captures++
+	return errors.New("card reported stolen")
+}
+
+func (p *failingCapturePaymentProvider) Void(authorizationID string, ctx context.Context) error {
+	p.voids++
+	return p.FakePaymentProvider.Void(authorizationID, ctx)
+}
+
+func TestAcceptReservationGroup_CaptureFails(t *testing.T) {
+	// Given
+	groupID := primitive.NewObjectID()
+	start := time.Now().AddDate(0, 0, 10)
+	accepted := 0
+	statuses := map[primitive.ObjectID]model.ReservationRequestStatus{}
+	mockRepo := &MockRepo{
+		TransitionStatusFn: transitionStatus,
+		FindGroupReservationRequestsFn: func(groupID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{ID: primitive.NewObjectID(), GroupID: &groupID, AccommodationID: 7, GuestID: 3, OwnerID: 1, Status: model.SUBMITTED, StartDate: start, EndDate: start.AddDate(0, 0, 2), Price: &model.PriceBreakdown{Total: 300}},
+				{ID: primitive.NewObjectID(), GroupID: &groupID, AccommodationID: 8, GuestID: 3, OwnerID: 1, Status: model.SUBMITTED, StartDate: start, EndDate: start.AddDate(0, 0, 2), Price: &model.PriceBreakdown{Total: 200}},
+			}
+		},
+		FindAcceptedReservationRequestsFn: func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+		UpdateReservationRequestStatusFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			statuses[reservationRequest.ID] = reservationRequest.Status
+			return reservationRequest
+		},
+		UpdateReservationRequestPaymentFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+		AcceptReservationRequestFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			accepted++
+			return reservationRequest
+		},
+		FindCompetingFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+		FindWaitingWaitlistEntriesFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.WaitlistEntry {
+			return &[]model.WaitlistEntry{}
+		},
+	}
+
+	paymentProvider := &failingCapturePaymentProvider{FakePaymentProvider: payment.NewFakePaymentProvider(time.Hour)}
+	reservationService := service.ReservationRequestService{
+		Repo:            mockRepo,
+		PaymentProvider: paymentProvider,
+	}
+
+	// When
+	reservationGroup, err := reservationService.AcceptReservationGroup(groupID, 1, context.Background())
+
+	// Then
+	assert.EqualError(t, err, "Payment authorization failed: card reported stolen")
+	assert.Nil(t, reservationGroup)
+	assert.Equal(t, 0, accepted)
+	assert.Equal(t, 1, paymentProvider.captures)
+	assert.Equal(t, 1, paymentProvider.voids)
+	assert.Equal(t, 2, len(statuses))
+	for _, status := range statuses {
+		assert.Equal(t, model.PAYMENT_FAILED, status)
+	}
+}
+
+func TestAcceptReservationGroup_MemberChangedInTheMeantime(t *testing.T) {
+	// Given
+	groupID := primitive.NewObjectID()
+	start := time.Now().AddDate(0, 0, 10)
+	first, second := primitive.NewObjectID(), primitive.NewObjectID()
+	transitions := []model.ReservationRequestStatus{}
+	mockRepo := &MockRepo{
+		FindGroupReservationRequestsFn: func(groupID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{ID: first, GroupID: &groupID, AccommodationID: 7, GuestID: 3, OwnerID: 1, Status: model.SUBMITTED, StartDate: start, EndDate: start.AddDate(0, 0, 2), Price: &model.PriceBreakdown{Total: 300}},
+				{ID: second, GroupID: &groupID, AccommodationID: 8, GuestID: 3, OwnerID: 1, Status: model.SUBMITTED, StartDate: start, EndDate: start.AddDate(0, 0, 2), Price: &model.PriceBreakdown{Total: 200}},
+			}
+		},
+		FindAcceptedReservationRequestsFn: func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+		// the second reservation request expired after the group was read
+		TransitionStatusFn: func(reservationRequest *model.ReservationRequest, from model.ReservationRequestStatus, to model.ReservationRequestStatus, ctx context.Context) bool {
+			if reservationRequest.ID == second {
+				return false
+			}
+			transitions = append(transitions, to)
+			return transitionStatus(reservationRequest, from, to, ctx)
+		},
+	}
+
+	paymentProvider := &failingCapturePaymentProvider{FakePaymentProvider: payment.NewFakePaymentProvider(time.Hour)}
+	reservationService := service.ReservationRequestService{
+		Repo:            mockRepo,
+		PaymentProvider: paymentProvider,
+	}
+
+	// When
+	reservationGroup, err := reservationService.AcceptReservationGroup(groupID, 1, context.Background())
+
+	// Then
+	assert.Nil(t, reservationGroup)
+	assert.EqualError(t, err, "You cannot update given reservation group - wrong status.")
+	assert.Equal(t, []model.ReservationRequestStatus{model.PAYMENT_PENDING, model.SUBMITTED}, transitions)
+	assert.Equal(t, 0, paymentProvider.captures)
+}
+
+func TestCancelReservationGroup_PaymentPending(t *testing.T) {
+	// Given
+	groupID := primitive.NewObjectID()
+	start := time.Now().AddDate(0, 0, 10)
+	paymentProvider := payment.NewFakePaymentProvider(time.Hour)
+	paymentProvider.PendingAmountAbove = 100
+	authorization, _ := paymentProvider.Authorize(payment.AuthorizationRequest{Reference: groupID.Hex(), GuestID: 3, Amount: 500}, context.Background())
+
+	payments := []model.PaymentStatus{}
+	mockRepo := &MockRepo{
+		FindGroupReservationRequestsFn: func(groupID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{ID: primitive.NewObjectID(), GroupID: &groupID, AccommodationID: 7, GuestID: 3, OwnerID: 1, Status: model.PAYMENT_PENDING, StartDate: start, EndDate: start.AddDate(0, 0, 2), Price: &model.PriceBreakdown{Total: 300}, CancellationPolicy: model.FLEXIBLE,
+					Payment: &model.Payment{AuthorizationID: authorization.ID, Status: authorization.Status, Amount: 300, ExpiresAt: authorization.ExpiresAt}},
+				{ID: primitive.NewObjectID(), GroupID: &groupID, AccommodationID: 8, GuestID: 3, OwnerID: 1, Status: model.PAYMENT_PENDING, StartDate: start, EndDate: start.AddDate(0, 0, 2), Price: &model.PriceBreakdown{Total: 200}, CancellationPolicy: model.FLEXIBLE,
+					Payment: &model.Payment{AuthorizationID: authorization.ID, Status: authorization.Status, Amount: 200, ExpiresAt: authorization.ExpiresAt}},
+			}
+		},
+		UpdateReservationRequestStatusFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+		UpdateReservationRequestPaymentFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			payments = append(payments, reservationRequest.Payment.Status)
+			return reservationRequest
+		},
+		UpdateReservationRequestRefundFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+		SaveEventFn: func(event *model.Event, ctx context.Context) *model.Event {
+			return event
+		},
+		FindWaitingWaitlistEntriesFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.WaitlistEntry {
+			return &[]model.WaitlistEntry{}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo:            mockRepo,
+		PaymentProvider: paymentProvider,
+	}
+
+	// When
+	reservationGroup, err := reservationService.CancelReservationGroup(groupID, 3, context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	for _, reservationRequest := range reservationGroup.ReservationRequests {
+		assert.Equal(t, model.CANCELLED, reservationRequest.Status)
+	}
+	// the second member finds the shared authorization voided by the first
+	assert.Equal(t, []model.PaymentStatus{model.VOIDED, model.VOIDED}, payments)
+	assert.ErrorIs(t, paymentProvider.Void(authorization.ID, context.Background()), payment.ErrAuthorizationVoided)
+}
+
+func TestAcceptReservationRequest_DeclinesCompetingGroup(t *testing.T) {
+	// Given
+	groupID, seriesID := primitive.NewObjectID(), primitive.NewObjectID()
+	start := time.Now().AddDate(0, 0, 10)
+	competingMember, otherMember := primitive.NewObjectID(), primitive.NewObjectID()
+	occurrence := primitive.NewObjectID()
+	declinedEvents := []model.ReservationDeclinedEvent{}
+	mockRepo := &MockRepo{
+		TransitionStatusFn: transitionStatus,
+		FindReservationRequestFn: func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
+			return &model.ReservationRequest{ID: reservationRequestID, AccommodationID: 7, OwnerID: 1, Status: model.SUBMITTED, StartDate: start, EndDate: start.AddDate(0, 0, 2)}
+		},
+		FindAcceptedReservationRequestsFn: func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+		FindCompetingFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{ID: competingMember, GroupID: &groupID, AccommodationID: 7, GuestID: 3, Status: model.SUBMITTED},
+				{ID: occurrence, SeriesID: &seriesID, AccommodationID: 7, GuestID: 4, Status: model.SUBMITTED},
+			}
+		},
+		FindGroupReservationRequestsFn: func(groupID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{ID: competingMember, GroupID: &groupID, AccommodationID: 7, GuestID: 3, Status: model.SUBMITTED},
+				{ID: otherMember, GroupID: &groupID, AccommodationID: 8, GuestID: 3, Status: model.SUBMITTED},
+			}
+		},
+		SaveEventFn: func(event *model.Event, ctx context.Context) *model.Event {
+			var declinedEvent model.ReservationDeclinedEvent
+			json.Unmarshal([]byte(event.Payload), &declinedEvent)
+			assert.Equal(t, model.RESERVATION_DECLINED, event.Type)
+			declinedEvents = append(declinedEvents, declinedEvent)
+			return event
+		},
+		AcceptReservationRequestFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	reservationRequest, err := reservationService.AcceptReservationRequest(primitive.NewObjectID(), 1, context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, model.ACCEPTED, reservationRequest.Status)
+	declined := []string{}
+	for _, declinedEvent := range declinedEvents {
+		assert.Equal(t, model.COMPETING_REQUEST_ACCEPTED, declinedEvent.Reason)
+		declined = append(declined, declinedEvent.ReservationRequestID)
+	}
+	// the other occurrences of the series do not compete and are left to the host
+	assert.Equal(t, []string{competingMember.Hex(), otherMember.Hex(), occurrence.Hex()}, declined)
+	assert.Equal(t, groupID.Hex(), declinedEvents[1].GroupID)
+	assert.Equal(t, seriesID.Hex(), declinedEvents[2].SeriesID)
+}
+
+func TestDeclineReservationGroup(t *testing.T) {
+	// Given
+	groupID := primitive.NewObjectID()
+	start := time.Now().AddDate(0, 0, 10)
+	first, second := primitive.NewObjectID(), primitive.NewObjectID()
+	declinedEvents := []model.ReservationDeclinedEvent{}
+	mockRepo := &MockRepo{
+		TransitionStatusFn: transitionStatus,
+		FindGroupReservationRequestsFn: func(groupID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{ID: first, GroupID: &groupID, AccommodationID: 7, GuestID: 3, OwnerID: 1, Status: model.SUBMITTED, StartDate: start, EndDate: start.AddDate(0, 0, 2)},
+				{ID: second, GroupID: &groupID, AccommodationID: 8, GuestID: 3, OwnerID: 1, Status: model.SUBMITTED, StartDate: start, EndDate: start.AddDate(0, 0, 2)},
+			}
+		},
+		SaveEventFn: func(event *model.Event, ctx context.Context) *model.Event {
+			var declinedEvent model.ReservationDeclinedEvent
+			json.Unmarshal([]byte(event.Payload), &declinedEvent)
+			assert.Equal(t, model.RESERVATION_DECLINED, event.Type)
+			declinedEvents = append(declinedEvents, declinedEvent)
+			return event
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	reservationGroup, err := reservationService.DeclineReservationGroup(groupID, 1, context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	for _, reservationRequest := range reservationGroup.ReservationRequests {
+		assert.Equal(t, model.DECLINED, reservationRequest.Status)
+	}
+	declined := []string{}
+	for _, declinedEvent := range declinedEvents {
+		assert.Equal(t, model.DECLINED_BY_HOST, declinedEvent.Reason)
+		assert.Equal(t, groupID.Hex(), declinedEvent.GroupID)
+		declined = append(declined, declinedEvent.ReservationRequestID)
+	}
+	assert.Equal(t, []string{first.Hex(), second.Hex()}, declined)
+}
+
+func TestDeclineReservationGroup_CancelledBeforeWriting(t *testing.T) {
+	// Given
+	groupID := primitive.NewObjectID()
+	start := time.Now().AddDate(0, 0, 10)
+	mockRepo := &MockRepo{
+		FindGroupReservationRequestsFn: func(groupID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{ID: primitive.NewObjectID(), GroupID: &groupID, AccommodationID: 7, GuestID: 3, OwnerID: 1, Status: model.SUBMITTED, StartDate: start, EndDate: start.AddDate(0, 0, 2)},
+			}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// When
+	reservationGroup, err := reservationService.DeclineReservationGroup(groupID, 1, ctx)
+
+	// Then
+	assert.Nil(t, reservationGroup)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,117 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/handler"
+	"github.com/windbnb/reservation-service/health"
+	"github.com/windbnb/reservation-service/model"
+)
+
+func TestHealthMonitor_CachesReport(t *testing.T) {
+	// Given
+	calls := 0
+	monitor := &health.Monitor{CacheTTL: time.Minute, Timeout: time.Second}
+	monitor.Register(health.NewChecker("mongo", true, func(ctx context.Context) error {
+		calls++
+		return nil
+	}))
+	monitor.SetReady(true)
+
+	// When
+	first := monitor.Report(context.Background())
+	second := monitor.Report(context.Background())
+
+	// Then
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, health.UP, second.Status)
+	assert.True(t, second.Ready)
+	assert.Equal(t, first.CheckedAt, second.CheckedAt)
+}
+
+func TestHealthMonitor_CriticalAndOptionalFailures(t *testing.T) {
+	// Given
+	degraded := &health.Monitor{Timeout: time.Second}
+	degraded.Register(health.NewChecker("mongo", true, func(ctx context.Context) error { return nil }))
+	degraded.Register(health.NewChecker("user-service", false, func(ctx context.Context) error { return errors.New("connection refused") }))
+	degraded.SetReady(true)
+
+	down := &health.Monitor{Timeout: 10 * time.Millisecond}
+	down.Register(health.NewChecker("mongo", true, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}))
+	down.Register(health.NewChecker("outbox", false, func(ctx context.Context) error { panic("nil database") }))
+	down.SetReady(true)
+
+	// When
+	degradedReport := degraded.Report(context.Background())
+	downReport := down.Report(context.Background())
+
+	// Then
+	assert.Equal(t, health.DEGRADED, degradedReport.Status)
+	assert.True(t, degradedReport.Ready)
+	assert.Equal(t, "connection refused", degradedReport.Checks[1].Error)
+	assert.Equal(t, health.DOWN, downReport.Status)
+	assert.False(t, downReport.Ready)
+	assert.Equal(t, "context deadline exceeded", downReport.Checks[0].Error)
+	assert.Equal(t, "Check panicked: nil database", downReport.Checks[1].Error)
+}
+
+func TestReadiness_NotReadyWhileStartingOrStopping(t *testing.T) {
+	// Given
+	monitor := &health.Monitor{Timeout: time.Second}
+	monitor.Register(health.NewChecker("mongo", true, func(ctx context.Context) error { return nil }))
+	h := &handler.Handler{Health: monitor}
+
+	probe := func(handle http.HandlerFunc) (int, health.Report) {
+		recorder := httptest.NewRecorder()
+		handle(recorder, httptest.NewRequest(http.MethodGet, "/probe/readiness", nil))
+		var report health.Report
+		json.NewDecoder(recorder.Body).Decode(&report)
+		return recorder.Code, report
+	}
+
+	// When
+	startingStatus, _ := probe(h.Ready)
+	monitor.SetReady(true)
+	readyStatus, readyReport := probe(h.Ready)
+	monitor.SetReady(false)
+	stoppingStatus, _ := probe(h.Ready)
+	livenessStatus, _ := probe(h.Healthcheck)
+	monitor.Register(health.NewChecker("user-service", true, func(ctx context.Context) error { return errors.New("connection refused") }))
+	downLivenessStatus, _ := probe(h.Healthcheck)
+
+	// Then
+	assert.Equal(t, http.StatusServiceUnavailable, startingStatus)
+	assert.Equal(t, http.StatusOK, readyStatus)
+	assert.Equal(t, "mongo", readyReport.Checks[0].Name)
+	assert.Equal(t, http.StatusServiceUnavailable, stoppingStatus)
+	assert.Equal(t, http.StatusOK, livenessStatus)
+	assert.Equal(t, http.StatusOK, downLivenessStatus)
+}
+
+func TestOutboxLagChecker(t *testing.T) {
+	// Given
+	createdAt := time.Now().Add(-10 * time.Minute)
+	mockRepo := &MockRepo{
+		FindUnpublishedEventsFn: func(limit int64, ctx context.Context) *[]model.Event {
+			return &[]model.Event{{Type: model.RESERVATION_CANCELLED, CreatedAt: createdAt}}
+		},
+	}
+
+	// When
+	err := health.OutboxLagChecker(mockRepo, 5*time.Minute).Check(context.Background())
+	caughtUp := health.OutboxLagChecker(mockRepo, time.Hour).Check(context.Background())
+
+	// Then
+	assert.EqualError(t, err, "Oldest unpublished event waits for 10m0s")
+	assert.Nil(t, caughtUp)
+}
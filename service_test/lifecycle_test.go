@@ -0,0 +1,125 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/lifecycle"
+	"github.com/windbnb/reservation-service/scheduler"
+)
+
+func recordingComponent(name string, calls *[]string, startErr error, stopErr error) lifecycle.Component {
+	return lifecycle.Component{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			*calls = append(*calls, "start "+name)
+			return startErr
+		},
+		Stop: func(ctx context.Context) error {
+			*calls = append(*calls, "stop "+name)
+			return stopErr
+		}}
+}
+
+func TestLifecycle_StopsStartedComponentsInReverseOrder(t *testing.T) {
+	// Given
+	calls := []string{}
+	app := &lifecycle.Manager{}
+	app.Start(context.Background(), recordingComponent("mongo", &calls, nil, nil))
+	app.Start(context.Background(), recordingComponent("tracer", &calls, nil, errors.New("exporter unreachable")))
+	app.Start(context.Background(), recordingComponent("http-server", &calls, nil, nil))
+
+	// When
+	err := app.Stop(context.Background())
+
+	// Then
+	assert.ErrorContains(t, err, "stopping tracer: exporter unreachable")
+	assert.Equal(t, []string{
+		"start mongo", "start tracer", "start http-server",
+		"stop http-server", "stop tracer", "stop mongo"}, calls)
+}
+
+func TestLifecycle_ComponentFailingToStartIsNotStopped(t *testing.T) {
+	// Given
+	calls := []string{}
+	app := &lifecycle.Manager{}
+	app.Start(context.Background(), recordingComponent("mongo", &calls, nil, nil))
+
+	// When
+	err := app.Start(context.Background(), recordingComponent("schema", &calls, errors.New("migration failed"), nil))
+	app.Stop(context.Background())
+
+	// Then
+	assert.ErrorContains(t, err, "starting schema: migration failed")
+	assert.Equal(t, []string{"start mongo", "start schema", "stop mongo"}, calls)
+}
+
+func TestRetry_SucceedsAfterFailedAttempts(t *testing.T) {
+	// Given
+	attempts := 0
+
+	// When
+	err := lifecycle.Retry(context.Background(), 3, time.Millisecond, nil, func(ctx context.Context) error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("no reachable servers")
+		}
+		return nil
+	})
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRetry_GivesUpWhenContextIsDone(t *testing.T) {
+	// Given
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	attempts := 0
+
+	// When
+	err := lifecycle.Retry(ctx, 5, time.Hour, nil, func(ctx context.Context) error {
+		attempts++
+		return errors.New("no reachable servers")
+	})
+
+	// Then
+	assert.ErrorContains(t, err, "no reachable servers")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestScheduler_StopGivesUpAtDeadline(t *testing.T) {
+	// Given
+	running, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+
+	jobs := &scheduler.Scheduler{}
+	jobs.Register(scheduler.Job{
+		Name:     "long-job",
+		Interval: time.Millisecond,
+		Run: func(ctx context.Context) {
+			select {
+			case running <- struct{}{}:
+			default:
+			}
+			<-release
+		}})
+	jobs.Start()
+	<-running
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// When
+	begin := time.Now()
+	err := jobs.Stop(ctx)
+
+	// Then
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(begin), time.Second)
+}
@@ -0,0 +1,84 @@
+package service_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/logging"
+	"github.com/windbnb/reservation-service/tracer"
+)
+
+func TestLoggingMiddleware_RequestCorrelation(t *testing.T) {
+	// Given
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	_, closer := tracer.Init("reservation-service")
+	defer closer.Close()
+
+	output := &bytes.Buffer{}
+	logger := logging.New(output, "info", "json")
+	handlerRequestID := ""
+	handler := logging.Middleware(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := tracer.StartSpanFromRequest("testHandler", nil, r)
+		defer span.End()
+
+		handlerRequestID = logging.RequestID(r.Context())
+		logger.InfoContext(tracer.ContextWithSpan(r.Context(), span), "handling")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte("{}"))
+	}))
+
+	request := httptest.NewRequest(http.MethodPut, "/api/reservationGroup/1/accept", nil)
+	request.Header.Set(logging.RequestIDHeader, "checkout-42")
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	recorder := httptest.NewRecorder()
+
+	// When
+	handler.ServeHTTP(recorder, request)
+
+	// Then
+	assert.Equal(t, "checkout-42", handlerRequestID)
+	assert.Equal(t, "checkout-42", recorder.Header().Get(logging.RequestIDHeader))
+
+	lines := bytes.Split(bytes.TrimSpace(output.Bytes()), []byte("\n"))
+	assert.Equal(t, 2, len(lines))
+
+	var handling, access map[string]interface{}
+	json.Unmarshal(lines[0], &handling)
+	json.Unmarshal(lines[1], &access)
+	assert.Equal(t, "checkout-42", handling["request_id"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", handling["trace_id"])
+	assert.Equal(t, "request handled", access["msg"])
+	assert.Equal(t, "checkout-42", access["request_id"])
+	assert.Equal(t, float64(http.StatusConflict), access["status"])
+	assert.Equal(t, float64(2), access["bytes"])
+	assert.Contains(t, access, "duration_ms")
+}
+
+func TestLoggingMiddleware_GeneratesRequestIDAndRedactsAuthorization(t *testing.T) {
+	// Given
+	output := &bytes.Buffer{}
+	logger := logging.New(output, "debug", "json")
+	handler := logging.Middleware(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	request := httptest.NewRequest(http.MethodGet, "/api/reservationGroup/guest", nil)
+	request.Header.Set(logging.RequestIDHeader, "not\ta valid id")
+	request.Header.Set("Authorization", "Bearer secret-token")
+	recorder := httptest.NewRecorder()
+
+	// When
+	handler.ServeHTTP(recorder, request)
+
+	// Then
+	assert.Len(t, recorder.Header().Get(logging.RequestIDHeader), 32)
+	assert.NotContains(t, output.String(), "secret-token")
+
+	var access struct {
+		Headers map[string]interface{} `json:"headers"`
+	}
+	json.Unmarshal(output.Bytes(), &access)
+	assert.Equal(t, "REDACTED", access.Headers["Authorization"])
+}
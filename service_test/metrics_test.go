@@ -0,0 +1,56 @@
+package service_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/handler"
+	"github.com/windbnb/reservation-service/metrics"
+	"github.com/windbnb/reservation-service/router"
+)
+
+func TestMetricProxy_LabelsByRouteTemplate(t *testing.T) {
+	// Given
+	router := mux.NewRouter()
+	router.HandleFunc("/api/metricsTest/{id}", metrics.MetricProxy(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("not found"))
+	})).Methods("GET")
+
+	// When
+	for _, visitor := range []string{"10.0.0.1:5000", "10.0.0.2:6000"} {
+		request := httptest.NewRequest("GET", "/api/metricsTest/"+visitor, nil)
+		request.RemoteAddr = visitor
+		request.Header.Set("User-Agent", "agent "+visitor)
+		router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	// Then
+	recorder := httptest.NewRecorder()
+	metrics.MetricsHandler().ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(recorder.Body)
+
+	assert.Contains(t, string(body), `http_requests_total{method="GET",route="/api/metricsTest/{id}",status_class="4xx"} 2`)
+	assert.Contains(t, string(body), `http_response_size_bytes_sum{method="GET",route="/api/metricsTest/{id}"} 18`)
+	assert.Contains(t, string(body), `http_requests_in_flight{route="/api/metricsTest/{id}"} 0`)
+	assert.NotContains(t, string(body), "10.0.0.1")
+}
+
+func TestConfigureRouter_RecordsRejectedRequests(t *testing.T) {
+	// Given
+	configuredRouter := router.ConfigureRouter(&handler.Handler{})
+	series := `http_requests_total{method="PUT",route="/api/reservationRequest/{id}/accept",status_class="4xx"}`
+	before := metricValue(series)
+
+	// When
+	recorder := httptest.NewRecorder()
+	configuredRouter.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/api/reservationRequest/42/accept", nil))
+
+	// Then
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, before+1, metricValue(series))
+}
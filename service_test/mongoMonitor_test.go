@@ -0,0 +1,46 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/tracer"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+func TestMongoMonitor_CommandSpans(t *testing.T) {
+	// Given
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	monitor := tracer.MongoMonitor()
+	parent := tracer.StartSpanFromContext(context.Background(), "findReservationRequestRepository")
+	ctx := tracer.ContextWithSpan(context.Background(), parent)
+	find, _ := bson.Marshal(bson.D{{"find", "reservation_request"}, {"filter", bson.D{{"guestID", 3}}}})
+	insert, _ := bson.Marshal(bson.D{{"insert", "reservation_request"}})
+
+	// When
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: find, DatabaseName: "reservation_database", CommandName: "find", RequestID: 1})
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: insert, DatabaseName: "reservation_database", CommandName: "insert", RequestID: 2})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1}})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "insert", RequestID: 2}, Failure: "duplicate key"})
+	parent.End()
+
+	// Then
+	spans := recorder.Ended()
+	assert.Equal(t, 3, len(spans))
+	assert.Equal(t, "mongodb.find", spans[0].Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+	assert.Contains(t, spans[0].Attributes(), semconv.DBMongoDBCollection("reservation_request"))
+	assert.Equal(t, "mongodb.insert", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
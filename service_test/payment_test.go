@@ -0,0 +1,178 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/payment"
+	"github.com/windbnb/reservation-service/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestAcceptReservationRequest_PaymentDeclined(t *testing.T) {
+	// Given
+	statuses := []model.ReservationRequestStatus{}
+	mockRepo := &MockRepo{
+		TransitionStatusFn: func(reservationRequest *model.ReservationRequest, from model.ReservationRequestStatus, to model.ReservationRequestStatus, ctx context.Context) bool {
+			statuses = append(statuses, to)
+			return transitionStatus(reservationRequest, from, to, ctx)
+		},
+		FindReservationRequestFn: func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
+			return &model.ReservationRequest{
+				ID:              reservationRequestID,
+				StartDate:       time.Now().AddDate(0, 0, 10),
+				EndDate:         time.Now().AddDate(0, 0, 12),
+				AccommodationID: 1,
+				GuestID:         2,
+				GuestNumber:     2,
+				Status:          model.SUBMITTED,
+				OwnerID:         1,
+				Price:           &model.PriceBreakdown{Total: 1500},
+			}
+		},
+		FindAcceptedReservationRequestsFn: func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+		UpdateReservationRequestStatusFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			statuses = append(statuses, reservationRequest.Status)
+			return reservationRequest
+		},
+		UpdateReservationRequestPaymentFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+		FindWaitingWaitlistEntriesFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.WaitlistEntry {
+			return &[]model.WaitlistEntry{}
+		},
+	}
+
+	paymentProvider := payment.NewFakePaymentProvider(time.Hour)
+	paymentProvider.DeclineAmountAbove = 1000
+
+	reservationService := service.ReservationRequestService{
+		Repo:            mockRepo,
+		PaymentProvider: paymentProvider,
+	}
+
+	// When
+	reservationRequest, err := reservationService.AcceptReservationRequest(primitive.NewObjectID(), 1, context.Background())
+
+	// Then
+	assert.Nil(t, reservationRequest)
+	assert.EqualError(t, errors.New("Payment authorization failed: insufficient funds"), err.Error())
+	assert.Equal(t, []model.ReservationRequestStatus{model.PAYMENT_PENDING, model.PAYMENT_FAILED}, statuses)
+}
+
+func TestAcceptReservationRequest_PaymentPending(t *testing.T) {
+	// Given
+	var storedPayment *model.Payment
+	mockRepo := &MockRepo{
+		TransitionStatusFn: transitionStatus,
+		FindReservationRequestFn: func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
+			return &model.ReservationRequest{
+				ID:        reservationRequestID,
+				StartDate: time.Now().AddDate(0, 0, 10),
+				EndDate:   time.Now().AddDate(0, 0, 12),
+				Status:    model.SUBMITTED,
+				OwnerID:   1,
+				Price:     &model.PriceBreakdown{Total: 500},
+			}
+		},
+		FindAcceptedReservationRequestsFn: func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+		UpdateReservationRequestStatusFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+		UpdateReservationRequestPaymentFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			storedPayment = reservationRequest.Payment
+			return reservationRequest
+		},
+	}
+
+	paymentProvider := payment.NewFakePaymentProvider(time.Hour)
+	paymentProvider.PendingAmountAbove = 100
+
+	reservationService := service.ReservationRequestService{
+		Repo:            mockRepo,
+		PaymentProvider: paymentProvider,
+	}
+
+	// When
+	reservationRequest, err := reservationService.AcceptReservationRequest(primitive.NewObjectID(), 1, context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, model.PAYMENT_PENDING, reservationRequest.Status)
+	assert.Equal(t, model.AUTHORIZATION_PENDING, storedPayment.Status)
+	assert.Equal(t, 500.0, storedPayment.Amount)
+}
+
+func TestFakePaymentProvider_ExpiredAuthorizationCannotBeCaptured(t *testing.T) {
+	// Given
+	paymentProvider := payment.NewFakePaymentProvider(-time.Minute)
+	authorization, _ := paymentProvider.Authorize(payment.AuthorizationRequest{Reference: "reservation", Amount: 100}, context.Background())
+
+	// When
+	err := paymentProvider.Capture(authorization.ID, 100, context.Background())
+
+	// Then
+	assert.ErrorIs(t, err, payment.ErrAuthorizationExpired)
+}
+
+func TestAcceptReservationRequest_CancelledInTheMeantime(t *testing.T) {
+	// Given
+	paymentUpdated := false
+	mockRepo := &MockRepo{
+		FindReservationRequestFn: func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
+			return &model.ReservationRequest{
+				ID:        reservationRequestID,
+				StartDate: time.Now().AddDate(0, 0, 10),
+				EndDate:   time.Now().AddDate(0, 0, 12),
+				Status:    model.SUBMITTED,
+				OwnerID:   1,
+				Price:     &model.PriceBreakdown{Total: 500},
+			}
+		},
+		FindAcceptedReservationRequestsFn: func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+		// the guest cancelled the reservation request after it was read
+		TransitionStatusFn: func(reservationRequest *model.ReservationRequest, from model.ReservationRequestStatus, to model.ReservationRequestStatus, ctx context.Context) bool {
+			return false
+		},
+		UpdateReservationRequestPaymentFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			paymentUpdated = true
+			return reservationRequest
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo:            mockRepo,
+		PaymentProvider: payment.NewFakePaymentProvider(time.Hour),
+	}
+
+	// When
+	reservationRequest, err := reservationService.AcceptReservationRequest(primitive.NewObjectID(), 1, context.Background())
+
+	// Then
+	assert.Nil(t, reservationRequest)
+	assert.EqualError(t, err, "You cannot update given reservation request - wrong status.")
+	assert.False(t, paymentUpdated)
+}
+
+func TestFakePaymentProvider_CapturedAuthorizationCannotBeVoided(t *testing.T) {
+	// Given
+	paymentProvider := payment.NewFakePaymentProvider(time.Hour)
+	authorization, _ := paymentProvider.Authorize(payment.AuthorizationRequest{Reference: "reservation", Amount: 100}, context.Background())
+	paymentProvider.Capture(authorization.ID, 100, context.Background())
+
+	// When
+	err := paymentProvider.Void(authorization.ID, context.Background())
+
+	// Then
+	assert.ErrorIs(t, err, payment.ErrAuthorizationCaptured)
+}
@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/service"
+)
+
+func TestCalculatePrice_PerNightWithWeekendUplift(t *testing.T) {
+	// Given
+	accommodationInfo := &model.AccommodationInfo{
+		Price:         100,
+		PricingType:   model.PER_NIGHT,
+		WeekendUplift: 20,
+	}
+	// Thursday
+	startDate := time.Date(2030, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	// When
+	price, err := service.CalculatePrice(accommodationInfo, startDate, 3, 2)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(price.Nights))
+	assert.Equal(t, 120.0, price.Nights[1].Price)
+	assert.Equal(t, 120.0, price.Nights[2].Price)
+	assert.Equal(t, 340.0, price.Total)
+}
+
+func TestCalculatePrice_PerGuestWithSeasonalRateAndDiscount(t *testing.T) {
+	// Given
+	startDate := time.Date(2030, 7, 1, 0, 0, 0, 0, time.UTC)
+	accommodationInfo := &model.AccommodationInfo{
+		Price:       30,
+		PricingType: model.PER_GUEST,
+		SeasonalRates: []model.SeasonalRate{
+			{StartDate: startDate.AddDate(0, 0, 2), EndDate: startDate.AddDate(0, 1, 0), Price: 50},
+		},
+		LengthOfStayDiscounts: []model.LengthOfStayDiscount{
+			{MinimumNights: 3, Percentage: 5},
+			{MinimumNights: 4, Percentage: 10},
+			{MinimumNights: 7, Percentage: 20},
+		},
+	}
+
+	// When
+	price, err := service.CalculatePrice(accommodationInfo, startDate, 4, 2)
+
+	// Then
+	assert.Nil(t, err)
+	assert.False(t, price.Nights[1].Seasonal)
+	assert.True(t, price.Nights[2].Seasonal)
+	assert.Equal(t, 320.0, price.Subtotal)
+	assert.Equal(t, 10.0, price.DiscountPercentage)
+	assert.Equal(t, 288.0, price.Total)
+}
+
+func TestCalculatePrice_TooManyGuests(t *testing.T) {
+	// Given
+	accommodationInfo := &model.AccommodationInfo{
+		Price:         100,
+		MinimimGuests: 1,
+		MaximumGuests: 4,
+	}
+
+	// When
+	_, err := service.CalculatePrice(accommodationInfo, time.Now().AddDate(0, 0, 1), 2, 5)
+
+	// Then
+	assert.EqualError(t, errors.New("Number of guests is not allowed for given accommodation"), err.Error())
+}
@@ -0,0 +1,100 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReconcileWithAccommodationService(t *testing.T) {
+	// Given
+	reservedTerms := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/reservedTerm"):
+			reservedTerms++
+			json.NewEncoder(w).Encode(model.ReservedTermResponse{Id: 40})
+		case strings.HasSuffix(r.URL.Path, "/7"):
+			json.NewEncoder(w).Encode(model.AccommodationInfo{Id: 7, UserID: 1})
+		case strings.HasSuffix(r.URL.Path, "/8"):
+			json.NewEncoder(w).Encode(model.AccommodationInfo{Id: 8, UserID: 2})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	useAccommodationService(t, server.URL)
+
+	consistent := model.ReservationRequest{ID: primitive.NewObjectID(), AccommodationID: 7, OwnerID: 1, ReservedTermId: 30, Status: model.ACCEPTED}
+	unrecordedTerm := model.ReservationRequest{ID: primitive.NewObjectID(), AccommodationID: 7, OwnerID: 1, Status: model.CHECKED_IN}
+	wrongOwner := model.ReservationRequest{ID: primitive.NewObjectID(), AccommodationID: 8, OwnerID: 1, ReservedTermId: 31, Status: model.ACCEPTED}
+	deleted := model.ReservationRequest{ID: primitive.NewObjectID(), AccommodationID: 9, OwnerID: 1, ReservedTermId: 32, Status: model.ACCEPTED}
+
+	var filtered model.ReservationRequestFilter
+	var updated model.ReservationRequest
+	reservationService := service.ReservationRequestService{
+		Repo: &MockRepo{
+			FindReservationRequestsFn: func(filter model.ReservationRequestFilter, ctx context.Context) *[]model.ReservationRequest {
+				filtered = filter
+				return &[]model.ReservationRequest{consistent, unrecordedTerm, wrongOwner, deleted}
+			},
+			UpdateReservedTermFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+				updated = *reservationRequest
+				return reservationRequest
+			},
+		},
+	}
+
+	// When
+	report := reservationService.ReconcileWithAccommodationService(true, context.Background())
+
+	// Then
+	assert.Equal(t, model.ActiveStatuses, filtered.Statuses)
+	assert.Equal(t, 4, report.Checked)
+	assert.Equal(t, 1, report.Repaired)
+	assert.Equal(t, 1, reservedTerms)
+	assert.Equal(t, uint(40), updated.ReservedTermId)
+	assert.Equal(t, []model.ReconciliationIssue{
+		{Type: model.RESERVED_TERM_NOT_RECORDED, ReservationRequestID: unrecordedTerm.ID.Hex(), AccommodationID: 7, Repaired: true},
+		{Type: model.OWNER_MISMATCH, ReservationRequestID: wrongOwner.ID.Hex(), AccommodationID: 8, Detail: "accommodation is owned by host 2"},
+		{Type: model.ACCOMMODATION_NOT_FOUND, ReservationRequestID: deleted.ID.Hex(), AccommodationID: 9},
+	}, report.Issues)
+}
+
+func TestReconcileWithAccommodationService_WithoutRepairOnlyReports(t *testing.T) {
+	// Given
+	reservedTerms := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			reservedTerms++
+		}
+		json.NewEncoder(w).Encode(model.AccommodationInfo{Id: 7, UserID: 1})
+	}))
+	defer server.Close()
+	useAccommodationService(t, server.URL)
+
+	reservationService := service.ReservationRequestService{
+		Repo: &MockRepo{
+			FindReservationRequestsFn: func(filter model.ReservationRequestFilter, ctx context.Context) *[]model.ReservationRequest {
+				return &[]model.ReservationRequest{{ID: primitive.NewObjectID(), AccommodationID: 7, OwnerID: 1, Status: model.ACCEPTED}}
+			},
+		},
+	}
+
+	// When
+	report := reservationService.ReconcileWithAccommodationService(false, context.Background())
+
+	// Then
+	assert.Equal(t, 0, reservedTerms)
+	assert.Equal(t, 0, report.Repaired)
+	assert.Len(t, report.Issues, 1)
+	assert.Equal(t, model.RESERVED_TERM_NOT_RECORDED, report.Issues[0].Type)
+}
@@ -0,0 +1,175 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/payment"
+	"github.com/windbnb/reservation-service/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCalculateRefund_ModeratePolicy(t *testing.T) {
+	// Given
+	startDate := time.Date(2030, 5, 10, 0, 0, 0, 0, time.UTC)
+	reservationRequest := &model.ReservationRequest{
+		StartDate:          startDate,
+		CancellationPolicy: model.MODERATE,
+		Price:              &model.PriceBreakdown{Total: 500},
+	}
+
+	// When
+	earlyRefund := service.CalculateRefund(reservationRequest, startDate.AddDate(0, 0, -6))
+	lateRefund := service.CalculateRefund(reservationRequest, startDate.AddDate(0, 0, -2))
+	lastMinuteRefund := service.CalculateRefund(reservationRequest, startDate.Add(-time.Hour))
+
+	// Then
+	assert.Equal(t, 500.0, earlyRefund.Amount)
+	assert.Equal(t, 0.0, earlyRefund.Penalty)
+	assert.Equal(t, 250.0, lateRefund.Amount)
+	assert.Equal(t, 250.0, lateRefund.Penalty)
+	assert.Equal(t, 0.0, lastMinuteRefund.Amount)
+	assert.Equal(t, 500.0, lastMinuteRefund.Penalty)
+}
+
+func TestCalculateRefund_WithoutPriceSnapshot(t *testing.T) {
+	// Given
+	reservationRequest := &model.ReservationRequest{StartDate: time.Now().AddDate(0, 1, 0)}
+
+	// When
+	refund := service.CalculateRefund(reservationRequest, time.Now())
+
+	// Then
+	assert.Equal(t, model.FLEXIBLE, refund.Policy)
+	assert.Equal(t, 0.0, refund.Amount)
+	assert.Equal(t, 0.0, refund.Penalty)
+}
+
+func TestCancelReservationRequest_CallerGone(t *testing.T) {
+	// Given
+	mockRepo := &MockRepo{
+		FindReservationRequestFn: func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
+			return &model.ReservationRequest{ID: reservationRequestID, GuestID: 3, Status: model.ACCEPTED, StartDate: time.Now().AddDate(0, 0, 7), EndDate: time.Now().AddDate(0, 0, 10)}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// When
+	reservationRequest, err := reservationService.CancelReservationRequest(primitive.NewObjectID(), 3, ctx)
+
+	// Then
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, reservationRequest)
+}
+
+func TestCancelReservationRequest_CallerGoneAfterCancelling(t *testing.T) {
+	// Given
+	ctx, cancel := context.WithCancel(context.Background())
+	writeErrors := []error{}
+	mockRepo := &MockRepo{
+		FindReservationRequestFn: func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
+			return &model.ReservationRequest{ID: reservationRequestID, GuestID: 3, Status: model.ACCEPTED, StartDate: time.Now().AddDate(0, 0, 7), EndDate: time.Now().AddDate(0, 0, 10), Price: &model.PriceBreakdown{Total: 300}, CancellationPolicy: model.FLEXIBLE}
+		},
+		UpdateReservationRequestStatusFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			// the guest disconnects once the cancellation is written
+			cancel()
+			writeErrors = append(writeErrors, ctx.Err())
+			return reservationRequest
+		},
+		UpdateReservationRequestRefundFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			writeErrors = append(writeErrors, ctx.Err())
+			return reservationRequest
+		},
+		SaveEventFn: func(event *model.Event, ctx context.Context) *model.Event {
+			writeErrors = append(writeErrors, ctx.Err())
+			return event
+		},
+		FindWaitingWaitlistEntriesFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.WaitlistEntry {
+			writeErrors = append(writeErrors, ctx.Err())
+			return &[]model.WaitlistEntry{}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	reservationRequest, err := reservationService.CancelReservationRequest(primitive.NewObjectID(), 3, ctx)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, model.CANCELLED, reservationRequest.Status)
+	assert.Equal(t, 300.0, reservationRequest.Refund.Amount)
+	assert.Equal(t, []error{nil, nil, nil, nil}, writeErrors)
+}
+
+func TestCancelReservationRequest_PaymentPending(t *testing.T) {
+	// Given
+	paymentProvider := payment.NewFakePaymentProvider(time.Hour)
+	paymentProvider.PendingAmountAbove = 100
+	authorization, _ := paymentProvider.Authorize(payment.AuthorizationRequest{Reference: "pending", GuestID: 3, Amount: 300}, context.Background())
+
+	var savedEvent model.ReservationCancelledEvent
+	var payments []model.PaymentStatus
+	mockRepo := &MockRepo{
+		FindReservationRequestFn: func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
+			return &model.ReservationRequest{
+				ID:                 reservationRequestID,
+				GuestID:            3,
+				Status:             model.PAYMENT_PENDING,
+				StartDate:          time.Now().AddDate(0, 0, 1),
+				EndDate:            time.Now().AddDate(0, 0, 3),
+				Price:              &model.PriceBreakdown{Total: 300},
+				CancellationPolicy: model.STRICT,
+				Payment:            &model.Payment{AuthorizationID: authorization.ID, Status: authorization.Status, Amount: 300, ExpiresAt: authorization.ExpiresAt},
+			}
+		},
+		UpdateReservationRequestStatusFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+		UpdateReservationRequestPaymentFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			payments = append(payments, reservationRequest.Payment.Status)
+			return reservationRequest
+		},
+		UpdateReservationRequestRefundFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+		SaveEventFn: func(event *model.Event, ctx context.Context) *model.Event {
+			json.Unmarshal([]byte(event.Payload), &savedEvent)
+			return event
+		},
+		FindWaitingWaitlistEntriesFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.WaitlistEntry {
+			return &[]model.WaitlistEntry{}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo:            mockRepo,
+		PaymentProvider: paymentProvider,
+	}
+
+	// When
+	reservationRequest, err := reservationService.CancelReservationRequest(primitive.NewObjectID(), 3, context.Background())
+	paymentProvider.Confirm(authorization.ID)
+	captureErr := paymentProvider.Capture(authorization.ID, 300, context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, model.CANCELLED, reservationRequest.Status)
+	assert.Equal(t, []model.PaymentStatus{model.VOIDED}, payments)
+	assert.Equal(t, 0.0, reservationRequest.Refund.Amount)
+	assert.Equal(t, 0.0, reservationRequest.Refund.Penalty)
+	assert.Equal(t, 0.0, savedEvent.RefundAmount)
+	assert.ErrorIs(t, captureErr, payment.ErrAuthorizationVoided)
+}
@@ -0,0 +1,127 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/service"
+	"github.com/windbnb/reservation-service/util"
+)
+
+var seedOptions = service.SeedOptions{
+	Run:            "load-test",
+	Count:          400,
+	Accommodations: 6,
+	Guests:         20,
+	From:           time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+	Days:           120,
+	TimeZone:       "Europe/Belgrade",
+	NightlyPrice:   80,
+}
+
+// seedSignature leaves out the calculation time of the price, which is not generated.
+func seedSignature(reservationRequest *model.ReservationRequest) []any {
+	return []any{reservationRequest.AccommodationID, reservationRequest.GuestID, reservationRequest.CheckInDate,
+		reservationRequest.CheckOutDate, reservationRequest.Status, reservationRequest.Price.Total, reservationRequest.CreatedAt}
+}
+
+func TestGenerateReservationRequests_ConsistentAndRepeatable(t *testing.T) {
+	// Given
+	now := time.Date(2030, 2, 15, 12, 0, 0, 0, time.UTC)
+
+	// When
+	reservationRequests, err := service.GenerateReservationRequests(seedOptions, rand.New(rand.NewSource(42)), now)
+	again, _ := service.GenerateReservationRequests(seedOptions, rand.New(rand.NewSource(42)), now)
+
+	// Then
+	assert.Nil(t, err)
+	assert.Len(t, reservationRequests, 400)
+	for i, reservationRequest := range reservationRequests {
+		assert.Equal(t, seedSignature(again[i]), seedSignature(reservationRequest))
+	}
+
+	heldNights := map[uint]map[time.Time]bool{}
+	for _, reservationRequest := range reservationRequests {
+		assert.Equal(t, uint(1+(reservationRequest.AccommodationID-1)/3), reservationRequest.OwnerID)
+		assert.Greater(t, reservationRequest.GuestID, uint(2))
+		assert.Equal(t, "Europe/Belgrade", reservationRequest.TimeZone)
+		assert.Equal(t, reservationRequest.StartDate.Format(util.DateLayout), reservationRequest.CheckInDate)
+		assert.NotNil(t, reservationRequest.Price)
+		assert.Equal(t, "load-test", reservationRequest.SeedRun)
+		assert.False(t, reservationRequest.CreatedAt.After(now))
+
+		if reservationRequest.EndDate.Before(now) {
+			assert.NotContains(t, []model.ReservationRequestStatus{model.SUBMITTED, model.ACCEPTED, model.CHECKED_IN}, reservationRequest.Status)
+		}
+		if reservationRequest.StartDate.After(now) {
+			assert.NotContains(t, []model.ReservationRequestStatus{model.CHECKED_IN, model.COMPLETED, model.NO_SHOW}, reservationRequest.Status)
+		}
+
+		switch reservationRequest.Status {
+		case model.ACCEPTED, model.CHECKED_IN, model.COMPLETED, model.NO_SHOW:
+			if heldNights[reservationRequest.AccommodationID] == nil {
+				heldNights[reservationRequest.AccommodationID] = map[time.Time]bool{}
+			}
+			checkIn, _ := time.Parse(util.DateLayout, reservationRequest.CheckInDate)
+			checkOut, _ := time.Parse(util.DateLayout, reservationRequest.CheckOutDate)
+			for _, night := range util.Nights(checkIn, checkOut) {
+				assert.False(t, heldNights[reservationRequest.AccommodationID][night], "overlapping stays in accommodation %d", reservationRequest.AccommodationID)
+				heldNights[reservationRequest.AccommodationID][night] = true
+			}
+		}
+	}
+}
+
+func TestSeedReservationRequests_SavesInBatches(t *testing.T) {
+	// Given
+	options := seedOptions
+	options.Count = 1200
+	batches := []int{}
+	reservationService := service.ReservationRequestService{
+		Repo: &MockRepo{
+			SaveReservationRequestsFn: func(reservationRequests []*model.ReservationRequest, ctx context.Context) bool {
+				batches = append(batches, len(reservationRequests))
+				return len(batches) < 3
+			},
+		},
+	}
+
+	// When
+	saved, err := reservationService.SeedReservationRequests(options, rand.New(rand.NewSource(1)), context.Background())
+
+	// Then
+	assert.NotNil(t, err)
+	assert.Equal(t, 1000, saved)
+	assert.Equal(t, []int{500, 500, 200}, batches)
+}
+
+func TestRemoveSeededReservationRequests(t *testing.T) {
+	// Given
+	removedRuns := []string{}
+	reservationService := service.ReservationRequestService{
+		Repo: &MockRepo{
+			DeleteSeededReservationRequestsFn: func(seedRun string, ctx context.Context) (int, error) {
+				removedRuns = append(removedRuns, seedRun)
+				if seedRun == "unreachable" {
+					return 0, errors.New("server selection timeout")
+				}
+				return 400, nil
+			},
+		},
+	}
+
+	// When
+	removed, err := reservationService.RemoveSeededReservationRequests("load-test", context.Background())
+	_, failedErr := reservationService.RemoveSeededReservationRequests("unreachable", context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, 400, removed)
+	assert.EqualError(t, failedErr, "Removing seeded reservation requests failed")
+	assert.Equal(t, []string{"load-test", "unreachable"}, removedRuns)
+}
@@ -0,0 +1,173 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/service"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func weeklySeriesRequest(start time.Time, skipConflicts bool) *model.CreateReservationRequest {
+	return &model.CreateReservationRequest{
+		CheckInDate:     start.Format(util.DateLayout),
+		NumberOfDays:    3,
+		AccommodationID: 7,
+		GuestID:         3,
+		GuestNumber:     1,
+		Recurrence:      &model.RecurrenceSpec{Frequency: model.WEEKLY, Count: 4},
+		SkipConflicts:   skipConflicts,
+	}
+}
+
+func serveWeeklySeriesAccommodation(t *testing.T, start time.Time) {
+	// the third week is not available
+	serveAccommodation(t, model.AccommodationInfo{
+		Id:     7,
+		UserID: 1,
+		AvailableTerms: []model.AvailableTerm{
+			{StartDate: start, EndDate: start.AddDate(0, 0, 14)},
+			{StartDate: start.AddDate(0, 0, 21), EndDate: start.AddDate(0, 0, 28)},
+		},
+	})
+}
+
+func TestSaveReservationSeries_ConflictsReported(t *testing.T) {
+	// Given
+	start := util.StartOfDay(time.Now()).AddDate(0, 0, 10)
+	serveWeeklySeriesAccommodation(t, start)
+
+	saved := false
+	reservationService := service.ReservationRequestService{
+		Repo: groupMockRepo(&saved),
+	}
+
+	// When
+	reservationSeries, err := reservationService.SaveReservationSeries(weeklySeriesRequest(start, false), context.Background())
+
+	// Then
+	var conflictsError *service.SeriesConflictsError
+	assert.Nil(t, reservationSeries)
+	assert.True(t, errors.As(err, &conflictsError))
+	assert.Equal(t, []model.OccurrenceConflict{
+		{CheckInDate: start.AddDate(0, 0, 14).Format(util.DateLayout), Message: "Accommodation is not available"},
+	}, conflictsError.Conflicts)
+	assert.False(t, saved)
+}
+
+func TestSaveReservationSeries_SkipConflicts(t *testing.T) {
+	// Given
+	start := util.StartOfDay(time.Now()).AddDate(0, 0, 10)
+	serveWeeklySeriesAccommodation(t, start)
+
+	saved := false
+	reservationService := service.ReservationRequestService{
+		Repo: groupMockRepo(&saved),
+	}
+
+	// When
+	reservationSeries, err := reservationService.SaveReservationSeries(weeklySeriesRequest(start, true), context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	assert.True(t, saved)
+	assert.Equal(t, 3, len(reservationSeries.ReservationRequests))
+	assert.Equal(t, 1, len(reservationSeries.Conflicts))
+	for i, checkInDate := range []time.Time{start, start.AddDate(0, 0, 7), start.AddDate(0, 0, 21)} {
+		assert.Equal(t, checkInDate.Format(util.DateLayout), reservationSeries.ReservationRequests[i].CheckInDate)
+		assert.Equal(t, reservationSeries.ID, *reservationSeries.ReservationRequests[i].SeriesID)
+		assert.Equal(t, model.SUBMITTED, reservationSeries.ReservationRequests[i].Status)
+	}
+}
+
+func TestSaveReservationSeries_MonthlyKeepsEndOfMonth(t *testing.T) {
+	// Given
+	year := time.Now().Year() + 1
+	for year%4 == 0 {
+		year++
+	}
+	start := time.Date(year, time.January, 31, 0, 0, 0, 0, time.UTC)
+	serveAccommodation(t, model.AccommodationInfo{
+		Id:             7,
+		UserID:         1,
+		AvailableTerms: []model.AvailableTerm{{StartDate: start, EndDate: start.AddDate(1, 0, 0)}},
+	})
+
+	saved := false
+	reservationService := service.ReservationRequestService{
+		Repo: groupMockRepo(&saved),
+	}
+
+	// When
+	reservationSeries, err := reservationService.SaveReservationSeries(&model.CreateReservationRequest{
+		CheckInDate:     start.Format(util.DateLayout),
+		NumberOfDays:    2,
+		AccommodationID: 7,
+		GuestID:         3,
+		GuestNumber:     1,
+		Recurrence:      &model.RecurrenceSpec{Frequency: model.MONTHLY, Until: start.AddDate(0, 3, 0).Format(util.DateLayout)},
+	}, context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	checkInDates := []string{}
+	for _, reservationRequest := range reservationSeries.ReservationRequests {
+		checkInDates = append(checkInDates, reservationRequest.CheckInDate[5:])
+	}
+	assert.Equal(t, []string{"01-31", "02-28", "03-31", "04-30"}, checkInDates)
+}
+
+func TestCancelReservationSeries(t *testing.T) {
+	// Given
+	seriesID := primitive.NewObjectID()
+	accepted := primitive.NewObjectID()
+	submitted := primitive.NewObjectID()
+	completed := primitive.NewObjectID()
+	deleted := []primitive.ObjectID{}
+	statuses := map[primitive.ObjectID]model.ReservationRequestStatus{}
+	mockRepo := &MockRepo{
+		FindSeriesReservationRequestsFn: func(seriesID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{ID: completed, SeriesID: &seriesID, GuestID: 3, Status: model.COMPLETED, StartDate: time.Now().AddDate(0, 0, -7), EndDate: time.Now().AddDate(0, 0, -4)},
+				{ID: accepted, SeriesID: &seriesID, GuestID: 3, Status: model.ACCEPTED, StartDate: time.Now().AddDate(0, 0, 7), EndDate: time.Now().AddDate(0, 0, 10), Price: &model.PriceBreakdown{Total: 300}, CancellationPolicy: model.FLEXIBLE},
+				{ID: submitted, SeriesID: &seriesID, GuestID: 3, Status: model.SUBMITTED, StartDate: time.Now().AddDate(0, 0, 14), EndDate: time.Now().AddDate(0, 0, 17)},
+			}
+		},
+		UpdateReservationRequestStatusFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			statuses[reservationRequest.ID] = reservationRequest.Status
+			return reservationRequest
+		},
+		UpdateReservationRequestRefundFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+		DeleteReservationRequestFn: func(reservationRequestID primitive.ObjectID, ctx context.Context) bool {
+			deleted = append(deleted, reservationRequestID)
+			return true
+		},
+		SaveEventFn: func(event *model.Event, ctx context.Context) *model.Event {
+			return event
+		},
+		FindWaitingWaitlistEntriesFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.WaitlistEntry {
+			return &[]model.WaitlistEntry{}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	reservationSeries, err := reservationService.CancelReservationSeries(seriesID, 3, context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, map[primitive.ObjectID]model.ReservationRequestStatus{accepted: model.CANCELLED}, statuses)
+	assert.Equal(t, []primitive.ObjectID{submitted}, deleted)
+	assert.Equal(t, 2, len(reservationSeries.ReservationRequests))
+	assert.Equal(t, model.COMPLETED, reservationSeries.ReservationRequests[0].Status)
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/config"
 	"github.com/windbnb/reservation-service/model"
 	"github.com/windbnb/reservation-service/repository"
 	"github.com/windbnb/reservation-service/service"
 	"github.com/windbnb/reservation-service/util"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 	"testing"
 	"time"
 )
@@ -198,9 +200,16 @@ func TestDeleteReservationRequest_Successfully(t *testing.T) {
 	assert.Equal(t, nil, reservationRequest)
 }
 
+// connectToDatabase connects to the database the service is configured with.
+func connectToDatabase() *mongo.Database {
+	cfg, _ := config.Load()
+	db, _ := util.ConnectToDatabase(cfg.Database.URI, cfg.Database.Name, cfg.Database.ConnectTimeout, context.Background())
+	return db
+}
+
 func TestDeleteReservationRequest_DoesNotExist_Integration(t *testing.T) {
 	// Given
-	db := util.ConnectToDatabase()
+	db := connectToDatabase()
 
 	reservationService := service.ReservationRequestService{
 		Repo: &repository.Repository{
@@ -217,7 +226,7 @@ func TestDeleteReservationRequest_DoesNotExist_Integration(t *testing.T) {
 
 func TestDeleteReservationRequest_WrongStatus_Integration(t *testing.T) {
 	// Given
-	db := util.ConnectToDatabase()
+	db := connectToDatabase()
 
 	repo := &repository.Repository{
 		Db: db,
@@ -251,7 +260,7 @@ func TestDeleteReservationRequest_WrongStatus_Integration(t *testing.T) {
 
 func TestDeleteReservationRequest_Successfully_Integration(t *testing.T) {
 	// Given
-	db := util.ConnectToDatabase()
+	db := connectToDatabase()
 
 	repo := &repository.Repository{
 		Db: db,
@@ -285,8 +294,43 @@ func TestDeleteReservationRequest_Successfully_Integration(t *testing.T) {
 
 type MockRepo struct {
 	repository.Repository
-	FindReservationRequestFn   func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest
-	DeleteReservationRequestFn func(reservationRequestID primitive.ObjectID, ctx context.Context) bool
+	FindReservationRequestFn          func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest
+	DeleteReservationRequestFn        func(reservationRequestID primitive.ObjectID, ctx context.Context) bool
+	FindAcceptedReservationRequestsFn func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest
+	UpdateReservationRequestStatusFn  func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest
+	UpdateReservationRequestPaymentFn func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest
+	FindExpiredSubmittedFn            func(submittedBefore time.Time, startingBefore time.Time, ctx context.Context) *[]model.ReservationRequest
+	ExpireReservationRequestFn        func(reservationRequest *model.ReservationRequest, ctx context.Context) bool
+	TransitionStatusFn                func(reservationRequest *model.ReservationRequest, from model.ReservationRequestStatus, to model.ReservationRequestStatus, ctx context.Context) bool
+	SaveEventFn                       func(event *model.Event, ctx context.Context) *model.Event
+	UpdateReservationRequestStayFn    func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest
+	FindCompletedStaysFn              func(eligibilityRequests []model.RatingEligibilityRequest, ctx context.Context) *[]model.ReservationRequest
+	FindReservationRequestsInPeriodFn func(accommodationID uint, from time.Time, to time.Time, statuses []model.ReservationRequestStatus, ctx context.Context) *[]model.ReservationRequest
+	FindBlockedPeriodsFn              func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.BlockedPeriod
+	FindCalendarImportsFn             func(accommodationID uint, ctx context.Context) *[]model.CalendarImport
+	ReplaceBlockedPeriodsFn           func(calendarImport *model.CalendarImport, blockedPeriods []model.BlockedPeriod, ctx context.Context) bool
+	UpdateCalendarImportSyncFn        func(calendarImport *model.CalendarImport, ctx context.Context) *model.CalendarImport
+	FindUnavailableAccommodationsFn   func(accommodationIDs []uint, from time.Time, to time.Time, ctx context.Context) *[]uint
+	FindWaitingWaitlistEntriesFn      func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.WaitlistEntry
+	FindGuestsWaitlistEntriesFn       func(guestID uint, ctx context.Context) *[]model.WaitlistEntry
+	SaveWaitlistEntryFn               func(waitlistEntry *model.WaitlistEntry, ctx context.Context) *model.WaitlistEntry
+	UpdateWaitlistEntryFn             func(waitlistEntry *model.WaitlistEntry, ctx context.Context) *model.WaitlistEntry
+	SaveReservationRequestFn          func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest
+	FindBookingRulesFn                func(accommodationID uint, ctx context.Context) *model.BookingRules
+	SaveReservationRequestsFn         func(reservationRequests []*model.ReservationRequest, ctx context.Context) bool
+	DeleteSeededReservationRequestsFn func(seedRun string, ctx context.Context) (int, error)
+	FindGroupReservationRequestsFn    func(groupID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest
+	AcceptReservationRequestFn        func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest
+	FindSeriesReservationRequestsFn   func(seriesID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest
+	UpdateReservationRequestRefundFn  func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest
+	FindCompetingFn                   func(reservationRequest *model.ReservationRequest, ctx context.Context) *[]model.ReservationRequest
+	CountSubmittedByOwnerFn           func(ctx context.Context) *map[uint]int
+	FindUnpublishedEventsFn           func(limit int64, ctx context.Context) *[]model.Event
+	MarkEventPublishedFn              func(event *model.Event, ctx context.Context) bool
+	RecordEventAttemptFn              func(event *model.Event, ctx context.Context) bool
+	ParkEventFn                       func(event *model.Event, ctx context.Context) bool
+	FindReservationRequestsFn         func(filter model.ReservationRequestFilter, ctx context.Context) *[]model.ReservationRequest
+	UpdateReservedTermFn              func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest
 }
 
 func (m *MockRepo) FindReservationRequest(reservationRequestId primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
@@ -296,3 +340,153 @@ func (m *MockRepo) FindReservationRequest(reservationRequestId primitive.ObjectI
 func (m *MockRepo) DeleteReservationRequest(reservationRequestID primitive.ObjectID, ctx context.Context) bool {
 	return m.DeleteReservationRequestFn(reservationRequestID, ctx)
 }
+
+func (m *MockRepo) FindAcceptedReservationRequests(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+	return m.FindAcceptedReservationRequestsFn(accomodationId, ctx)
+}
+
+func (m *MockRepo) UpdateReservationRequestStatus(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+	return m.UpdateReservationRequestStatusFn(reservationRequest, ctx)
+}
+
+func (m *MockRepo) UpdateReservationRequestPayment(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+	return m.UpdateReservationRequestPaymentFn(reservationRequest, ctx)
+}
+
+func (m *MockRepo) FindExpiredSubmittedReservationRequests(submittedBefore time.Time, startingBefore time.Time, ctx context.Context) *[]model.ReservationRequest {
+	return m.FindExpiredSubmittedFn(submittedBefore, startingBefore, ctx)
+}
+
+func (m *MockRepo) ExpireReservationRequest(reservationRequest *model.ReservationRequest, ctx context.Context) bool {
+	return m.ExpireReservationRequestFn(reservationRequest, ctx)
+}
+
+func (m *MockRepo) TransitionReservationRequestStatus(reservationRequest *model.ReservationRequest, from model.ReservationRequestStatus, to model.ReservationRequestStatus, ctx context.Context) bool {
+	return m.TransitionStatusFn(reservationRequest, from, to, ctx)
+}
+
+func (m *MockRepo) SaveEvent(event *model.Event, ctx context.Context) *model.Event {
+	return m.SaveEventFn(event, ctx)
+}
+
+func (m *MockRepo) UpdateReservationRequestStay(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+	return m.UpdateReservationRequestStayFn(reservationRequest, ctx)
+}
+
+func (m *MockRepo) FindCompletedStays(eligibilityRequests []model.RatingEligibilityRequest, ctx context.Context) *[]model.ReservationRequest {
+	return m.FindCompletedStaysFn(eligibilityRequests, ctx)
+}
+
+func (m *MockRepo) FindReservationRequestsInPeriod(accommodationID uint, from time.Time, to time.Time, statuses []model.ReservationRequestStatus, ctx context.Context) *[]model.ReservationRequest {
+	return m.FindReservationRequestsInPeriodFn(accommodationID, from, to, statuses, ctx)
+}
+
+func (m *MockRepo) FindBlockedPeriods(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.BlockedPeriod {
+	return m.FindBlockedPeriodsFn(accommodationID, from, to, ctx)
+}
+
+func (m *MockRepo) FindCalendarImports(accommodationID uint, ctx context.Context) *[]model.CalendarImport {
+	return m.FindCalendarImportsFn(accommodationID, ctx)
+}
+
+func (m *MockRepo) ReplaceBlockedPeriods(calendarImport *model.CalendarImport, blockedPeriods []model.BlockedPeriod, ctx context.Context) bool {
+	return m.ReplaceBlockedPeriodsFn(calendarImport, blockedPeriods, ctx)
+}
+
+func (m *MockRepo) UpdateCalendarImportSync(calendarImport *model.CalendarImport, ctx context.Context) *model.CalendarImport {
+	return m.UpdateCalendarImportSyncFn(calendarImport, ctx)
+}
+
+func (m *MockRepo) FindUnavailableAccommodations(accommodationIDs []uint, from time.Time, to time.Time, ctx context.Context) *[]uint {
+	return m.FindUnavailableAccommodationsFn(accommodationIDs, from, to, ctx)
+}
+
+func (m *MockRepo) FindWaitingWaitlistEntries(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.WaitlistEntry {
+	return m.FindWaitingWaitlistEntriesFn(accommodationID, from, to, ctx)
+}
+
+func (m *MockRepo) FindGuestsWaitlistEntries(guestID uint, ctx context.Context) *[]model.WaitlistEntry {
+	return m.FindGuestsWaitlistEntriesFn(guestID, ctx)
+}
+
+func (m *MockRepo) SaveWaitlistEntry(waitlistEntry *model.WaitlistEntry, ctx context.Context) *model.WaitlistEntry {
+	return m.SaveWaitlistEntryFn(waitlistEntry, ctx)
+}
+
+func (m *MockRepo) UpdateWaitlistEntry(waitlistEntry *model.WaitlistEntry, ctx context.Context) *model.WaitlistEntry {
+	return m.UpdateWaitlistEntryFn(waitlistEntry, ctx)
+}
+
+func (m *MockRepo) SaveReservationRequest(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+	return m.SaveReservationRequestFn(reservationRequest, ctx)
+}
+
+func (m *MockRepo) FindBookingRules(accommodationID uint, ctx context.Context) *model.BookingRules {
+	return m.FindBookingRulesFn(accommodationID, ctx)
+}
+
+func (m *MockRepo) SaveReservationRequests(reservationRequests []*model.ReservationRequest, ctx context.Context) bool {
+	return m.SaveReservationRequestsFn(reservationRequests, ctx)
+}
+
+func (m *MockRepo) DeleteSeededReservationRequests(seedRun string, ctx context.Context) (int, error) {
+	return m.DeleteSeededReservationRequestsFn(seedRun, ctx)
+}
+
+func (m *MockRepo) FindGroupReservationRequests(groupID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest {
+	return m.FindGroupReservationRequestsFn(groupID, ctx)
+}
+
+func (m *MockRepo) AcceptReservationRequest(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+	return m.AcceptReservationRequestFn(reservationRequest, ctx)
+}
+
+func (m *MockRepo) FindSeriesReservationRequests(seriesID primitive.ObjectID, ctx context.Context) *[]model.ReservationRequest {
+	return m.FindSeriesReservationRequestsFn(seriesID, ctx)
+}
+
+func (m *MockRepo) UpdateReservationRequestRefund(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+	return m.UpdateReservationRequestRefundFn(reservationRequest, ctx)
+}
+
+func (m *MockRepo) FindCompetingReservationRequests(reservationRequest *model.ReservationRequest, ctx context.Context) *[]model.ReservationRequest {
+	return m.FindCompetingFn(reservationRequest, ctx)
+}
+
+func (m *MockRepo) CountSubmittedReservationRequestsByOwner(ctx context.Context) *map[uint]int {
+	return m.CountSubmittedByOwnerFn(ctx)
+}
+
+func (m *MockRepo) FindUnpublishedEvents(limit int64, ctx context.Context) *[]model.Event {
+	return m.FindUnpublishedEventsFn(limit, ctx)
+}
+
+func (m *MockRepo) MarkEventPublished(event *model.Event, ctx context.Context) bool {
+	return m.MarkEventPublishedFn(event, ctx)
+}
+
+func (m *MockRepo) RecordEventAttempt(event *model.Event, ctx context.Context) bool {
+	return m.RecordEventAttemptFn(event, ctx)
+}
+
+func (m *MockRepo) ParkEvent(event *model.Event, ctx context.Context) bool {
+	return m.ParkEventFn(event, ctx)
+}
+
+func (m *MockRepo) FindReservationRequests(filter model.ReservationRequestFilter, ctx context.Context) *[]model.ReservationRequest {
+	return m.FindReservationRequestsFn(filter, ctx)
+}
+
+func (m *MockRepo) UpdateReservationRequestReservedTerm(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+	return m.UpdateReservedTermFn(reservationRequest, ctx)
+}
+
+// transitionStatus is a TransitionStatusFn for reservation requests nobody changes in the meantime.
+func transitionStatus(reservationRequest *model.ReservationRequest, from model.ReservationRequestStatus, to model.ReservationRequestStatus, ctx context.Context) bool {
+	if reservationRequest.Status != from {
+		return false
+	}
+
+	reservationRequest.Status = to
+	return true
+}
@@ -0,0 +1,161 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/service"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCheckIn_BeforeStayStarts(t *testing.T) {
+	// Given
+	mockRepo := &MockRepo{
+		FindReservationRequestFn: func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
+			return &model.ReservationRequest{
+				ID:        reservationRequestID,
+				StartDate: time.Now().AddDate(0, 0, 3),
+				EndDate:   time.Now().AddDate(0, 0, 5),
+				Status:    model.ACCEPTED,
+				OwnerID:   1,
+			}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	_, err := reservationService.CheckIn(primitive.NewObjectID(), 1, context.Background())
+
+	// Then
+	assert.EqualError(t, errors.New("Guest cannot check in before the stay starts"), err.Error())
+}
+
+func TestCheckOut_Successfully(t *testing.T) {
+	// Given
+	checkedInAt := time.Now().AddDate(0, 0, -2)
+	var updatedReservationRequest *model.ReservationRequest
+	mockRepo := &MockRepo{
+		FindReservationRequestFn: func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
+			return &model.ReservationRequest{
+				ID:          reservationRequestID,
+				StartDate:   checkedInAt,
+				EndDate:     time.Now().AddDate(0, 0, 1),
+				Status:      model.CHECKED_IN,
+				OwnerID:     1,
+				CheckedInAt: &checkedInAt,
+			}
+		},
+		UpdateReservationRequestStayFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			updatedReservationRequest = reservationRequest
+			return reservationRequest
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	reservationRequest, err := reservationService.CheckOut(primitive.NewObjectID(), 1, context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, model.COMPLETED, reservationRequest.Status)
+	assert.NotNil(t, updatedReservationRequest.CheckedOutAt)
+}
+
+func TestCheckOut_AcceptedStay(t *testing.T) {
+	// Given
+	endDate := time.Now().AddDate(0, 0, 1)
+	mockRepo := &MockRepo{
+		FindReservationRequestFn: func(reservationRequestID primitive.ObjectID, ctx context.Context) *model.ReservationRequest {
+			return &model.ReservationRequest{
+				ID:        reservationRequestID,
+				StartDate: time.Now().AddDate(0, 0, -2),
+				EndDate:   endDate,
+				Status:    model.ACCEPTED,
+				OwnerID:   1,
+			}
+		},
+		UpdateReservationRequestStayFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			return reservationRequest
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	_, ongoingErr := reservationService.CheckOut(primitive.NewObjectID(), 1, context.Background())
+	endDate = time.Now().AddDate(0, 0, -1)
+	reservationRequest, endedErr := reservationService.CheckOut(primitive.NewObjectID(), 1, context.Background())
+
+	// Then
+	assert.EqualError(t, ongoingErr, "Guest has to be checked in before the stay ends")
+	assert.Nil(t, endedErr)
+	assert.Equal(t, model.COMPLETED, reservationRequest.Status)
+	assert.Nil(t, reservationRequest.CheckedInAt)
+}
+
+func TestSaveReservationRequest_LocalStayDates(t *testing.T) {
+	// Given
+	checkInDate := util.StartOfDay(time.Now()).AddDate(0, 0, 10)
+	serveAccommodation(t, model.AccommodationInfo{
+		Id:           7,
+		TimeZone:     "Pacific/Honolulu",
+		CheckInTime:  "16:00",
+		CheckOutTime: "11:00",
+		AvailableTerms: []model.AvailableTerm{
+			{StartDate: checkInDate, EndDate: checkInDate.AddDate(0, 0, 10)},
+		},
+	})
+
+	var saved *model.ReservationRequest
+	mockRepo := &MockRepo{
+		FindBookingRulesFn: func(accommodationID uint, ctx context.Context) *model.BookingRules {
+			return &model.BookingRules{AccommodationID: accommodationID}
+		},
+		FindAcceptedReservationRequestsFn: func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+		FindBlockedPeriodsFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.BlockedPeriod {
+			return &[]model.BlockedPeriod{}
+		},
+		SaveReservationRequestFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			saved = reservationRequest
+			return reservationRequest
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	_, err := reservationService.SaveReservationRequest(&model.CreateReservationRequest{
+		CheckInDate:     checkInDate.Format(util.DateLayout),
+		NumberOfDays:    3,
+		AccommodationID: 7,
+		GuestID:         3,
+		GuestNumber:     2,
+	}, context.Background())
+
+	// Then
+	honolulu, _ := time.LoadLocation("Pacific/Honolulu")
+	checkOutDate := checkInDate.AddDate(0, 0, 3)
+	assert.Nil(t, err)
+	assert.Equal(t, "Pacific/Honolulu", saved.TimeZone)
+	assert.Equal(t, checkInDate.Format(util.DateLayout), saved.CheckInDate)
+	assert.Equal(t, checkOutDate.Format(util.DateLayout), saved.CheckOutDate)
+	assert.True(t, time.Date(checkInDate.Year(), checkInDate.Month(), checkInDate.Day(), 16, 0, 0, 0, honolulu).Equal(saved.StartDate))
+	assert.True(t, time.Date(checkOutDate.Year(), checkOutDate.Month(), checkOutDate.Day(), 11, 0, 0, 0, honolulu).Equal(saved.EndDate))
+}
@@ -0,0 +1,43 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/client"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/tracer"
+)
+
+func TestTraceContext_PropagatedToClientCalls(t *testing.T) {
+	// Given
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	traceTracer, closer := tracer.Init("reservation-service")
+	defer closer.Close()
+
+	traceparent := ""
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		json.NewEncoder(w).Encode(model.AccommodationInfo{Id: 7})
+	}))
+	defer server.Close()
+	useAccommodationService(t, server.URL)
+
+	inbound := httptest.NewRequest(http.MethodGet, "/api/reservationRequest/guest", nil)
+	inbound.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// When
+	span := tracer.StartSpanFromRequest("testHandler", traceTracer, inbound)
+	defer span.End()
+	_, err := client.GetAccommodation(7, tracer.ContextWithSpan(context.Background(), span))
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Contains(t, traceparent, "4bf92f3577b34da6a3ce929d0e0e4736")
+}
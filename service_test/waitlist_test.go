@@ -0,0 +1,182 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/windbnb/reservation-service/model"
+	"github.com/windbnb/reservation-service/service"
+	"github.com/windbnb/reservation-service/util"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestJoinWaitlist(t *testing.T) {
+	// Given
+	start := util.StartOfDay(time.Now()).AddDate(0, 0, 10)
+	serveAccommodation(t, model.AccommodationInfo{
+		Id: 7,
+		AvailableTerms: []model.AvailableTerm{
+			{StartDate: start, EndDate: start.AddDate(0, 0, 10)},
+		},
+	})
+
+	var saved *model.WaitlistEntry
+	mockRepo := &MockRepo{
+		FindAcceptedReservationRequestsFn: func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{Status: model.ACCEPTED, StartDate: start.AddDate(0, 0, 1), EndDate: start.AddDate(0, 0, 3)},
+			}
+		},
+		FindBlockedPeriodsFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.BlockedPeriod {
+			return &[]model.BlockedPeriod{}
+		},
+		FindGuestsWaitlistEntriesFn: func(guestID uint, ctx context.Context) *[]model.WaitlistEntry {
+			return &[]model.WaitlistEntry{}
+		},
+		SaveWaitlistEntryFn: func(waitlistEntry *model.WaitlistEntry, ctx context.Context) *model.WaitlistEntry {
+			saved = waitlistEntry
+			return waitlistEntry
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	waitlistEntry, err := reservationService.JoinWaitlist(&model.JoinWaitlistRequest{
+		StartDate:       start,
+		NumberOfDays:    2,
+		AccommodationID: 7,
+		GuestID:         3,
+		GuestNumber:     2,
+	}, context.Background())
+
+	// Then
+	assert.Nil(t, err)
+	assert.Equal(t, saved, waitlistEntry)
+	assert.Equal(t, model.WAITING, waitlistEntry.Status)
+	// check-out is at the default check-out time in the default time zone
+	assert.Equal(t, time.Date(start.Year(), start.Month(), start.Day()+2, 10, 0, 0, 0, time.UTC), waitlistEntry.EndDate)
+}
+
+func TestJoinWaitlist_DatesAvailable(t *testing.T) {
+	// Given
+	start := util.StartOfDay(time.Now()).AddDate(0, 0, 10)
+	serveAccommodation(t, model.AccommodationInfo{
+		Id: 7,
+		AvailableTerms: []model.AvailableTerm{
+			{StartDate: start, EndDate: start.AddDate(0, 0, 10)},
+		},
+	})
+
+	mockRepo := &MockRepo{
+		FindAcceptedReservationRequestsFn: func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{}
+		},
+		FindBlockedPeriodsFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.BlockedPeriod {
+			return &[]model.BlockedPeriod{}
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	_, err := reservationService.JoinWaitlist(&model.JoinWaitlistRequest{
+		StartDate:       start,
+		NumberOfDays:    2,
+		AccommodationID: 7,
+		GuestID:         3,
+		GuestNumber:     2,
+	}, context.Background())
+
+	// Then
+	assert.EqualError(t, err, "Accommodation is available for given dates - reserve it instead")
+}
+
+func TestExpireSubmittedReservationRequests_NotifiesWaitlist(t *testing.T) {
+	// Given
+	start := util.StartOfDay(time.Now()).AddDate(0, 0, 10)
+	serveAccommodation(t, model.AccommodationInfo{
+		Id:     7,
+		UserID: 1,
+		Price:  100,
+		AvailableTerms: []model.AvailableTerm{
+			{StartDate: start, EndDate: start.AddDate(0, 0, 10)},
+		},
+	})
+
+	converted := primitive.NewObjectID()
+	stillTaken := primitive.NewObjectID()
+	notified := primitive.NewObjectID()
+	updated := []model.WaitlistEntry{}
+	notifications := []model.WaitlistNotifiedEvent{}
+	mockRepo := &MockRepo{
+		FindExpiredSubmittedFn: func(submittedBefore time.Time, startingBefore time.Time, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{ID: primitive.NewObjectID(), AccommodationID: 7, Status: model.SUBMITTED, StartDate: start, EndDate: start.AddDate(0, 0, 4)},
+			}
+		},
+		ExpireReservationRequestFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) bool {
+			return true
+		},
+		FindWaitingWaitlistEntriesFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.WaitlistEntry {
+			return &[]model.WaitlistEntry{
+				{ID: converted, GuestID: 3, AccommodationID: 7, GuestNumber: 2, AutoSubmit: true, Status: model.WAITING, StartDate: start, EndDate: start.AddDate(0, 0, 2)},
+				{ID: stillTaken, GuestID: 4, AccommodationID: 7, GuestNumber: 2, Status: model.WAITING, StartDate: start.AddDate(0, 0, 2), EndDate: start.AddDate(0, 0, 5)},
+				{ID: notified, GuestID: 5, AccommodationID: 7, GuestNumber: 2, Status: model.WAITING, StartDate: start.AddDate(0, 0, 1), EndDate: start.AddDate(0, 0, 3)},
+			}
+		},
+		FindAcceptedReservationRequestsFn: func(accomodationId uint, ctx context.Context) *[]model.ReservationRequest {
+			return &[]model.ReservationRequest{
+				{Status: model.ACCEPTED, StartDate: start.AddDate(0, 0, 4), EndDate: start.AddDate(0, 0, 6)},
+			}
+		},
+		FindBlockedPeriodsFn: func(accommodationID uint, from time.Time, to time.Time, ctx context.Context) *[]model.BlockedPeriod {
+			return &[]model.BlockedPeriod{}
+		},
+		FindBookingRulesFn: func(accommodationID uint, ctx context.Context) *model.BookingRules {
+			return &model.BookingRules{AccommodationID: accommodationID}
+		},
+		SaveReservationRequestFn: func(reservationRequest *model.ReservationRequest, ctx context.Context) *model.ReservationRequest {
+			reservationRequest.ID = primitive.NewObjectID()
+			return reservationRequest
+		},
+		UpdateWaitlistEntryFn: func(waitlistEntry *model.WaitlistEntry, ctx context.Context) *model.WaitlistEntry {
+			updated = append(updated, *waitlistEntry)
+			return waitlistEntry
+		},
+		SaveEventFn: func(event *model.Event, ctx context.Context) *model.Event {
+			if event.Type == model.WAITLIST_NOTIFIED {
+				var waitlistNotified model.WaitlistNotifiedEvent
+				json.Unmarshal([]byte(event.Payload), &waitlistNotified)
+				notifications = append(notifications, waitlistNotified)
+			}
+			return event
+		},
+	}
+
+	reservationService := service.ReservationRequestService{
+		Repo: mockRepo,
+	}
+
+	// When
+	reservationService.ExpireSubmittedReservationRequests(context.Background())
+
+	// Then
+	assert.Equal(t, 2, len(updated))
+	assert.Equal(t, converted, updated[0].ID)
+	assert.Equal(t, model.CONVERTED, updated[0].Status)
+	assert.NotNil(t, updated[0].ReservationRequestID)
+	assert.Equal(t, notified, updated[1].ID)
+	assert.Equal(t, model.NOTIFIED, updated[1].Status)
+	assert.Equal(t, 2, len(notifications))
+	assert.Equal(t, uint(3), notifications[0].GuestID)
+	assert.NotEmpty(t, notifications[0].ReservationRequestID)
+	assert.Equal(t, uint(5), notifications[1].GuestID)
+}
@@ -0,0 +1,53 @@
+package tracer
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MongoMonitor returns a command monitor for the Mongo driver recording every command as a client span, a child
+// of the span in the context the command was issued with. Only the command name and collection are recorded,
+// never the command itself, since filters and documents hold guest data.
+func MongoMonitor() *event.CommandMonitor {
+	spans := sync.Map{}
+
+	finish := func(requestID int64, failure string) {
+		value, found := spans.LoadAndDelete(requestID)
+		if !found {
+			return
+		}
+
+		span := value.(trace.Span)
+		if failure != "" {
+			LogError(span, errors.New(failure))
+		}
+		span.End()
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, started *event.CommandStartedEvent) {
+			collection, _ := started.Command.Lookup(started.CommandName).StringValueOK()
+			_, span := otel.Tracer(instrumentationName).Start(ctx, "mongodb."+started.CommandName,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(
+					semconv.DBSystemMongoDB,
+					semconv.DBName(started.DatabaseName),
+					semconv.DBOperation(started.CommandName),
+					semconv.DBMongoDBCollection(collection),
+				))
+			spans.Store(started.RequestID, span)
+		},
+		Succeeded: func(ctx context.Context, succeeded *event.CommandSucceededEvent) {
+			finish(succeeded.RequestID, "")
+		},
+		Failed: func(ctx context.Context, failed *event.CommandFailedEvent) {
+			finish(failed.RequestID, failed.Failure)
+		},
+	}
+}
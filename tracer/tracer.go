@@ -2,81 +2,127 @@ package tracer
 
 import (
 	"context"
-	"fmt"
-	"github.com/uber/jaeger-client-go"
 	"io"
+	"log/slog"
 	"net/http"
+	"os"
+	"strconv"
+	"time"
 
-	opentracing "github.com/opentracing/opentracing-go"
-	"github.com/opentracing/opentracing-go/ext"
-	"github.com/opentracing/opentracing-go/log"
-	"github.com/uber/jaeger-client-go/config"
-	jaegerlog "github.com/uber/jaeger-client-go/log"
-	"github.com/uber/jaeger-lib/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// Init returns an instance of Jaeger Tracer.
-func Init(service string) (opentracing.Tracer, io.Closer) {
-	cfg, err := config.FromEnv()
+const instrumentationName = "github.com/windbnb/reservation-service"
+
+// Init installs an OpenTelemetry tracer provider exporting spans over OTLP/HTTP to the collector configured by
+// the standard OTEL_EXPORTER_OTLP_ENDPOINT or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT variables. Traces are sampled
+// with the ratio in OTEL_TRACES_SAMPLER_ARG unless the caller already decided. Without a collector, or when the
+// exporter cannot be created, spans are still propagated but not recorded. The closer flushes pending spans.
+func Init(service string) (trace.Tracer, io.Closer) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") == "" && os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") == "" {
+		slog.Info("no OTLP endpoint configured, spans will not be exported")
+		return otel.Tracer(instrumentationName), nopCloser{}
+	}
+
+	exporter, err := otlptracehttp.New(context.Background())
 	if err != nil {
-		fmt.Println(err.Error())
-		return nil, nil
+		slog.Warn("creating OTLP exporter failed, spans will not be exported", "error", err)
+		return otel.Tracer(instrumentationName), nopCloser{}
 	}
 
-	cfg.ServiceName = "reservation-service"
-	cfg.Sampler.Type = jaeger.SamplerTypeConst
-	cfg.Sampler.Param = 1        // "const" sampler is a binary sampling strategy: 0=never sample, 1=always sample.
-	cfg.Reporter.LogSpans = true // Log the emitted spans to stdout.
+	serviceResource, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(service)))
+	if err != nil {
+		serviceResource = resource.Default()
+	}
 
-	jLogger := jaegerlog.StdLogger
-	jMetricsFactory := metrics.NullFactory
-	tracer, closer, err := cfg.NewTracer(
-		config.Logger(jLogger),
-		config.Metrics(jMetricsFactory),
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(serviceResource),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(samplingRatio()))),
 	)
-	if err != nil {
-		panic(fmt.Sprintf("ERROR: cannot init Jaeger: %v\n", err))
+	otel.SetTracerProvider(provider)
+
+	return provider.Tracer(instrumentationName), providerCloser{provider: provider}
+}
+
+func samplingRatio() float64 {
+	value, found := os.LookupEnv("OTEL_TRACES_SAMPLER_ARG")
+	if !found {
+		return 1
 	}
-	return tracer, closer
+
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		slog.Warn("invalid sampling ratio for OTEL_TRACES_SAMPLER_ARG, sampling every trace", "value", value)
+		return 1
+	}
+
+	return ratio
 }
 
-// Inject injects the outbound HTTP request with the given span's context to ensure
-// correct propagation of span context throughout the trace.
-func Inject(span opentracing.Span, request *http.Request) error {
-	return span.Tracer().Inject(
-		span.Context(),
-		opentracing.HTTPHeaders,
-		opentracing.HTTPHeadersCarrier(request.Header))
+type providerCloser struct {
+	provider *sdktrace.TracerProvider
+}
+
+func (c providerCloser) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return c.provider.Shutdown(ctx)
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error {
+	return nil
 }
 
-// Extract extracts the inbound HTTP request to obtain the parent span's context to ensure
+// Inject writes the trace context of the span in ctx into the headers of the outbound HTTP request to ensure
 // correct propagation of span context throughout the trace.
-func Extract(tracer opentracing.Tracer, r *http.Request) (opentracing.SpanContext, error) {
-	return tracer.Extract(
-		opentracing.HTTPHeaders,
-		opentracing.HTTPHeadersCarrier(r.Header))
+func Inject(ctx context.Context, request *http.Request) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(request.Header))
+}
+
+// Extract reads the trace context of the caller from the headers of the inbound HTTP request.
+func Extract(r *http.Request) context.Context {
+	return otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
 }
 
 // StartSpanFromRequest extracts the parent span context from the inbound HTTP request
-// and starts a new child span if there is a parent span.
-func StartSpanFromRequest(spanName string, tracer opentracing.Tracer, r *http.Request) opentracing.Span {
-	spanCtx, _ := Extract(tracer, r)
-	return tracer.StartSpan(spanName, ext.RPCServerOption(spanCtx))
+// and starts a new server span, a child of the caller's span if there is one.
+func StartSpanFromRequest(spanName string, tracer trace.Tracer, r *http.Request) trace.Span {
+	if tracer == nil {
+		tracer = otel.Tracer(instrumentationName)
+	}
+
+	_, span := tracer.Start(Extract(r), spanName, trace.WithSpanKind(trace.SpanKindServer))
+	return span
 }
 
-func StartSpanFromContext(ctx context.Context, spanName string) opentracing.Span {
-	span, _ := opentracing.StartSpanFromContext(ctx, spanName)
+func StartSpanFromContext(ctx context.Context, spanName string) trace.Span {
+	_, span := otel.Tracer(instrumentationName).Start(ctx, spanName)
 	return span
 }
 
-func ContextWithSpan(ctx context.Context, span opentracing.Span) context.Context {
-	return opentracing.ContextWithSpan(ctx, span)
+func ContextWithSpan(ctx context.Context, span trace.Span) context.Context {
+	return trace.ContextWithSpan(ctx, span)
 }
 
-func LogString(key string, value string) log.Field {
-	return log.String(key, value)
+func LogString(key string, value string) attribute.KeyValue {
+	return attribute.String(key, value)
 }
 
-func LogError(span opentracing.Span, err error, fields ...log.Field) {
-	ext.LogError(span, err, fields...)
+func LogError(span trace.Span, err error, fields ...attribute.KeyValue) {
+	span.RecordError(err, trace.WithAttributes(fields...))
+	span.SetStatus(codes.Error, err.Error())
 }
@@ -0,0 +1,22 @@
+package util
+
+import "time"
+
+// DateLayout is the layout of calendar dates exchanged through the API.
+const DateLayout = "2006-01-02"
+
+func StartOfDay(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+}
+
+// Nights returns the dates of the nights spent between start and end, that is every calendar day
+// from the day of start up to, but not including, the day of end.
+func Nights(start time.Time, end time.Time) []time.Time {
+	nights := []time.Time{}
+	lastDay := StartOfDay(end.In(start.Location()))
+	for night := StartOfDay(start); night.Before(lastDay); night = night.AddDate(0, 0, 1) {
+		nights = append(nights, night)
+	}
+
+	return nights
+}
@@ -0,0 +1,42 @@
+package util
+
+import (
+	"errors"
+	"time"
+	// embeds the time zone database so IANA time zones load in images without one
+	_ "time/tzdata"
+)
+
+// DefaultTimeZone is the time zone of accommodations that do not have one, set from the configuration.
+var DefaultTimeZone = "UTC"
+
+// LoadLocation returns the IANA time zone, falling back to DefaultTimeZone and then to UTC when the time zone
+// is empty or unknown.
+func LoadLocation(timeZone string) *time.Location {
+	if timeZone == "" {
+		timeZone = DefaultTimeZone
+	}
+
+	location, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return time.UTC
+	}
+
+	return location
+}
+
+// CivilDate returns the calendar date of the instant in the location, as midnight UTC of that date.
+func CivilDate(instant time.Time, location *time.Location) time.Time {
+	local := instant.In(location)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ParseClock parses a time of day such as 15:00.
+func ParseClock(clock string) (int, int, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, 0, errors.New("Time of day " + clock + " is not valid")
+	}
+
+	return parsed.Hour(), parsed.Minute(), nil
+}